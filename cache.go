@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+)
+
+// DefaultCacheDir is where -cache-dir is suggested to point
+const DefaultCacheDir = ".tf2doc-cache"
+
+// cacheSchemaVersion is bumped whenever CacheEntry changes, so older entries are ignored
+const cacheSchemaVersion = 2
+
+// CachedFile is a file a cached run wrote, other than its standard output
+type CachedFile struct {
+	Path    string `json:"path"`
+	Content []byte `json:"content"`
+}
+
+// CachedInput is a file a run read that is only known once the module is rendered, like a file the template
+// includes, with the hash of its content, or no hash when it didn't exist
+type CachedInput struct {
+	Path string `json:"path"`
+	Sum  string `json:"sum,omitempty"`
+}
+
+// CacheEntry is what a run of one module produced, kept under the key of everything it was rendered from
+type CacheEntry struct {
+	Schema int           `json:"schema"`
+	Key    string        `json:"key"`
+	Output []byte        `json:"output"`
+	Files  []CachedFile  `json:"files"`
+	Inputs []CachedInput `json:"inputs"`
+}
+
+// RenderCache keeps a module's last output in Dir, to be reused while Key is unchanged. There is one entry
+// per module, so a changed module replaces its entry rather than adding one.
+//
+// Entries are written directly rather than through the FileWriter, as they aren't part of the docs.
+type RenderCache struct {
+	Dir  string
+	Key  string
+	path string
+}
+
+// cacheable reports whether a run's results can be cached. Runs that explain their work, or are
+// interactive, always do it, as do runs comparing with a git ref, which may have moved, Adopt,
+// InjectReadme, DiffReadme and -inject-into, whose output depends on the README they read, and
+// -collection, whose modules aren't among the cached inputs.
+func cacheable(cliOpts *CliOpts) bool {
+	return !cliOpts.Tui && cliOpts.Explain == "" && cliOpts.ManifestOut == "" && cliOpts.AnnotateSince == "" && cliOpts.InjectInto == "" && cliOpts.Action != "Adopt" && cliOpts.Action != "InjectReadme" && cliOpts.Action != "DiffReadme" && cliOpts.Action != "Feed" && !cliOpts.Collection
+}
+
+// cacheInputs are the files a run of cliOpts reads, whether or not they exist, that are known before it
+// renders the module. Override files are among the module's .tf and .tf.json files.
+func cacheInputs(cliOpts *CliOpts) ([]string, error) {
+	files := []string{cliOpts.TemplatePath}
+	for _, l := range cliOpts.LocaleOutputs {
+		files = append(files, l.Template)
+	}
+	if cliOpts.InputJson != "" {
+		files = append(files, cliOpts.InputJson)
+	}
+	metadata := cliOpts.MetadataFile
+	if !filepath.IsAbs(metadata) {
+		metadata = filepath.Join(cliOpts.TfPath, metadata)
+	}
+	files = append(files, metadata, filepath.Join(cliOpts.TfPath, tfdoc.DeprecatedFile))
+	for _, glob := range []string{"*.tf", "*.tf.json", cliOpts.TfvarsGlob} {
+		matches, err := filepath.Glob(filepath.Join(cliOpts.TfPath, glob))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if root, ok := tfdoc.FindRepoRoot(cliOpts.TfPath); ok {
+		for _, p := range tfdoc.CodeownersPaths {
+			files = append(files, filepath.Join(root, p))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// CacheKey hashes the tool version, the options and the content of every file the run reads, so any
+// change to them gives a different key
+func CacheKey(cliOpts *CliOpts) (string, error) {
+	opts := *cliOpts
+	opts.CacheDir, opts.NoCache, opts.MetricsOut = "", false, ""
+	optsJson, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "tf2doc %s\n%s\n", Version, optsJson)
+	for _, r := range cliOpts.URLRewrites {
+		fmt.Fprintf(h, "rewrite %s %s\n", r.Pattern, r.Replacement)
+	}
+
+	files, err := cacheInputs(cliOpts)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		sum, err := fileSum(file)
+		switch {
+		case err != nil:
+			return "", err
+		case sum == "":
+			fmt.Fprintf(h, "absent %s\n", file)
+		default:
+			fmt.Fprintf(h, "file %s %s\n", file, sum)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// fileSum is the hash of the file's content, or empty when it doesn't exist
+func fileSum(file string) (string, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// renderedInputs are the files a run read that cacheInputs couldn't know of: those the templates included
+// and the .editorconfig files that may apply to the files it wrote
+func renderedInputs(included *tfdoc.IncludedFiles, written []string) ([]string, error) {
+	inputs := included.Paths()
+	for _, file := range written {
+		paths, err := tfdoc.EditorconfigPaths(file)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, paths...)
+	}
+	return inputs, nil
+}
+
+// NewRenderCache returns the cache for the module at modulePath, whose runs have key
+func NewRenderCache(dir, modulePath, key string) (*RenderCache, error) {
+	abs, err := filepath.Abs(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%x.json", sha256.Sum256([]byte(abs)))
+	return &RenderCache{Dir: dir, Key: key, path: filepath.Join(dir, name)}, nil
+}
+
+// Load returns the module's entry if it was stored under the same key. Entries that can't be read are
+// ignored with a warning, and the module is rendered again.
+func (c *RenderCache) Load() (CacheEntry, bool) {
+	var entry CacheEntry
+	b, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return entry, false
+	}
+	if err == nil {
+		err = json.Unmarshal(b, &entry)
+	}
+	if err != nil {
+		stderr.Printf("ignoring cache entry %s: %v", c.path, err)
+		return entry, false
+	}
+	if entry.Schema != cacheSchemaVersion || entry.Key != c.Key {
+		return entry, false
+	}
+	for _, input := range entry.Inputs {
+		if sum, err := fileSum(input.Path); err != nil || sum != input.Sum {
+			return entry, false
+		}
+	}
+	return entry, true
+}
+
+// Store keeps output and the current content of the files written under the cache's key, with the
+// current content of inputs, the files the run read that aren't part of the key
+func (c *RenderCache) Store(output []byte, written, inputs []string) error {
+	entry := CacheEntry{Schema: cacheSchemaVersion, Key: c.Key, Output: output, Files: []CachedFile{}, Inputs: []CachedInput{}}
+	for _, file := range written {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		entry.Files = append(entry.Files, CachedFile{Path: file, Content: b})
+	}
+	for _, file := range inputs {
+		sum, err := fileSum(file)
+		if err != nil {
+			return err
+		}
+		entry.Inputs = append(entry.Inputs, CachedInput{Path: file, Sum: sum})
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	return tfdoc.ReplaceFile(c.path, b)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// cachedRun does what a run of cliOpts does with the cache: it reports a hit, or renders the template
+// into the README and stores the result
+func cachedRun(t *testing.T, cliOpts *CliOpts) bool {
+	t.Helper()
+	key, err := CacheKey(cliOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewRenderCache(cliOpts.CacheDir, cliOpts.TfPath, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Load(); ok {
+		return true
+	}
+
+	module, diags := tfconfig.LoadModule(cliOpts.TfPath)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	toc := []tfdoc.TocEntry{}
+	included := &tfdoc.IncludedFiles{}
+	funcs := tfdoc.TemplateFuncs(cliOpts.TemplatePath, tfdoc.DefaultMaxIncludeSize, "en", module, &toc, included)
+	tmpl, err := template.New(filepath.Base(cliOpts.TemplatePath)).Funcs(funcs).ParseFiles(cliOpts.TemplatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	readme := filepath.Join(cliOpts.TfPath, "README.md")
+	if err := ioutil.WriteFile(readme, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	written := []string{readme}
+	inputs, err := renderedInputs(included, written)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store(nil, written, inputs); err != nil {
+		t.Fatal(err)
+	}
+	return false
+}
+
+func TestCacheMissesWhenAnInputChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf":         `variable "name" {}`,
+		"override.tf":     `variable "name" { default = "app" }`,
+		"extra.tf.json":   `{"variable": {"size": {}}}`,
+		"prod.tfvars":     `name = "prod"`,
+		"metadata.yaml":   "owner: platform\n",
+		"notes.md":        "Some notes\n",
+		"usage.md":        "# Usage\n\nRun it\n",
+		".editorconfig":   "root = true\n",
+		"README.template": `{{ rawfile "notes.md" }}{{ includeSection "usage.md" "Usage" }}`,
+		"unrelated.txt":   "not read\n",
+	})
+	cliOpts := &CliOpts{
+		Action:       "RenderTemplate",
+		TfPath:       dir,
+		TemplatePath: filepath.Join(dir, "README.template"),
+		MetadataFile: tfdoc.DefaultMetadataFile,
+		TfvarsGlob:   "*.tfvars",
+		CacheDir:     filepath.Join(dir, DefaultCacheDir),
+	}
+	if cachedRun(t, cliOpts) {
+		t.Fatal("the first run was a cache hit")
+	}
+	if !cachedRun(t, cliOpts) {
+		t.Fatal("a run with nothing changed was a cache miss")
+	}
+	writeFiles(t, dir, map[string]string{"unrelated.txt": "changed\n"})
+	if !cachedRun(t, cliOpts) {
+		t.Fatal("changing a file the run doesn't read was a cache miss")
+	}
+
+	edits := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"a .tf file", map[string]string{"main.tf": `variable "name" { type = string }`}},
+		{"an override file", map[string]string{"override.tf": `variable "name" { default = "web" }`}},
+		{"a .tf.json file", map[string]string{"extra.tf.json": `{"variable": {"size": {"default": 2}}}`}},
+		{"a new .tf.json override file", map[string]string{"main_override.tf.json": `{"variable": {"name": {"default": "db"}}}`}},
+		{"a tfvars file", map[string]string{"prod.tfvars": `name = "production"`}},
+		{"the metadata file", map[string]string{"metadata.yaml": "owner: network\n"}},
+		{"a new DEPRECATED.md", map[string]string{tfdoc.DeprecatedFile: "Use the new module\n"}},
+		{"DEPRECATED.md", map[string]string{tfdoc.DeprecatedFile: "Use the other module\n"}},
+		{"the template", map[string]string{"README.template": `{{ rawfile "notes.md" }}{{ includeSection "usage.md" "Usage" 2 }}`}},
+		{"a file read by rawfile", map[string]string{"notes.md": "Other notes\n"}},
+		{"a file read by includeSection", map[string]string{"usage.md": "# Usage\n\nRun it twice\n"}},
+		{".editorconfig", map[string]string{".editorconfig": "root = true\n\n[*.md]\nend_of_line = crlf\n"}},
+	}
+	for _, edit := range edits {
+		writeFiles(t, dir, edit.files)
+		if cachedRun(t, cliOpts) {
+			t.Errorf("changing %s was a cache hit", edit.name)
+		}
+		if !cachedRun(t, cliOpts) {
+			t.Errorf("the run after changing %s was a cache miss", edit.name)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, tfdoc.DeprecatedFile)); err != nil {
+		t.Fatal(err)
+	}
+	if cachedRun(t, cliOpts) {
+		t.Error("removing DEPRECATED.md was a cache hit")
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	tests := []struct {
+		name    string
+		cliOpts CliOpts
+		want    bool
+	}{
+		{"a table", CliOpts{Action: "VarsTable"}, true},
+		{"a template", CliOpts{Action: "RenderTemplate"}, true},
+		{"injecting into a README", CliOpts{Action: "RenderTemplate", InjectInto: "README.md"}, false},
+		{"InjectReadme", CliOpts{Action: "InjectReadme"}, false},
+		{"DiffReadme", CliOpts{Action: "DiffReadme"}, false},
+		{"Adopt", CliOpts{Action: "Adopt"}, false},
+		{"explaining", CliOpts{Action: "VarsTable", Explain: "-"}, false},
+		{"comparing with a git ref", CliOpts{Action: "VarsTable", AnnotateSince: "main"}, false},
+		{"a collection", CliOpts{Collection: true}, false},
+	}
+	for _, test := range tests {
+		if got := cacheable(&test.cliOpts); got != test.want {
+			t.Errorf("cacheable for %s = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCacheMissesWhenARenderFlagChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": `variable "name" {
+  description = "The name given to every resource the module creates"
+}
+`,
+	})
+	common := []string{"-no-detect-repo-url", "-no-config", "-path", ".", "-cache-dir", DefaultCacheDir, "-repoUrl", "https://github.com/org/repo"}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"-text-width", []string{"-action", "VarsTable", "-format", "text", "-text-width", "20"}, "The name given to   main.tf:1"},
+		{"-text-width", []string{"-action", "VarsTable", "-format", "text", "-text-width", "0"}, "The name given to every resource"},
+		{"-link-titles", []string{"-action", "VarsTable", "-link-titles"}, `"Definition of variable name"`},
+		{"-repo-url-rewrite", []string{"-action", "VarsTable", "-repo-url-rewrite", "github.com=git.example.com"}, "https://git.example.com/org/repo"},
+	}
+	for _, test := range tests {
+		stdout, stderr, code := runTf2doc(t, dir, append(common, test.args...)...)
+		if code != 0 {
+			t.Fatalf("%s exited %d with stderr:\n%s", test.name, code, stderr)
+		}
+		if !strings.Contains(stdout, test.want) {
+			t.Errorf("with %s got:\n%s\nwant it to contain %q, so a cached run's output was reused", test.args, stdout, test.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+type clipboardCommand struct {
+	Name string
+	Args []string
+}
+
+// clipboardCommands lists the clipboard programs to try for the current platform, in order of preference
+func clipboardCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{{Name: "pbcopy"}}
+	case "windows":
+		return []clipboardCommand{{Name: "clip.exe"}}
+	default:
+		return []clipboardCommand{
+			{Name: "wl-copy"},
+			{Name: "xclip", Args: []string{"-selection", "clipboard"}},
+			{Name: "xsel", Args: []string{"--clipboard", "--input"}},
+			{Name: "clip.exe"}, // WSL
+		}
+	}
+}
+
+// CopyToClipboard copies text to the system clipboard using the first clipboard program that works
+func CopyToClipboard(text []byte) error {
+	tried := []string{}
+	for _, c := range clipboardCommands() {
+		bin, err := exec.LookPath(c.Name)
+		if err != nil {
+			tried = append(tried, fmt.Sprintf("%s (not installed)", c.Name))
+			continue
+		}
+		cmd := exec.Command(bin, c.Args...)
+		cmd.Stdin = bytes.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			tried = append(tried, fmt.Sprintf("%s (%s)", c.Name, strings.TrimSpace(err.Error()+" "+string(out))))
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard available, tried: %s", strings.Join(tried, ", "))
+}
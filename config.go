@@ -0,0 +1,427 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+)
+
+// DefaultConfigFile is where MigrateConfig suggests writing its output
+const DefaultConfigFile = ".tf2doc.yaml"
+
+// moduleConfigFiles are the config files read from -path when -config isn't given, in order of preference
+var moduleConfigFiles = []string{".tf2doc.yml", DefaultConfigFile}
+
+// FindModuleConfig is the first of moduleConfigFiles in the module's directory, or empty when it has none
+func FindModuleConfig(dir string) string {
+	for _, name := range moduleConfigFiles {
+		file := filepath.Join(dir, name)
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file
+		}
+	}
+	return ""
+}
+
+// terraformDocsConfigFile is the config file of terraform-docs, read by MigrateConfig and in place of a
+// tf2doc config by -config
+const terraformDocsConfigFile = ".terraform-docs.yml"
+
+// LoadConfig reads a config file, whose keys are flag names and whose values are what the flag would be
+// given, or a list for flags that may be repeated. The file is checked with ValidateConfig first, and with
+// lax, keys that aren't settings are left out with a warning instead of failing. A terraform-docs config
+// is translated as well as it can be, with what couldn't be reported on stderr.
+func LoadConfig(file string, lax bool) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if isTerraformDocsConfig(file) {
+		settings, untranslated, err := TranslateTerraformDocsConfig(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for _, u := range untranslated {
+			stderr.Printf("%s: ignoring %s", file, u)
+		}
+		return settings, nil
+	}
+
+	problems, err := ValidateConfig(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	failed := []string{}
+	unknown := map[string]bool{}
+	for _, p := range problems {
+		if p.Unknown && lax {
+			stderr.Printf("%s:%s (ignored by -config-lax)", file, p)
+			unknown[p.Key] = true
+			continue
+		}
+		failed = append(failed, fmt.Sprintf("%s:%s", file, p))
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(failed, "\n"))
+	}
+
+	settings := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &settings); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	for key := range unknown {
+		delete(settings, key)
+	}
+	return settings, nil
+}
+
+// ConfigProblem is something wrong with a setting of a config file, at its position in the file
+type ConfigProblem struct {
+	Line, Column int
+	Key          string
+	Message      string
+	Unknown      bool // The key isn't a setting, which -config-lax only warns about
+}
+
+func (p ConfigProblem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// configSettings are the flags a config file may set, all but the ones that say which file to read
+func configSettings() map[string]*flag.Flag {
+	settings := make(map[string]*flag.Flag)
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name != "config" && f.Name != "config-lax" && f.Name != "no-config" {
+			settings[f.Name] = f
+		}
+	})
+	return settings
+}
+
+// suggestSetting is the setting a mistyped key most likely meant, or empty when none is close
+func suggestSetting(key string, settings map[string]*flag.Flag) string {
+	best, bestDistance := "", 3
+	for name := range settings {
+		if d := tfdoc.EditDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if bestDistance > len(key)/3 && bestDistance > 1 {
+		return ""
+	}
+	return best
+}
+
+// checkConfigValue reports what's wrong with a scalar value given to f, or empty when it would be accepted
+func checkConfigValue(f *flag.Flag, value *yaml.Node) string {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Sprintf("%s takes a single value, only settings that may be repeated take a list", f.Name)
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		if _, err := strconv.ParseBool(value.Value); err != nil {
+			return fmt.Sprintf("%s takes true or false, not %q", f.Name, value.Value)
+		}
+		return ""
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return ""
+	}
+	switch getter.Get().(type) {
+	case int:
+		if _, err := strconv.Atoi(value.Value); err != nil {
+			return fmt.Sprintf("%s takes a whole number, not %q", f.Name, value.Value)
+		}
+	case time.Duration:
+		if _, err := time.ParseDuration(value.Value); err != nil {
+			return fmt.Sprintf("%s takes a duration such as 30s, not %q", f.Name, value.Value)
+		}
+	}
+	return ""
+}
+
+// ValidateConfig checks a tf2doc config file against the flags: its keys must be settings, a list may only
+// be given to a setting that may be repeated, and values must be what their flag takes. Misspelled keys
+// get the setting they most likely meant suggested. Files that aren't YAML are an error.
+func ValidateConfig(b []byte) ([]ConfigProblem, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	problems := []ConfigProblem{}
+	if len(doc.Content) == 0 {
+		return problems, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return append(problems, ConfigProblem{Line: root.Line, Column: root.Column, Message: "the config must be a mapping of settings to values"}), nil
+	}
+
+	settings := configSettings()
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		f, ok := settings[key.Value]
+		if !ok {
+			message := fmt.Sprintf("%s is not a setting", key.Value)
+			if key.Value == "config" || key.Value == "config-lax" {
+				message += ", it can only be given on the command line"
+			} else if suggestion := suggestSetting(key.Value, settings); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %s?", suggestion)
+			}
+			problems = append(problems, ConfigProblem{Line: key.Line, Column: key.Column, Key: key.Value, Message: message, Unknown: true})
+			continue
+		}
+
+		if _, repeatable := f.Value.(*stringList); repeatable && value.Kind == yaml.SequenceNode {
+			for _, v := range value.Content {
+				if v.Kind != yaml.ScalarNode {
+					problems = append(problems, ConfigProblem{Line: v.Line, Column: v.Column, Key: key.Value, Message: fmt.Sprintf("%s takes a list of single values", key.Value)})
+				}
+			}
+			continue
+		}
+		if message := checkConfigValue(f, value); message != "" {
+			problems = append(problems, ConfigProblem{Line: value.Line, Column: value.Column, Key: key.Value, Message: message})
+		}
+	}
+	return problems, nil
+}
+
+func isTerraformDocsConfig(file string) bool {
+	base := tfpath.Base(file)
+	return base == terraformDocsConfigFile || base == ".terraform-docs.yaml"
+}
+
+// envFlags are the environment variables that stand in for flags, for containers and CI systems where they
+// are easier to set than arguments
+var envFlags = map[string]string{
+	"TF2DOC_PATH":          "path",
+	"TF2DOC_ACTION":        "action",
+	"TF2DOC_TEMPLATE_PATH": "templatePath",
+	"TF2DOC_REPO_URL":      "repoUrl",
+	"TF2DOC_MODULE_PATH":   "modulePath",
+}
+
+// ApplyEnv sets each flag of envFlags that wasn't given on the command line from its environment variable,
+// if set. It runs before the config file is read, so the variables also win over it.
+func ApplyEnv() error {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	vars := []string{}
+	for name := range envFlags {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	for _, name := range vars {
+		value, ok := os.LookupEnv(name)
+		if !ok || given[envFlags[name]] {
+			continue
+		}
+		if err := flag.Set(envFlags[name], value); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyConfig sets each flag in settings that wasn't given on the command line
+func ApplyConfig(settings map[string]interface{}) error {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	keys := []string{}
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if flag.Lookup(key) == nil || key == "config" || key == "no-config" {
+			return fmt.Errorf("%s is not a setting", key)
+		}
+		if given[key] {
+			continue
+		}
+		values := []interface{}{settings[key]}
+		if list, ok := settings[key].([]interface{}); ok {
+			values = list
+		}
+		for _, v := range values {
+			if err := flag.Set(key, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// terraformDocsConfig is the part of a terraform-docs config that has tf2doc equivalents
+type terraformDocsConfig struct {
+	Formatter string `yaml:"formatter"`
+	Sections  struct {
+		Hide []string `yaml:"hide"`
+		Show []string `yaml:"show"`
+	} `yaml:"sections"`
+	Content string `yaml:"content"`
+	Output  struct {
+		File string `yaml:"file"`
+		Mode string `yaml:"mode"`
+	} `yaml:"output"`
+	Sort struct {
+		Enabled *bool  `yaml:"enabled"`
+		By      string `yaml:"by"`
+	} `yaml:"sort"`
+	Settings map[string]interface{} `yaml:"settings"`
+}
+
+// terraformDocsSections are terraform-docs' sections, with the action that fills a region for each.
+// Sections without an action can't be injected.
+var terraformDocsSections = []struct {
+	Name, Action string
+}{
+	{"header", ""},
+	{"requirements", "RequirementsTable"},
+	{"providers", "RequirementsTable"},
+	{"modules", ""},
+	{"resources", "ManagedResourcesTable"},
+	{"data-sources", "DataSourcesTable"},
+	{"inputs", "VarsTable"},
+	{"outputs", "OutputsTable"},
+	{"footer", ""},
+}
+
+// TranslateTerraformDocsConfig maps a terraform-docs config onto tf2doc settings, keyed by flag name, and
+// lists what it couldn't translate
+func TranslateTerraformDocsConfig(b []byte) (map[string]interface{}, []string, error) {
+	var cfg terraformDocsConfig
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, nil, err
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	settings := map[string]interface{}{}
+	untranslated := []string{}
+	handled := map[string]bool{"formatter": true, "sections": true, "content": true, "output": true, "sort": true, "settings": true, "version": true}
+	for key := range raw {
+		if !handled[key] {
+			untranslated = append(untranslated, fmt.Sprintf("%s, which tf2doc has no equivalent of", key))
+		}
+	}
+
+	if cfg.Formatter != "" && !strings.HasPrefix(cfg.Formatter, "markdown") && !strings.HasPrefix(cfg.Formatter, "md") {
+		untranslated = append(untranslated, fmt.Sprintf("formatter %q, as tf2doc only writes markdown tables", cfg.Formatter))
+	}
+
+	switch {
+	case cfg.Sort.Enabled != nil && !*cfg.Sort.Enabled:
+		settings["sort"] = "declaration"
+	case cfg.Sort.By == "" || cfg.Sort.By == "name":
+		settings["sort"] = "name"
+	default:
+		settings["sort"] = "name"
+		untranslated = append(untranslated, fmt.Sprintf("sort by %s, so tables are sorted by name", cfg.Sort.By))
+	}
+
+	settingKeys := []string{}
+	for key := range cfg.Settings {
+		settingKeys = append(settingKeys, key)
+	}
+	sort.Strings(settingKeys)
+	for _, key := range settingKeys {
+		if key == "type" {
+			if show, ok := cfg.Settings[key].(bool); ok && !show {
+				settings["columns"] = []interface{}{"vars=name,description,position"}
+			}
+			continue
+		}
+		untranslated = append(untranslated, fmt.Sprintf("settings.%s, which tf2doc has no equivalent of", key))
+	}
+
+	shown := []string{}
+	for _, section := range terraformDocsSections {
+		hidden := tfdoc.StringInSlice(section.Name, cfg.Sections.Hide)
+		if len(cfg.Sections.Show) > 0 {
+			hidden = !tfdoc.StringInSlice(section.Name, cfg.Sections.Show)
+		}
+		if !hidden {
+			shown = append(shown, section.Name)
+		}
+	}
+
+	switch {
+	case cfg.Output.File != "" && (cfg.Output.Mode == "inject" || cfg.Output.Mode == ""):
+		regions := []interface{}{}
+		filled := map[string]bool{}
+		for _, section := range terraformDocsSections {
+			if !tfdoc.StringInSlice(section.Name, shown) {
+				continue
+			}
+			if section.Action == "" {
+				untranslated = append(untranslated, fmt.Sprintf("the %s section, which tf2doc can't inject", section.Name))
+				continue
+			}
+			if filled[section.Action] {
+				continue
+			}
+			filled[section.Action] = true
+			regions = append(regions, fmt.Sprintf("%s=%s", section.Name, section.Action))
+		}
+		settings["inject-into"] = cfg.Output.File
+		settings["inject-region"] = regions
+		untranslated = append(untranslated, fmt.Sprintf("output.template, so %s needs a <!-- tf2doc:NAME:begin --> and <!-- tf2doc:NAME:end --> pair for each -inject-region in place of its BEGIN_TF_DOCS markers", cfg.Output.File))
+	case cfg.Output.Mode == "replace":
+		untranslated = append(untranslated, fmt.Sprintf("output mode replace, so send the output to %s yourself", cfg.Output.File))
+	}
+	if cfg.Content != "" {
+		untranslated = append(untranslated, "content, which needs rewriting as a template for -templatePath")
+	}
+
+	sort.Strings(untranslated)
+	return settings, untranslated, nil
+}
+
+// MigrateConfig translates the terraform-docs config in file into a tf2doc config, with the settings it
+// couldn't translate listed in comments at the top
+func MigrateConfig(file string) (string, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	settings, untranslated, err := TranslateTerraformDocsConfig(b)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", file, err)
+	}
+	out, err := yaml.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+
+	var config strings.Builder
+	fmt.Fprintf(&config, "# Migrated from %s, use with -config %s\n", tfpath.Base(file), DefaultConfigFile)
+	if len(untranslated) > 0 {
+		config.WriteString("#\n# Not translated:\n")
+		for _, u := range untranslated {
+			fmt.Fprintf(&config, "#   - %s\n", u)
+		}
+	}
+	config.Write(out)
+	return config.String(), nil
+}
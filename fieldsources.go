@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// templateFieldSources describes what fills each field of DocData
+func templateFieldSources(cliOpts *CliOpts, managedResources int) map[string]string {
+	resources := "the managed resources table, see \"resources table\""
+	if cliOpts.LargeAbove > 0 && managedResources > cliOpts.LargeAbove {
+		resources = fmt.Sprintf("counts per resource type, as the module has more than -large-threshold %d managed resources", cliOpts.LargeAbove)
+	}
+	toc := "the headings of the template, to depth 3"
+	if cliOpts.HeadingIds == "explicit" {
+		toc = "the headings of the rendered document, to depth 3, by -heading-ids explicit"
+	}
+	return map[string]string{
+		"TerraformVarsTable":              "the variables table, see \"vars table\"",
+		"TerraformOutputsTable":           "the outputs table, see \"outputs table\"",
+		"TerraformManagedResourcesTable":  resources,
+		"TerraformDataSourcesTable":       "the data sources table, see \"data table\"",
+		"TerraformModulesTable":           "the module calls table, see \"modules table\"",
+		"TerraformRequirementsTable":      "required_version, required_providers and module call versions",
+		"TerraformRequiredProvidersTable": "required_providers",
+		"TerraformProvidersTable":         "provider blocks, configuration_aliases and the providers resources use",
+		"TerraformRemovedTable":           "the module's removed blocks",
+		"TerraformTodos":                  fmt.Sprintf("comments marked %s", strings.Join(cliOpts.TodoMarkers, "/")),
+		"MarkdownTOC":                     toc,
+		"TOCEntries":                      toc,
+		"RepoBaseUrl":                     "-repoUrl",
+		"Owners":                          "the CODEOWNERS file",
+		"Deprecated":                      "the module's deprecation notice",
+		"DeprecationMessage":              "the module's deprecation notice",
+		"Replacement":                     "the module's deprecation notice",
+		"Meta":                            fmt.Sprintf("-metadata-file %s", cliOpts.MetadataFile),
+		"TerraformMetadataTable":          fmt.Sprintf("-metadata-file %s", cliOpts.MetadataFile),
+		"Experiments":                     "the experiments terraform blocks enable",
+		"UndocumentedBlocks":              "top-level blocks no table covers",
+		"Extra":                           "the ExtraData functions of a program rendering with the tfdoc package",
+		"TerraformTagCoverage":            "the tag arguments of taggable managed resources, by -tag-attributes and -untaggable-types",
+		"LintFindings":                    "the Lint action's naming rules, by -with-lint",
+		"LintSummary":                     "the Lint action's naming rules, by -with-lint",
+		"DocScore":                        "the descriptions of variables and outputs, by -with-lint",
+		"TerraformImportScaffold":         fmt.Sprintf("the managed resources, with count and for_each, by -import-style %s", cliOpts.ImportStyle),
+	}
+}
@@ -1,13 +1,20 @@
-module TF_2_DOC
+module github.com/JoeButler99/TF_2_DOC
 
-go 1.12
+go 1.18
+
+require (
+	github.com/hashicorp/hcl/v2 v2.5.1
+	github.com/hashicorp/terraform-config-inspect v0.0.0-20200526195750-d43f12b82861
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/agext/levenshtein v1.2.3 // indirect
+	github.com/apparentlymart/go-textseg/v12 v12.0.0 // indirect
 	github.com/google/go-cmp v0.4.1 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
-	github.com/hashicorp/hcl/v2 v2.5.1 // indirect
 	github.com/hashicorp/hcl2 v0.0.0-20191002203319-fb75b3253c80 // indirect
-	github.com/hashicorp/terraform-config-inspect v0.0.0-20200526195750-d43f12b82861
+	github.com/mitchellh/go-wordwrap v1.0.0 // indirect
 	github.com/zclconf/go-cty v1.4.2 // indirect
+	golang.org/x/text v0.3.2 // indirect
 )
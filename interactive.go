@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+	"gopkg.in/yaml.v3"
+)
+
+// starterTemplateFile is where -interactive writes starterTemplate, beside the module
+const starterTemplateFile = ".tf2doc.template.md"
+
+// starterTemplate is the template -interactive starts a new README from: each table under its own heading
+const starterTemplate = `{{ .MarkdownTOC }}
+
+# Requirements
+
+{{ .TerraformRequirementsTable }}
+
+# Inputs
+
+{{ .TerraformVarsTable }}
+
+# Outputs
+
+{{ .TerraformOutputsTable }}
+
+# Resources
+
+{{ .TerraformManagedResourcesTable }}
+
+# Data Sources
+
+{{ .TerraformDataSourcesTable }}
+
+# Modules
+
+{{ .TerraformModulesTable }}
+`
+
+// prompter asks questions on a terminal, a line for each answer
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints question with its default in brackets and returns the answer, or def when Enter is pressed.
+// Answers valid rejects are reported and asked for again.
+func (p *prompter) ask(question, def string, valid func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		line, err := p.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("-interactive: the input ended before the questions did")
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if valid == nil {
+			return answer, nil
+		}
+		if err := valid(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// confirm asks a yes or no question
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	answer, err := p.ask(fmt.Sprintf("%s (%s)", question, choices), "", func(a string) error {
+		if a != "" && !tfdoc.StringInSlice(strings.ToLower(a), []string{"y", "yes", "n", "no"}) {
+			return errors.New("answer y or n")
+		}
+		return nil
+	})
+	if err != nil || answer == "" {
+		return def, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+// choose asks for one of options
+func (p *prompter) choose(question string, options []string, def string) (string, error) {
+	return p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), def, func(a string) error {
+		if !tfdoc.StringInSlice(a, options) {
+			return fmt.Errorf("answer one of %s", strings.Join(options, ", "))
+		}
+		return nil
+	})
+}
+
+// validModuleDir says what's wrong with dir as a module to document, if anything
+func validModuleDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("%s doesn't exist", dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	own, err := tfdoc.HasTfFiles(dir)
+	if err != nil {
+		return err
+	}
+	if !own {
+		return fmt.Errorf("%s has no .tf files", dir)
+	}
+	return nil
+}
+
+// interactiveSetting is a flag -interactive chose, in the order it was asked about. Bools have the value
+// "true".
+type interactiveSetting struct {
+	Name, Value string
+}
+
+var rShellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellWord quotes s for a POSIX shell when it needs it
+func shellWord(s string) string {
+	if rShellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// interactiveCommand is the command line that does what settings say
+func interactiveCommand(tfPath string, settings []interactiveSetting) string {
+	words := []string{"tf2doc", "-path", shellWord(tfPath)}
+	for _, s := range settings {
+		words = append(words, "-"+s.Name)
+		if s.Value != "true" {
+			words = append(words, shellWord(s.Value))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// interactiveConfig is settings as a config file, repeated flags as lists
+func interactiveConfig(tfPath string, settings []interactiveSetting) (string, error) {
+	config := make(map[string]interface{})
+	for _, s := range settings {
+		var value interface{} = s.Value
+		if s.Value == "true" {
+			value = true
+		}
+		if existing, ok := config[s.Name]; ok {
+			list, isList := existing.([]interface{})
+			if !isList {
+				list = []interface{}{existing}
+			}
+			value = append(list, value)
+		}
+		config[s.Name] = value
+	}
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("# Written by tf2doc -interactive. Paths are relative to where tf2doc -path %s is run\n%s", tfPath, out), nil
+}
+
+// injectSettings are the -inject-into and -inject-region flags that keep readme's regions up to date: the
+// regions it already has that tf2doc knows how to fill, or else the sections -action Adopt would mark
+func injectSettings(readme string, out io.Writer) ([]interactiveSetting, error) {
+	settings := []interactiveSetting{{"inject-into", readme}}
+	b, err := ioutil.ReadFile(readme)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := tfdoc.FindMarkedRegions(strings.Split(string(b), "\n"), tfdoc.DefaultInjectMarker)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", readme, err)
+	}
+	for _, section := range tfdoc.AdoptableSections {
+		for _, r := range regions {
+			if r.Name == section.Region {
+				settings = append(settings, interactiveSetting{"inject-region", section.Region + "=" + section.Action})
+			}
+		}
+	}
+	if len(regions) == 0 {
+		for _, section := range tfdoc.AdoptableSections {
+			settings = append(settings, interactiveSetting{"inject-region", section.Region + "=" + section.Action})
+		}
+		fmt.Fprintf(out, "  %s has no tf2doc regions yet. Mark them first with: tf2doc -path %s -action Adopt -inject-into %s\n", readme, shellWord(filepath.Dir(readme)), shellWord(readme))
+	} else if len(settings) == 1 {
+		return nil, fmt.Errorf("none of the regions of %s are ones tf2doc fills", readme)
+	}
+	return settings, nil
+}
+
+// RunInteractive asks for the choices a first run needs: the module, whether to write a new README or
+// update the regions of the existing one, where links go and which template to use. Every question has a
+// default, so pressing Enter throughout gives a working setup. It prints the command line that does the
+// same and offers to save the choices in the module's config file. It needs a terminal on stdin.
+func RunInteractive(tfPath string) error {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return errors.New("-interactive needs a terminal on stdin, give the flags it would ask for instead")
+	}
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	fmt.Fprintln(p.out, "Answer each question, or press Enter for the default in brackets.")
+
+	if tfPath == "" {
+		tfPath = "."
+	}
+	if tfPath, err = p.ask("Module path", tfPath, validModuleDir); err != nil {
+		return err
+	}
+	writer := &tfdoc.FileWriter{Allowed: []string{tfPath}}
+	settings := []interactiveSetting{}
+
+	readme := filepath.Join(tfPath, "README.md")
+	mode := "new"
+	if _, err := os.Stat(readme); err == nil {
+		mode = "inject"
+	}
+	if mode, err = p.choose("Write a new README, or inject the tables into the regions of the existing one", []string{"new", "inject"}, mode); err != nil {
+		return err
+	}
+	if mode == "inject" {
+		readme, err = p.ask("README to inject into", readme, func(a string) error {
+			_, err := os.Stat(a)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		inject, err := injectSettings(readme, p.out)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, inject...)
+	}
+
+	repoUrl, modulePath, err := tfdoc.DetectRepoUrl(tfPath)
+	detected := false
+	if err == nil {
+		if detected, err = p.confirm(fmt.Sprintf("Link to the module's files at %s", tfdoc.DocsUrl(repoUrl, modulePath, nil)), true); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(p.out, "  Links can't be worked out from the git remote: %v\n", err)
+	}
+	if !detected {
+		url, err := p.ask("Repository URL to link to, or Enter for links relative to the module", "", nil)
+		if err != nil {
+			return err
+		}
+		if url == "" {
+			settings = append(settings, interactiveSetting{"no-detect-repo-url", "true"})
+		} else {
+			settings = append(settings, interactiveSetting{"repoUrl", url})
+			if modulePath != "" {
+				settings = append(settings, interactiveSetting{"modulePath", modulePath})
+			}
+		}
+	}
+
+	if mode == "new" {
+		template := filepath.Join(tfPath, starterTemplateFile)
+		source, err := p.choose("Start from the default template, or a template file you have", []string{"default", "file"}, "default")
+		if err != nil {
+			return err
+		}
+		if source == "file" {
+			if template, err = p.ask("Template file", "", func(a string) error {
+				_, err := os.Stat(a)
+				return err
+			}); err != nil {
+				return err
+			}
+		} else if _, err := os.Stat(template); err == nil {
+			fmt.Fprintf(p.out, "  Using the %s already there\n", template)
+		} else {
+			if err := writer.WriteFile(template, []byte(starterTemplate)); err != nil {
+				return err
+			}
+			fmt.Fprintf(p.out, "  Wrote the default template to %s, edit it to add your own sections\n", template)
+		}
+		settings = append(settings,
+			interactiveSetting{"action", "RenderTemplate"},
+			interactiveSetting{"templatePath", template},
+			interactiveSetting{"output", readme},
+		)
+	}
+
+	fmt.Fprintf(p.out, "\nThe command for this setup is:\n\n    %s\n\n", interactiveCommand(tfPath, settings))
+	configPath := filepath.Join(tfPath, DefaultConfigFile)
+	existing := FindModuleConfig(tfPath)
+	question := fmt.Sprintf("Save these choices in %s", configPath)
+	if existing != "" {
+		question = fmt.Sprintf("Save these choices in %s, which replaces %s", configPath, existing)
+	}
+	save, err := p.confirm(question, existing == "")
+	if err != nil || !save {
+		return err
+	}
+	config, err := interactiveConfig(tfPath, settings)
+	if err != nil {
+		return err
+	}
+	if existing != "" && existing != configPath {
+		// Both would be found, and the first wins
+		if err := writer.Remove(existing); err != nil {
+			return err
+		}
+	}
+	if err := writer.WriteFile(configPath, []byte(config)); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "Saved. From now on, tf2doc -path %s does the same.\n", shellWord(tfPath))
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrompter(t *testing.T) {
+	var out bytes.Buffer
+	p := &prompter{in: bufio.NewReader(strings.NewReader("\nmaybe\nn\nold\ninject\n")), out: &out}
+
+	if yes, err := p.confirm("Save", true); err != nil || !yes {
+		t.Errorf("Enter gave %v, %v, want the default true", yes, err)
+	}
+	if yes, err := p.confirm("Link", true); err != nil || yes {
+		t.Errorf("maybe then n gave %v, %v, want false", yes, err)
+	}
+	if mode, err := p.choose("Mode", []string{"new", "inject"}, "new"); err != nil || mode != "inject" {
+		t.Errorf("old then inject gave %q, %v, want inject", mode, err)
+	}
+	for _, want := range []string{"Save (Y/n): ", "  answer y or n\n", "Mode (new/inject) [new]: ", "  answer one of new, inject\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printed:\n%s\nwant it to contain %q", out.String(), want)
+		}
+	}
+	if _, err := p.ask("Module path", ".", nil); err == nil {
+		t.Error("got no error when the input ended")
+	}
+}
+
+func TestInteractiveCommand(t *testing.T) {
+	settings := []interactiveSetting{
+		{"inject-into", "READ ME.md"},
+		{"inject-region", "inputs=VarsTable"},
+		{"inject-region", "outputs=OutputsTable"},
+		{"no-detect-repo-url", "true"},
+	}
+	want := "tf2doc -path modules/app -inject-into 'READ ME.md' -inject-region inputs=VarsTable -inject-region outputs=OutputsTable -no-detect-repo-url"
+	if got := interactiveCommand("modules/app", settings); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	config, err := interactiveConfig("modules/app", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `# Written by tf2doc -interactive. Paths are relative to where tf2doc -path modules/app is run
+inject-into: READ ME.md
+inject-region:
+    - inputs=VarsTable
+    - outputs=OutputsTable
+no-detect-repo-url: true
+`
+	if config != want {
+		t.Errorf("got:\n%s\nwant:\n%s", config, want)
+	}
+}
+
+func TestInteractiveNeedsATerminal(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": "variable \"name\" {}\n"})
+	_, stderr, code := runTf2doc(t, dir, "-path", ".", "-interactive")
+	if code != 1 || !strings.Contains(stderr, "-interactive needs a terminal on stdin") {
+		t.Errorf("exited %d with stderr:\n%s", code, stderr)
+	}
+}
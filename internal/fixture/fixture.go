@@ -0,0 +1,51 @@
+// Package fixture generates synthetic Terraform modules for checking how TF_2_DOC copes with very large
+// modules, both from tools/genfixture and from benchmarks.
+package fixture
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ResourceTypes are cycled through so the fixture has a realistic spread of types
+var ResourceTypes = []string{
+	"aws_iam_role",
+	"aws_iam_policy",
+	"aws_s3_bucket",
+	"aws_security_group",
+	"aws_route53_record",
+	"aws_cloudwatch_metric_alarm",
+	"aws_lambda_function",
+	"aws_sqs_queue",
+}
+
+// WriteModule writes a module with the given number of resources into dir, perFile resources to each .tf file
+func WriteModule(dir string, resources, perFile int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for start := 0; start < resources || start == 0; start += perFile {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("resources_%04d.tf", start/perFile)))
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(f)
+		if start == 0 {
+			fmt.Fprint(w, "variable \"name_prefix\" {\n  description = \"Prefix for every generated name\"\n  default     = \"fixture\"\n}\n\n")
+		}
+		for i := start; i < start+perFile && i < resources; i++ {
+			t := ResourceTypes[i%len(ResourceTypes)]
+			fmt.Fprintf(w, "resource %q \"r%05d\" {\n  name = \"${var.name_prefix}-%d\"\n}\n\n", t, i, i)
+		}
+		if err := w.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package tfpath handles paths so that what tf2doc writes is the same on every OS: links, manifests and
+// reports use forward slashes, and paths are only turned back into the OS's form where files are opened.
+// Globs are filepath.Match patterns, which have no **.
+//
+// The functions are methods of a Style, the separator and case behaviour of an OS, so that tests can check
+// Windows paths on any OS. Host is the style of the OS tf2doc is running on.
+package tfpath
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Style is how an OS writes paths
+type Style struct {
+	Separator       byte // Between the elements of a path
+	CaseInsensitive bool // The usual filesystems ignore case, so paths differing only in case are the same file
+}
+
+var (
+	Unix    = Style{Separator: '/'}
+	MacOS   = Style{Separator: '/', CaseInsensitive: true}
+	Windows = Style{Separator: '\\', CaseInsensitive: true}
+)
+
+// Host is the style of the OS tf2doc is running on
+var Host = HostStyle(runtime.GOOS)
+
+// HostStyle is the style of the OS named goos, as runtime.GOOS names it
+func HostStyle(goos string) Style {
+	switch goos {
+	case "windows":
+		return Windows
+	case "darwin", "ios":
+		return MacOS
+	}
+	return Unix
+}
+
+// Base is the last element of p, split at forward or back slashes whatever the style, as module JSON from
+// -input-json may have been made on another OS
+func Base(p string) string {
+	return path.Base(strings.Replace(p, `\`, "/", -1))
+}
+
+// ToSlash replaces the separators in p with forward slashes
+func (s Style) ToSlash(p string) string {
+	if s.Separator == '/' {
+		return p
+	}
+	return strings.Replace(p, string(s.Separator), "/", -1)
+}
+
+// FromSlash replaces the forward slashes in p with the separator
+func (s Style) FromSlash(p string) string {
+	if s.Separator == '/' {
+		return p
+	}
+	return strings.Replace(p, "/", string(s.Separator), -1)
+}
+
+// volumeLen is the length of the volume name a slash separated p starts with, like C: or //host/share. Only
+// Windows has them.
+func (s Style) volumeLen(p string) int {
+	if s.Separator != '\\' {
+		return 0
+	}
+	if len(p) >= 2 && p[1] == ':' && ('a' <= p[0] && p[0] <= 'z' || 'A' <= p[0] && p[0] <= 'Z') {
+		return 2
+	}
+	if len(p) > 2 && strings.HasPrefix(p, "//") && p[2] != '/' {
+		// A UNC path, whose volume is //host/share
+		parts := strings.SplitN(p[2:], "/", 3)
+		if len(parts) < 2 {
+			return len(p)
+		}
+		return 2 + len(parts[0]) + 1 + len(parts[1])
+	}
+	return 0
+}
+
+// Clean is p cleaned as path.Clean does, with forward slashes, keeping its volume name
+func (s Style) Clean(p string) string {
+	p = s.ToSlash(p)
+	vol := s.volumeLen(p)
+	if vol == len(p) && vol > 0 {
+		return p
+	}
+	return p[:vol] + path.Clean(p[vol:])
+}
+
+// Key is p as a key for finding duplicates: cleaned, with forward slashes, and lower cased when the style
+// ignores case
+func (s Style) Key(p string) string {
+	key := s.Clean(p)
+	if s.CaseInsensitive {
+		key = strings.ToLower(key)
+	}
+	return key
+}
+
+// LeavesDir reports whether a relative path climbs out of the directory it's relative to, or isn't relative
+// at all
+func (s Style) LeavesDir(rel string) bool {
+	rel = s.ToSlash(rel)
+	if s.volumeLen(rel) > 0 {
+		return true
+	}
+	rel = path.Clean(rel)
+	return rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel)
+}
+
+// Join joins a relative path under root, refusing paths that would leave it. The result has the style's
+// separators.
+func (s Style) Join(root, rel string) (string, error) {
+	if s.LeavesDir(rel) {
+		return "", fmt.Errorf("%q would be outside %s", rel, root)
+	}
+	rel = path.Clean(s.ToSlash(rel))
+	if root == "" {
+		return s.FromSlash(rel), nil
+	}
+	return s.FromSlash(s.Clean(s.ToSlash(root) + "/" + rel)), nil
+}
+
+// Rel is target relative to base, with forward slashes, as a link from base to target is written. base and
+// target must both be absolute, or both relative to the same directory, and on the same volume. Their elements
+// are compared ignoring case when the style does.
+func (s Style) Rel(base, target string) (string, error) {
+	b, t := s.Clean(base), s.Clean(target)
+	bVol, tVol := s.volumeLen(b), s.volumeLen(t)
+	if !s.same(b[:bVol], t[:tVol]) || path.IsAbs(b[bVol:]) != path.IsAbs(t[tVol:]) {
+		return "", fmt.Errorf("can't make %s relative to %s", target, base)
+	}
+	bElems, tElems := elements(b[bVol:]), elements(t[tVol:])
+	common := 0
+	for common < len(bElems) && common < len(tElems) && s.same(bElems[common], tElems[common]) {
+		common++
+	}
+	rel := []string{}
+	for _, e := range bElems[common:] {
+		if e == ".." {
+			return "", fmt.Errorf("can't make %s relative to %s", target, base)
+		}
+		rel = append(rel, "..")
+	}
+	rel = append(rel, tElems[common:]...)
+	if len(rel) == 0 {
+		return ".", nil
+	}
+	return strings.Join(rel, "/"), nil
+}
+
+// same reports whether two path elements name the same file
+func (s Style) same(a, b string) bool {
+	if s.CaseInsensitive {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// elements splits a cleaned, slash separated path without its volume name into its elements
+func elements(p string) []string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" || p == "." {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// Rel is Host.Rel of base and target once both are made absolute
+func Rel(base, target string) (string, error) {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return "", err
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	return Host.Rel(absBase, absTarget)
+}
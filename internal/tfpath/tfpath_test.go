@@ -0,0 +1,194 @@
+package tfpath
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBase(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.tf", "main.tf"},
+		{"modules/vpc/main.tf", "main.tf"},
+		{`C:\stack\modules\vpc\main.tf`, "main.tf"},
+		{`modules\vpc/variables.tf`, "variables.tf"},
+	}
+	for _, test := range tests {
+		if got := Base(test.path); got != test.want {
+			t.Errorf("got %q for %q, want %q", got, test.path, test.want)
+		}
+	}
+}
+
+func TestHostStyle(t *testing.T) {
+	tests := []struct {
+		goos string
+		want Style
+	}{
+		{"linux", Unix},
+		{"freebsd", Unix},
+		{"darwin", MacOS},
+		{"windows", Windows},
+	}
+	for _, test := range tests {
+		if got := HostStyle(test.goos); got != test.want {
+			t.Errorf("got %+v for %s, want %+v", got, test.goos, test.want)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		style Style
+		path  string
+		want  string
+	}{
+		{Unix, "docs/./README.md", "docs/README.md"},
+		{Unix, `docs\README.md`, `docs\README.md`},
+		{Windows, `docs\.\README.md`, "docs/README.md"},
+		{Windows, `C:\stack\modules\..\README.md`, "C:/stack/README.md"},
+		{Windows, `C:`, "C:"},
+		{Windows, `\\fileserver\share\modules\vpc\..\..`, "//fileserver/share/"},
+		{Windows, `\\fileserver\share`, "//fileserver/share"},
+	}
+	for _, test := range tests {
+		if got := test.style.Clean(test.path); got != test.want {
+			t.Errorf("got %q for %q with separator %q, want %q", got, test.path, test.style.Separator, test.want)
+		}
+	}
+}
+
+func TestLeavesDir(t *testing.T) {
+	tests := []struct {
+		style Style
+		rel   string
+		want  bool
+	}{
+		{Unix, "docs/README.md", false},
+		{Unix, "docs/../README.md", false},
+		{Unix, "..", true},
+		{Unix, "../README.md", true},
+		{Unix, "docs/../../README.md", true},
+		{Unix, "/etc/passwd", true},
+		{Unix, "..foo/README.md", false},
+		{Unix, `..\README.md`, false},
+		{Windows, `docs\README.md`, false},
+		{Windows, `..\README.md`, true},
+		{Windows, `docs\..\..\README.md`, true},
+		{Windows, `\README.md`, true},
+		{Windows, `C:\README.md`, true},
+		{Windows, `C:README.md`, true},
+		{Windows, `\\fileserver\share\README.md`, true},
+	}
+	for _, test := range tests {
+		if got := test.style.LeavesDir(test.rel); got != test.want {
+			t.Errorf("got %v for %q with separator %q, want %v", got, test.rel, test.style.Separator, test.want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		style Style
+		root  string
+		rel   string
+		want  string
+	}{
+		{Unix, "out/docs", "modules/vpc/./README.md", "out/docs/modules/vpc/README.md"},
+		{Unix, "", "modules/vpc/README.md", "modules/vpc/README.md"},
+		{Windows, `out\docs`, "modules/vpc/./README.md", `out\docs\modules\vpc\README.md`},
+		{Windows, `C:\out\docs`, `modules\vpc\README.md`, `C:\out\docs\modules\vpc\README.md`},
+		{Windows, `\\fileserver\share\docs`, "README.md", `\\fileserver\share\docs\README.md`},
+	}
+	for _, test := range tests {
+		got, err := test.style.Join(test.root, test.rel)
+		if err != nil || got != test.want {
+			t.Errorf("got %q, %v for %q under %q, want %q", got, err, test.rel, test.root, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		style Style
+		rel   string
+	}{
+		{Unix, "../README.md"},
+		{Unix, "modules/../../README.md"},
+		{Unix, "/README.md"},
+		{Windows, `..\README.md`},
+		{Windows, `D:\README.md`},
+		{Windows, `\README.md`},
+	} {
+		if got, err := test.style.Join("out", test.rel); err == nil {
+			t.Errorf("got %q for %q with separator %q, want an error", got, test.rel, test.style.Separator)
+		}
+	}
+}
+
+func TestRel(t *testing.T) {
+	tests := []struct {
+		style  Style
+		base   string
+		target string
+		want   string
+	}{
+		{Unix, "/repo/docs", "/repo/modules/vpc/main.tf", "../modules/vpc/main.tf"},
+		{Unix, "/repo", "/repo", "."},
+		{Unix, "/repo/Docs", "/repo/docs/README.md", "../docs/README.md"},
+		{MacOS, "/repo/Docs", "/repo/docs/README.md", "README.md"},
+		{Unix, "docs", "modules/vpc", "../modules/vpc"},
+		{Windows, `C:\repo\docs`, `C:\repo\modules\vpc\main.tf`, "../modules/vpc/main.tf"},
+		{Windows, `C:\Repo\Docs`, `c:\repo\docs\pages\VARS_2.md`, "pages/VARS_2.md"},
+		{Windows, `\\fileserver\share\repo`, `\\fileserver\share\repo\modules\vpc`, "modules/vpc"},
+	}
+	for _, test := range tests {
+		got, err := test.style.Rel(test.base, test.target)
+		if err != nil || got != test.want {
+			t.Errorf("got %q, %v for %q from %q with %+v, want %q", got, err, test.target, test.base, test.style, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		style        Style
+		base, target string
+	}{
+		{Unix, "/repo", "modules/vpc"},
+		{Unix, "../docs", "modules/vpc"},
+		{Windows, `C:\repo`, `D:\repo`},
+		{Windows, `C:\repo`, `\\fileserver\share\repo`},
+	} {
+		if got, err := test.style.Rel(test.base, test.target); err == nil {
+			t.Errorf("got %q for %q from %q with separator %q, want an error", got, test.target, test.base, test.style.Separator)
+		}
+	}
+}
+
+func TestHostRel(t *testing.T) {
+	got, err := Rel("docs", filepath.Join("modules", "vpc", "main.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "../modules/vpc/main.tf"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		style Style
+		a, b  string
+		same  bool
+	}{
+		{Unix, "docs/./README.md", "docs/README.md", true},
+		{Unix, "docs/README.md", "docs/readme.md", false},
+		{MacOS, "docs/README.md", "Docs/readme.md", true},
+		{Windows, `docs\README.md`, "Docs/readme.md", true},
+		{Windows, `C:\repo\docs\..\README.md`, "c:/repo/readme.md", true},
+	}
+	for _, test := range tests {
+		if same := test.style.Key(test.a) == test.style.Key(test.b); same != test.same {
+			t.Errorf("got the same key %v for %q and %q with %+v, want %v", same, test.a, test.b, test.style, test.same)
+		}
+	}
+}
@@ -1,23 +1,24 @@
 package main
 
-// TODO - better error handling (remove all panics)
 // TODO - help document explaining template usage
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
-	"regexp"
+	"path/filepath"
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 )
 
 var stderr = log.New(os.Stderr, "", 1)
@@ -27,155 +28,496 @@ var ValidActions = []string{
 	"OutputsTable",
 	"ManagedResourcesTable",
 	"DataSourcesTable",
+	"ModulesTable",
+	"RequirementsTable",
+	"RemovedTable",
+	"EnvMatrix",
+	"Todos",
 	"RenderTemplate",
+	"Update",
+	"Names",
+	"MigrateConfig",
+	"ImportScaffold",
+	"TagCoverage",
+	"Json",
+	"Yaml",
+	"Adopt",
+	"ValidateConfig",
+	"Lint",
+	"All",
+	"Feed",
+	"MarkdownDocument",
+	"RequiredProvidersTable",
+	"InjectReadme",
+	"ProvidersTable",
+	"DiffReadme",
 }
 
 type CliOpts struct {
-	TfPath       string
-	Action       string
-	TemplatePath string
-	RepoUrl      string
-	ModulePath   string
+	TfPath           string
+	Action           string
+	TemplatePath     string
+	RepoUrl          string
+	ModulePath       string
+	MetricsOut       string
+	Sort             string
+	Out              string
+	Output           string
+	MaxInclude       int64
+	TfvarsGlob       string
+	Description      string
+	SplitBy          string
+	OutDir           string
+	Prune            bool
+	MaxOutput        int64
+	Timeout          time.Duration
+	InputJson        string
+	TodoMarkers      []string
+	FailOnTodos      bool
+	Columns          map[string][]tfdoc.ColumnSpec
+	StripPrefix      map[string][]string
+	Group            tfdoc.GroupOptions
+	DryRun           bool
+	DryRunFormat     string
+	LargeAbove       int
+	StrictMarkdown   bool
+	OutLayout        string
+	ManifestOut      string
+	ShowDynamic      bool
+	MetadataFile     string
+	MetadataKeys     []string
+	Tui              bool
+	HeadingIds       string
+	ConstraintBadges bool
+	InjectInto       string
+	InjectMarker     string
+	InjectRegions    []tfdoc.InjectRegion
+	CheckOnly        bool
+	Explain          string
+	ExplainFormat    string
+	Lang             string
+	LocaleOutputs    []tfdoc.LocaleOutput
+	Paginate         map[string]int
+	Kind             string
+	RequiredOnly     bool
+	Separator        string
+	Config           string
+	ConfigLax        bool
+	LintRules        tfdoc.LintRules
+	ImportStyle      string
+	Tags             tfdoc.TagOptions
+	MinTagCoverage   float64
+	AllowErrors      bool
+	LinkTitles       bool
+	TextWidth        int
+	URLRewrites      []tfdoc.URLRewrite `json:"-"` // CacheKey hashes the patterns itself, as a regexp marshals to {}
+	CacheDir         string
+	NoCache          bool
+	CompactPR        bool
+	Collection       bool
+	CompactBudget    int
+	Format           string
+	AnnotateSince    string
+	AnnotateNew      string
+	AnnotateChanged  string
+	FeedSince        string
+	FeedFormat       string
+	NoPositions      bool
+	NoDetectRepoUrl  bool
+	Collapsible      bool
+	WithLint         bool
+	MaxItems         int
+	OverflowMode     string
+	Interactive      bool
 }
 
-type TemplateData struct {
-	TerraformVarsTable             string
-	TerraformOutputsTable          string
-	TerraformManagedResourcesTable string
-	TerraformDataSourcesTable      string
-	TerraformModulesTable          string
-	MarkdownTOC                    string
-	RepoBaseUrl                    string
+var ValidOuts = []string{
+	"stdout",
+	"clipboard",
 }
 
-type TfTableObject struct {
-	Name, Type, Description, Location string
+var ValidSplits = []string{
+	"provider",
 }
 
-func StringInSlice(a string, list []string) bool {
-	for _, b := range list {
-		if b == a {
-			return true
-		}
-	}
-	return false
-}
+// atExit holds work that must happen even when the run fails, like writing the manifest of what was done
+var atExit []func()
 
-func CheckErr(e error, msg string) {
-	if e != nil {
-		if msg != "" {
-			stderr.Println(msg)
-		}
-		stderr.Println(e.Error())
-		os.Exit(1)
+// runAtExit runs and clears atExit
+func runAtExit() {
+	hooks := atExit
+	atExit = nil
+	for _, f := range hooks {
+		f()
 	}
 }
 
-var (
-	rHashHeader        = regexp.MustCompile("^(?P<indent>#+) ?(?P<title>.+)$")
-	rUnderscoreHeader1 = regexp.MustCompile("^=+$")
-	rUnderscoreHeader2 = regexp.MustCompile("^\\-+$")
+// A run that fails, or whose other checks find a change, exits exitFailed. Lint findings exit exitLintFindings,
+// so CI can tell them from a run that failed
+const (
+	exitFailed       = 1
+	exitLintFindings = 2
 )
 
-//  Slugufy came from: https://github.com/sebdah/markdown-toc/tree/master/toc
-func slugify(s string) string {
-	droppedChars := []string{
-		"\"", "'", "`", ".",
-		"!", ",", "~", "&",
-		"%", "^", "*", "#",
-		"@", "|",
-		"(", ")",
-		"{", "}",
-		"[", "]",
-	}
-
-	s = strings.ToLower(s)
-	for _, c := range droppedChars {
-		s = strings.Replace(s, c, "", -1)
-	}
-
-	s = strings.Replace(s, " ", "-", -1)
+// diffActions print the change they would make to a README, which a dry run prints before its manifest
+var diffActions = []string{"Adopt", "DiffReadme"}
 
-	return s
+// fatal reports a user facing error on stderr and exits 1, without the stack trace a panic would print
+func fatal(format string, args ...interface{}) {
+	stderr.Printf(format, args...)
+	runAtExit()
+	os.Exit(exitFailed)
 }
 
-//  https://github.com/sebdah/markdown-toc/tree/master/toc
-func BuildMarkdownToc(d []byte, depth, skipHeaders int) ([]string, error) {
-	toc := []string{
-		"Table of Contents",
-		"=================",
-	}
-
-	seenHeaders := make(map[string]int)
-	var previousLine string
-	appendToC := func(title string, indent int) {
-		link := slugify(title)
-		if skipHeaders > 0 {
-			skipHeaders--
-			return
-		}
+// checkTable is a rendered table, or exits if it failed to render
+func checkTable(table string, err error) string {
+	CheckErr(err, "failed rendering the table")
+	return table
+}
 
-		if _, ok := seenHeaders[link]; ok {
-			seenHeaders[link]++
-			link = fmt.Sprintf("%s-%d", link, seenHeaders[link]-1)
-		} else {
-			seenHeaders[link] = 1
+func CheckErr(e error, msg string) {
+	if e != nil {
+		if msg != "" {
+			stderr.Println(msg)
 		}
-		toc = append(toc, fmt.Sprintf("%s1. [%s](#%s)", strings.Repeat("   ", indent), title, link))
+		stderr.Println(e.Error())
+		runAtExit()
+		os.Exit(exitFailed)
 	}
+}
 
-	s := bufio.NewScanner(bytes.NewReader(d))
-	for s.Scan() {
-		switch {
-		case rHashHeader.Match(s.Bytes()):
-			m := rHashHeader.FindStringSubmatch(s.Text())
-			if depth > 0 && len(m[1]) > depth {
-				continue
-			}
-			appendToC(m[2], len(m[1])-1)
-
-		case rUnderscoreHeader1.Match(s.Bytes()):
-			appendToC(previousLine, 0)
+// stringList is a flag that may be given more than once
+type stringList []string
 
-		case rUnderscoreHeader2.Match(s.Bytes()):
-			if depth > 0 && depth < 2 {
-				continue
-			}
-			appendToC(previousLine, 1)
-		}
-		previousLine = s.Text()
-	}
-	if err := s.Err(); err != nil {
-		return []string{}, err
-	}
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
 
-	return toc, nil
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 func ParseCli() *CliOpts {
 	opts := CliOpts{}
+	var columns, stripPrefixes, urlRewriteValues, injectRegions, localeOutputs, paginate, tagAttributes, lintRules stringList
+	flag.Var(&lintRules, "lint-rule", fmt.Sprintf("Set a naming rule of the Lint action, e.g. 'var-pattern=^[a-z_]+$', or turn one off with 'bool-prefix=off'. May be repeated. Rules are %s", tfdoc.LintRuleNames))
+	flag.Var(&tagAttributes, "tag-attributes", "The arguments that tag resources whose types start with a prefix, e.g. 'aws_=tags,tags_all'. May be repeated")
+	flag.Var(&paginate, "paginate", "Split a table into pages of this many rows beside the document, e.g. 'resources=500'. May be repeated")
+	flag.Var(&localeOutputs, "locale-outputs", "Render templates into files with a language's table headings, e.g. 'en=README.tmpl:README.md,ja=README.ja.tmpl:README.ja.md'. May be repeated")
+	flag.Var(&injectRegions, "inject-region", fmt.Sprintf("Fill a region of -inject-into with an action's output, e.g. 'inputs=VarsTable,outputs=OutputsTable'. May be repeated. Actions are %s", tfdoc.InjectableActions))
+	flag.Var(&urlRewriteValues, "repo-url-rewrite", "Rewrite link URLs matching a regex, e.g. 'https://git.corp/(.*)=https://github.com/mirror/$1'. May be repeated, the first matching rule applies")
+	flag.Var(&stripPrefixes, "strip-prefix", "Remove this prefix from names shown in tables, or table=prefix for one table. May be repeated")
+	flag.Var(&columns, "columns", "Choose table columns and their widths, e.g. 'vars=name,type:40:truncate,description:60:wrap'. May be repeated")
 	tfPathPtr := flag.String("path", "", "The path to the Terraform Module to inspect.")
+	allowErrorsPtr := flag.Bool("allow-errors", false, "Leave out files with errors, noting them in the docs, instead of failing")
+	configPtr := flag.String("config", "", fmt.Sprintf("Read settings from this YAML file of flag names and values, or a %s. Flags given on the command line win", terraformDocsConfigFile))
+	configLaxPtr := flag.Bool("config-lax", false, "Warn about keys of -config that aren't settings instead of failing")
+	noConfigPtr := flag.Bool("no-config", false, "Don't read .tf2doc.yml or .tf2doc.yaml from -path when -config isn't given")
 	actionPtr := flag.String("action", "", fmt.Sprintf("The Action to perform. %s", ValidActions))
 	templatePathPtr := flag.String("templatePath", "", "The path to the template to render")
-	repoUrlPtr := flag.String("repoUrl", "", "The URL path used as a prefix for links")
+	repoUrlPtr := flag.String("repoUrl", "", "The URL path used as a prefix for links. Without it, it's worked out from the git remote origin")
+	noDetectRepoUrlPtr := flag.Bool("no-detect-repo-url", false, "Don't work out -repoUrl from the git remote origin, leaving links relative")
 	modulePathPtr := flag.String("modulePath", "", "The path of the module relative to the repository")
+	manifestOutPtr := flag.String("manifest-out", "", "Write a JSON list of the files this run wrote or pruned, with their sha256 and size, to this file")
+	metadataFilePtr := flag.String("metadata-file", tfdoc.DefaultMetadataFile, "The module's metadata file for templates, relative to the module path")
+	metadataRequiredPtr := flag.String("metadata-required", "", "Comma separated keys that the metadata file must have")
+	metricsOutPtr := flag.String("metrics-out", "", "Write run metrics as JSON to this file")
+	minTagCoveragePtr := flag.Float64("min-tag-coverage", 0, "Exit non-zero if less than this percentage of taggable resources set tags")
+	untaggableTypesPtr := flag.String("untaggable-types", "", "Comma separated resource types without tags, in place of the built-in list")
+	maxIncludePtr := flag.Int64("max-include-size", tfdoc.DefaultMaxIncludeSize, "The largest file in bytes that template functions may include")
+	tfvarsGlobPtr := flag.String("tfvars-glob", "*.tfvars", "The tfvars files to compare for EnvMatrix, relative to the module path")
+	checkOnlyPtr := flag.Bool("check-only", false, "With -action Update, only report whether a newer release is available")
+	constraintBadgesPtr := flag.Bool("constraint-badges", false, "Follow each version constraint in the requirements table with a badge showing it")
+	explainPtr := flag.String("explain", "", "Also write a report of where each table row and template field came from to this file, or - for stderr")
+	explainFormatPtr := flag.String("explain-format", "text", fmt.Sprintf("The format of the -explain report. %s", tfdoc.ValidExplainFormats))
+	dryRunPtr := flag.Bool("dry-run", false, "Print what files would be written instead of writing them. Exits 1 if any would change")
+	dryRunFormatPtr := flag.String("dry-run-format", "text", fmt.Sprintf("The format of the -dry-run manifest. %s", tfdoc.ValidDryRunFormats))
+	groupByPtr := flag.String("group-by", "", fmt.Sprintf("Split tables into one section per group of names. %s", tfdoc.ValidGroupBys))
+	groupDelimiterPtr := flag.String("group-delimiter", "_", "With -group-by prefix, a name's prefix is everything before this")
+	groupMinPtr := flag.Int("group-min", 2, "With -group-by, groups smaller than this go into an \"other\" group")
+	groupTablesPtr := flag.String("group-tables", "outputs", fmt.Sprintf("Comma separated tables that -group-by splits. %s", tfdoc.GroupedTables))
+	headingIdsPtr := flag.String("heading-ids", "off", fmt.Sprintf("Add {#id} attributes to headings and link the TOC to them, for renderers with their own anchors. %s", tfdoc.ValidHeadingIds))
+	injectIntoPtr := flag.String("inject-into", "", "Update the -inject-region regions of this markdown file in place, or with -action Adopt, mark its generated sections as regions")
+	injectMarkerPtr := flag.String("inject-marker", tfdoc.DefaultInjectMarker, "The text that starts region markers, as in <!-- tf2doc:inputs:begin -->")
+	importStylePtr := flag.String("import-style", "cli", fmt.Sprintf("Whether -action ImportScaffold writes terraform import commands or import blocks. %s", tfdoc.ValidImportStyles))
+	inputJsonPtr := flag.String("input-json", "", "Read the module from terraform-config-inspect --json output at this path, or - for stdin, instead of -path")
+	maxOutputPtr := flag.Int64("max-output-size", tfdoc.DefaultMaxOutputSize, "The largest document in bytes that a template may render")
+	timeoutPtr := flag.Duration("render-timeout", tfdoc.DefaultRenderTimeout, "Give up rendering after this long")
+	tuiPtr := flag.Bool("tui", false, "Browse the module's tables in the terminal instead of generating docs")
+	interactivePtr := flag.Bool("interactive", false, "Ask for the common choices in the terminal, then print the command line that makes them and offer to save them in the module's config file")
+	lintIgnorePtr := flag.String("lint-ignore", "", "Comma separated variable and output name patterns, e.g. 'legacy_*', that the Lint action doesn't check")
+	lintPresetPtr := flag.String("lint-preset", "none", fmt.Sprintf("The naming rules the Lint action starts from, before -lint-rule. %s", tfdoc.ValidLintPresets))
+	todoMarkersPtr := flag.String("todo-markers", tfdoc.DefaultTodoMarkers, "Comma separated comment markers that the Todos action reports")
+	failOnTodosPtr := flag.Bool("fail-on-todos", false, "Exit non-zero if any comment contains a -todo-markers marker")
+	outPtr := flag.String("out", "stdout", fmt.Sprintf("Where to send the output. %s", ValidOuts))
+	outputPtr := flag.String("output", "", "Write the output to this file instead of stdout. The run fails before doing any work if it can't be written")
+	annotateSincePtr := flag.String("annotate-since", "", "Mark the variables and outputs added, and variables changed, since this git ref in the tables")
+	annotateNewPtr := flag.String("annotate-new", tfdoc.DefaultAnnotateNew, "The marker for rows added since -annotate-since. {ref} is replaced with the ref")
+	annotateChangedPtr := flag.String("annotate-changed", tfdoc.DefaultAnnotateChanged, "The marker for variables whose type or default changed since -annotate-since. {ref} is replaced with the ref")
+	feedSincePtr := flag.String("feed-since", "", "The git ref whose module interfaces -action Feed reports the changes since")
+	feedFormatPtr := flag.String("feed-format", "json", fmt.Sprintf("The feed -action Feed writes. %s", tfdoc.ValidFeedFormats))
+	maxItemsPtr := flag.Int("max-items", 0, "With -action Json or Yaml, or a table action with -format csv, the most items a list may have before -overflow-mode applies. 0 is no limit")
+	overflowModePtr := flag.String("overflow-mode", "truncate", fmt.Sprintf("What -max-items does with longer lists: truncate them, marking the JSON or YAML truncated, or split them into numbered files named by the output. %s", tfdoc.ValidOverflowModes))
+	noPositionsPtr := flag.Bool("no-positions", false, "Leave the Position column out of -action MarkdownDocument, for the document terraform-docs would write")
+	withLintPtr := flag.Bool("with-lint", false, "Give templates the Lint action's findings and the documentation score, as .LintFindings, .LintSummary and .DocScore")
+	collapsiblePtr := flag.Bool("collapsible", false, "Wrap each table in a <details> section whose summary gives its row count, e.g. Variables (42)")
+	textWidthPtr := flag.Int("text-width", tfdoc.DefaultTextWidth, "With -format text, wrap cells longer than this many characters. 0 never wraps")
+	formatPtr := flag.String("format", "markdown", fmt.Sprintf("How tables are written. %s", tfdoc.ValidFormats))
+	descriptionPtr := flag.String("description", "full", fmt.Sprintf("How much of each description to show in tables. %s", tfdoc.ValidDescriptions))
+	splitByPtr := flag.String("split-by", "", fmt.Sprintf("Write one page per group into -out-dir. %s", ValidSplits))
+	outDirPtr := flag.String("out-dir", "", "The directory that -split-by and -out-layout write pages into")
+	outLayoutPtr := flag.String("out-layout", "", fmt.Sprintf("Write the document into -out-dir instead of -out. %s", tfdoc.ValidOutLayouts))
+	prunePtr := flag.Bool("prune", false, "Remove pages in -out-dir that this run did not write")
+	kindPtr := flag.String("kind", "vars", fmt.Sprintf("What -action Names lists. %s", tfdoc.ValidNameKinds))
+	requiredOnlyPtr := flag.Bool("required-only", false, "With -action Names -kind vars, only list variables without a default")
+	separatorPtr := flag.String("separator", "newline", fmt.Sprintf("What -action Names puts after each name. %s", tfdoc.ValidSeparators))
+	langPtr := flag.String("lang", tfdoc.DefaultLang, fmt.Sprintf("The language of table headings. %s", tfdoc.ValidLangs()))
+	cacheDirPtr := flag.String("cache-dir", "", fmt.Sprintf("Reuse the last output for the module while it, the options, the templates and tf2doc are unchanged, keeping it in this directory, e.g. %s", DefaultCacheDir))
+	noCachePtr := flag.Bool("no-cache", false, "Render the module even if -cache-dir has its output")
+	collectionPtr := flag.Bool("collection", false, "Render an index of the modules under modules/ instead of tables, for a repository root that is a collection of modules")
+	compactPRPtr := flag.Bool("compact-pr", false, "Print the inputs and outputs tables with few columns, cut to -compact-budget, for a PR description. Works alone or with -action VarsTable or OutputsTable")
+	compactBudgetPtr := flag.Int("compact-budget", tfdoc.DefaultCompactBudget, "The most characters -compact-pr prints. 0 for no limit")
+	linkTitlesPtr := flag.Bool("link-titles", false, "Give every generated link a title saying what it links to, for screen readers")
+	largeAbovePtr := flag.Int("large-threshold", tfdoc.DefaultLargeThreshold, "Above this many managed resources templates get counts per type, with the full table written to resources.md. 0 never switches")
+	strictMarkdownPtr := flag.Bool("strict-markdown", false, "Fail instead of warning when a table cell would break the markdown")
+	showDynamicPtr := flag.Bool("show-dynamic", false, "Follow the managed resources table with a table of the dynamic blocks each resource uses")
+	sortPtr := flag.String("sort", "name", fmt.Sprintf("The order of table rows. %s", tfdoc.ValidSorts))
 	flag.Parse()
+	CheckErr(ApplyEnv(), "bad environment variable")
+	// -interactive asks for everything else, so it needs no config and none of the checks
+	if *interactivePtr {
+		opts.Interactive = true
+		opts.TfPath = *tfPathPtr
+		return &opts
+	}
+	// Without -config the module's own config file is read. MigrateConfig reads -config as a terraform-docs
+	// config, so it doesn't look for one.
+	configFile := *configPtr
+	if configFile == "" && *tfPathPtr != "" && !*noConfigPtr && *actionPtr != "MigrateConfig" {
+		configFile = FindModuleConfig(*tfPathPtr)
+	}
+	if configFile != "" && *actionPtr != "MigrateConfig" && *actionPtr != "ValidateConfig" {
+		settings, err := LoadConfig(configFile, *configLaxPtr)
+		CheckErr(err, "bad -config")
+		CheckErr(ApplyConfig(settings), fmt.Sprintf("bad -config %s", configFile))
+	}
 	opts.TfPath = *tfPathPtr
 	opts.Action = *actionPtr
 	opts.TemplatePath = *templatePathPtr
 	opts.RepoUrl = *repoUrlPtr
 	opts.ModulePath = *modulePathPtr
+	opts.MetricsOut = *metricsOutPtr
+	opts.ManifestOut = *manifestOutPtr
+	opts.ShowDynamic = *showDynamicPtr
+	opts.Tui = *tuiPtr
+	opts.HeadingIds = *headingIdsPtr
+	opts.ConstraintBadges = *constraintBadgesPtr
+	opts.MetadataFile = *metadataFilePtr
+	if *metadataRequiredPtr != "" {
+		opts.MetadataKeys = strings.Split(*metadataRequiredPtr, ",")
+	}
+	opts.Sort = *sortPtr
+	opts.InjectInto = *injectIntoPtr
+	opts.CheckOnly = *checkOnlyPtr
+	opts.Explain = *explainPtr
+	opts.Lang = *langPtr
+	opts.Config = configFile
+	opts.ConfigLax = *configLaxPtr
+	opts.AllowErrors = *allowErrorsPtr
+	opts.LinkTitles = *linkTitlesPtr
+	opts.CacheDir = *cacheDirPtr
+	opts.NoCache = *noCachePtr
+	opts.CompactPR = *compactPRPtr
+	opts.Collection = *collectionPtr
+	opts.CompactBudget = *compactBudgetPtr
+	opts.TextWidth = *textWidthPtr
+	opts.Kind = *kindPtr
+	opts.ImportStyle = *importStylePtr
+	opts.MinTagCoverage = *minTagCoveragePtr
+	opts.Tags.Untaggable = tfdoc.DefaultUntaggableTypes
+	if *untaggableTypesPtr != "" {
+		opts.Tags.Untaggable = strings.Split(*untaggableTypesPtr, ",")
+	}
+	opts.RequiredOnly = *requiredOnlyPtr
+	opts.Separator = *separatorPtr
+	opts.ExplainFormat = *explainFormatPtr
+	opts.InjectMarker = *injectMarkerPtr
+	opts.Group = tfdoc.GroupOptions{By: *groupByPtr, Delimiter: *groupDelimiterPtr, MinSize: *groupMinPtr}
+	opts.Out = *outPtr
+	opts.Output = *outputPtr
+	opts.Description = *descriptionPtr
+	opts.Format = *formatPtr
+	opts.AnnotateSince = *annotateSincePtr
+	opts.AnnotateNew = *annotateNewPtr
+	opts.AnnotateChanged = *annotateChangedPtr
+	opts.FeedSince = *feedSincePtr
+	opts.FeedFormat = *feedFormatPtr
+	opts.NoPositions = *noPositionsPtr
+	opts.NoDetectRepoUrl = *noDetectRepoUrlPtr
+	opts.Collapsible = *collapsiblePtr
+	opts.WithLint = *withLintPtr
+	opts.MaxItems = *maxItemsPtr
+	opts.OverflowMode = *overflowModePtr
+	opts.MaxInclude = *maxIncludePtr
+	opts.TfvarsGlob = *tfvarsGlobPtr
+	opts.SplitBy = *splitByPtr
+	opts.OutDir = *outDirPtr
+	opts.OutLayout = *outLayoutPtr
+	opts.Prune = *prunePtr
+	opts.MaxOutput = *maxOutputPtr
+	opts.Timeout = *timeoutPtr
+	opts.InputJson = *inputJsonPtr
+	opts.TodoMarkers = strings.Split(*todoMarkersPtr, ",")
+	opts.FailOnTodos = *failOnTodosPtr
+	opts.DryRun = *dryRunPtr
+	opts.DryRunFormat = *dryRunFormatPtr
+	opts.LargeAbove = *largeAbovePtr
+	opts.StrictMarkdown = *strictMarkdownPtr
 
-	if opts.TfPath == "" {
+	if opts.TfPath == "" && opts.InputJson == "" && opts.Action != "Update" && !((opts.Action == "MigrateConfig" || opts.Action == "ValidateConfig") && opts.Config != "") {
 		flag.Usage()
-		panic("no TF Path set")
+		fatal("no module given: set -path to the module's directory, or -input-json to terraform-config-inspect output")
+	}
+	if opts.TfPath != "" {
+		if info, err := os.Stat(opts.TfPath); err != nil {
+			fatal("-path %s: %v", opts.TfPath, err)
+		} else if !info.IsDir() {
+			fatal("-path %s is not a directory, set it to the module's directory", opts.TfPath)
+		}
 	}
-	if opts.Action == "" {
+	if opts.Action == "" && opts.SplitBy == "" && opts.InjectInto == "" && len(localeOutputs) == 0 && !opts.Tui && !opts.CompactPR && !opts.Collection {
 		flag.Usage()
-		panic("No Action set")
+		fatal("nothing to do: set -action, or one of -split-by, -inject-into, -locale-outputs, -tui, -compact-pr and -collection")
+	}
+	if opts.Action != "" && !tfdoc.StringInSlice(opts.Action, ValidActions) {
+		fatal("action %s is not one of: %s", opts.Action, ValidActions)
+	}
+	var err error
+	opts.Columns, err = tfdoc.ParseColumnSpecs(columns)
+	CheckErr(err, "bad -columns")
+	opts.StripPrefix, err = tfdoc.ParseStripPrefixes(stripPrefixes)
+	CheckErr(err, "bad -strip-prefix")
+	opts.URLRewrites, err = tfdoc.ParseURLRewrites(urlRewriteValues)
+	CheckErr(err, "bad -repo-url-rewrite")
+	opts.InjectRegions, err = tfdoc.ParseInjectRegions(injectRegions)
+	CheckErr(err, "bad -inject-region")
+	opts.LocaleOutputs, err = tfdoc.ParseLocaleOutputs(localeOutputs)
+	CheckErr(err, "bad -locale-outputs")
+	opts.Paginate, err = tfdoc.ParsePaginate(paginate)
+	CheckErr(err, "bad -paginate")
+	opts.Tags.Attributes, err = tfdoc.ParseTagAttributes(tagAttributes)
+	CheckErr(err, "bad -tag-attributes")
+	opts.LintRules, err = tfdoc.ParseLintRules(*lintPresetPtr, lintRules)
+	CheckErr(err, "bad -lint-rule")
+	opts.LintRules.Ignore, err = tfdoc.ParseLintIgnore(*lintIgnorePtr)
+	CheckErr(err, "bad -lint-ignore")
+	if _, ok := tfdoc.HeadingCatalogs[opts.Lang]; !ok {
+		CheckErr(fmt.Errorf("lang %s is not one of: %s", opts.Lang, tfdoc.ValidLangs()), "")
+	}
+	if _, ok := tfdoc.CompactActions[opts.Action]; opts.CompactPR && !ok {
+		CheckErr(fmt.Errorf("-compact-pr works alone or with -action VarsTable or OutputsTable, not %s", opts.Action), "")
+	}
+	if opts.Action == "Adopt" && opts.InjectInto == "" {
+		CheckErr(errors.New("-action Adopt needs -inject-into, the README to adopt"), "")
+	}
+	if opts.InjectInto != "" && len(opts.InjectRegions) == 0 && opts.Action != "Adopt" {
+		CheckErr(errors.New("-inject-into needs -inject-region"), "")
+	}
+	if opts.InjectMarker == "" {
+		CheckErr(errors.New("-inject-marker must not be empty"), "")
+	}
+	opts.Group.Tables, err = tfdoc.ParseGroupTables(*groupTablesPtr)
+	CheckErr(err, "bad -group-tables")
+	if opts.Group.By != "" && !tfdoc.StringInSlice(opts.Group.By, tfdoc.ValidGroupBys) {
+		CheckErr(fmt.Errorf("group-by %s is not one of: %s", opts.Group.By, tfdoc.ValidGroupBys), "")
+	}
+	if opts.Group.Delimiter == "" {
+		CheckErr(errors.New("-group-delimiter must not be empty"), "")
+	}
+	if !tfdoc.StringInSlice(opts.DryRunFormat, tfdoc.ValidDryRunFormats) {
+		CheckErr(fmt.Errorf("dry-run-format %s is not one of: %s", opts.DryRunFormat, tfdoc.ValidDryRunFormats), "")
+	}
+	if !tfdoc.StringInSlice(opts.ImportStyle, tfdoc.ValidImportStyles) {
+		CheckErr(fmt.Errorf("import-style %s is not one of: %s", opts.ImportStyle, tfdoc.ValidImportStyles), "")
 	}
-	if !StringInSlice(opts.Action, ValidActions) {
-		panic(fmt.Sprintf("Action %s is not one of: %s", opts.Action, ValidActions))
+	if !tfdoc.StringInSlice(opts.Kind, tfdoc.ValidNameKinds) {
+		CheckErr(fmt.Errorf("kind %s is not one of: %s", opts.Kind, tfdoc.ValidNameKinds), "")
+	}
+	if !tfdoc.StringInSlice(opts.Separator, tfdoc.ValidSeparators) {
+		CheckErr(fmt.Errorf("separator %s is not one of: %s", opts.Separator, tfdoc.ValidSeparators), "")
+	}
+	if opts.RequiredOnly && opts.Kind != "vars" {
+		CheckErr(errors.New("-required-only only applies to -kind vars"), "")
+	}
+	if !tfdoc.StringInSlice(opts.ExplainFormat, tfdoc.ValidExplainFormats) {
+		CheckErr(fmt.Errorf("explain-format %s is not one of: %s", opts.ExplainFormat, tfdoc.ValidExplainFormats), "")
+	}
+	if !tfdoc.StringInSlice(opts.HeadingIds, tfdoc.ValidHeadingIds) {
+		CheckErr(fmt.Errorf("heading-ids %s is not one of: %s", opts.HeadingIds, tfdoc.ValidHeadingIds), "")
+	}
+	if !tfdoc.StringInSlice(opts.Out, ValidOuts) {
+		CheckErr(fmt.Errorf("out %s is not one of: %s", opts.Out, ValidOuts), "")
+	}
+	if !tfdoc.StringInSlice(opts.Format, tfdoc.ValidFormats) {
+		CheckErr(fmt.Errorf("format %s is not one of: %s", opts.Format, tfdoc.ValidFormats), "")
+	}
+	if !tfdoc.StringInSlice(opts.Description, tfdoc.ValidDescriptions) {
+		CheckErr(fmt.Errorf("description %s is not one of: %s", opts.Description, tfdoc.ValidDescriptions), "")
+	}
+	if !tfdoc.StringInSlice(opts.Sort, tfdoc.ValidSorts) {
+		CheckErr(fmt.Errorf("sort %s is not one of: %s", opts.Sort, tfdoc.ValidSorts), "")
+	}
+	if opts.SplitBy != "" && !tfdoc.StringInSlice(opts.SplitBy, ValidSplits) {
+		CheckErr(fmt.Errorf("split-by %s is not one of: %s", opts.SplitBy, ValidSplits), "")
+	}
+	if opts.SplitBy != "" && opts.OutDir == "" {
+		CheckErr(errors.New("-split-by needs -out-dir"), "")
+	}
+	if opts.OutLayout != "" && !tfdoc.StringInSlice(opts.OutLayout, tfdoc.ValidOutLayouts) {
+		CheckErr(fmt.Errorf("out-layout %s is not one of: %s", opts.OutLayout, tfdoc.ValidOutLayouts), "")
+	}
+	if opts.OutLayout != "" && opts.OutDir == "" {
+		CheckErr(errors.New("-out-layout needs -out-dir"), "")
+	}
+	if !tfdoc.StringInSlice(opts.FeedFormat, tfdoc.ValidFeedFormats) {
+		CheckErr(fmt.Errorf("feed-format %s is not one of: %s", opts.FeedFormat, tfdoc.ValidFeedFormats), "")
+	}
+	if opts.Action == "Feed" && opts.FeedSince == "" {
+		CheckErr(errors.New("-action Feed needs -feed-since, the ref to report changes since"), "")
+	}
+	if opts.WithLint && opts.TemplatePath == "" && len(opts.LocaleOutputs) == 0 {
+		CheckErr(errors.New("-with-lint gives templates the lint results, so it needs -templatePath or -locale-outputs"), "")
+	}
+	if opts.MaxItems < 0 {
+		CheckErr(fmt.Errorf("max-items %d is negative", opts.MaxItems), "")
+	}
+	if !tfdoc.StringInSlice(opts.OverflowMode, tfdoc.ValidOverflowModes) {
+		CheckErr(fmt.Errorf("overflow-mode %s is not one of: %s", opts.OverflowMode, tfdoc.ValidOverflowModes), "")
+	}
+	if _, table := tfdoc.OverflowTables[opts.Action]; opts.MaxItems > 0 && opts.Action != "Json" && opts.Action != "Yaml" && !(table && opts.Format == "csv") {
+		CheckErr(errors.New("-max-items works with -action Json or Yaml, or a table action with -format csv"), "")
+	}
+	if opts.Collapsible && opts.Format != "markdown" && opts.Format != "html" {
+		CheckErr(fmt.Errorf("-collapsible wraps tables in HTML <details> sections, so it can't use -format %s", opts.Format), "")
+	}
+	if (opts.Action == "InjectReadme" || opts.Action == "DiffReadme") && opts.Output == "" {
+		CheckErr(fmt.Errorf("-action %s needs -output, the README to inject the tables into", opts.Action), "")
+	}
+	if opts.Action == "DiffReadme" && opts.DryRun {
+		CheckErr(errors.New("-action DiffReadme writes nothing, so -dry-run would only hide its diff"), "")
+	}
+	if (opts.Action == "All" || opts.Action == "MarkdownDocument" || opts.Action == "InjectReadme" || opts.Action == "DiffReadme") && opts.Format != "markdown" {
+		CheckErr(fmt.Errorf("-action %s puts the tables under markdown headings, so it can't use -format %s", opts.Action, opts.Format), "")
+	}
+	if opts.Collection && (opts.Action != "" || opts.CompactPR) {
+		CheckErr(errors.New("-collection renders its own index, so it can't be combined with -action or -compact-pr"), "")
+	}
+	if opts.Output != "" && opts.OutLayout != "" {
+		CheckErr(errors.New("-output and -out-layout both say where the output goes, give one"), "")
+	}
+	if opts.Output != "" && opts.Out != "stdout" {
+		CheckErr(fmt.Errorf("-output and -out %s both say where the output goes, give one", opts.Out), "")
 	}
 	if opts.Action == "RenderTemplate" && opts.TemplatePath == "" {
 		CheckErr(errors.New("no Template path specified"), "")
@@ -184,207 +526,717 @@ func ParseCli() *CliOpts {
 	return &opts
 }
 
-func MarkdownTableCellEscape(cellText string) string {
-	// Replace Newlines with <br/>
-	re := regexp.MustCompile(`\r?\n`)
-	cellText = re.ReplaceAllString(cellText, "<br>")
-	return cellText
-}
+func main() {
 
-func MarkdownTable(headings []string, lengths []string, data [][]string) string {
-	// TODO - input/parameter validation
-	table := "|"
-	for _, h := range headings {
-		table += fmt.Sprintf(" %s |", h)
+	cliOpts := ParseCli()
+	if cliOpts.Interactive {
+		CheckErr(RunInteractive(cliOpts.TfPath), "")
+		return
 	}
-	table += "\n|"
-	for _, l := range lengths {
-		table += fmt.Sprintf(" %s |", l)
+	metrics := NewRunMetrics(cliOpts.Action)
+	if cliOpts.Action == "Update" {
+		CheckErr(RunUpdate(cliOpts.CheckOnly), "update failed")
+		return
 	}
-
-	for _, d := range data {
-		table += "\n|"
-		for _, val := range d {
-			table += fmt.Sprintf(" %s |", MarkdownTableCellEscape(val))
+	if cliOpts.Action == "MigrateConfig" {
+		file := cliOpts.Config
+		if file == "" {
+			file = filepath.Join(cliOpts.TfPath, terraformDocsConfigFile)
 		}
+		config, err := MigrateConfig(file)
+		CheckErr(err, "failed migrating the terraform-docs config")
+		fmt.Print(config)
+		return
 	}
 
-	return table
+	if cliOpts.Action == "ValidateConfig" {
+		file := cliOpts.Config
+		if file == "" {
+			file = filepath.Join(cliOpts.TfPath, DefaultConfigFile)
+		}
+		_, err := LoadConfig(file, cliOpts.ConfigLax)
+		CheckErr(err, "invalid config")
+		stderr.Printf("%s is valid", file)
+		return
+	}
+	// DiffReadme only reads -output
+	if cliOpts.Output != "" && !cliOpts.DryRun && cliOpts.Action != "DiffReadme" {
+		CheckErr(tfdoc.CheckWritable(cliOpts.Output), fmt.Sprintf("can't write -output %s", cliOpts.Output))
+	}
+	if cliOpts.RepoUrl == "" && !cliOpts.NoDetectRepoUrl && cliOpts.TfPath != "" {
+		repoUrl, modulePath, err := tfdoc.DetectRepoUrl(cliOpts.TfPath)
+		if err != nil {
+			stderr.Printf("no -repoUrl given and it couldn't be worked out from the git remote, so links are relative: %v", err)
+		} else {
+			cliOpts.RepoUrl = repoUrl
+			if cliOpts.ModulePath == "" {
+				cliOpts.ModulePath = modulePath
+			}
+		}
+	}
 
-}
+	var cache *RenderCache
+	if cliOpts.CacheDir != "" && !cliOpts.NoCache && cacheable(cliOpts) {
+		key, err := CacheKey(cliOpts)
+		CheckErr(err, "failed hashing the module for -cache-dir")
+		cache, err = NewRenderCache(cliOpts.CacheDir, cliOpts.TfPath, key)
+		CheckErr(err, "")
+		if entry, ok := cache.Load(); ok {
+			writer := newFileWriter(cliOpts)
+			for _, f := range entry.Files {
+				CheckErr(writer.WriteFile(f.Path, f.Content), fmt.Sprintf("failed writing %s", f.Path))
+			}
+			if cliOpts.MetricsOut != "" {
+				metrics.AddCachedModule(cliOpts.TfPath)
+				CheckErr(metrics.Write(writer, cliOpts.MetricsOut), fmt.Sprintf("failed writing metrics: %s", cliOpts.MetricsOut))
+			}
+			writeOutput(cliOpts, writer, entry.Output)
+			return
+		}
+		metrics.CacheLookup(false)
+	}
 
-func getSortedKeys(objs map[string]TfTableObject) []string {
-	keys := make([]string, 0, len(objs))
-	for k := range objs {
-		keys = append(keys, k)
+	loadStart := time.Now()
+	var module *tfconfig.Module
+	var diags tfconfig.Diagnostics
+	if cliOpts.InputJson != "" {
+		var err error
+		module, diags, err = tfdoc.LoadModuleJSON(cliOpts.InputJson)
+		CheckErr(err, "Problem Loading Module JSON")
+		if cliOpts.TfPath == "" {
+			cliOpts.TfPath = module.Path
+		}
+	} else {
+		module, diags = tfconfig.LoadModule(cliOpts.TfPath)
 	}
-	sort.Strings(keys)
-	return keys
-}
+	loadDuration := time.Since(loadStart)
+	//baseUrl := GitLabBaseUrl(cliOpts.TfPath)
 
-func GetVarsTable(module *tfconfig.Module, baseUrl, modulePath string) string {
-	headings := []string{"Variable", "Type", "Description", "Code Position"}
-	lengths := []string{"----", "------", "--------", "------"}
-	data := [][]string{}
+	skipped := []tfdoc.SkippedFile{}
+	if diags.HasErrors() && cliOpts.AllowErrors {
+		var err error
+		skipped, err = tfdoc.IsolateBrokenFiles(module, diags)
+		CheckErr(err, "Problem Loading Module")
+		for _, f := range skipped {
+			metrics.Warnings++
+			stderr.Printf("leaving %s out of the docs: %s", f.Filename, strings.Join(f.Diagnostics, "; "))
+		}
+	} else if diags.HasErrors() {
+		fatal("Problem Loading Module at %s: %s\nUse -allow-errors to leave out the files with errors", cliOpts.TfPath, diags.Error())
+	}
 
-	// Make a map of item objects
-	var objs = make(map[string]TfTableObject)
-	for _, item := range module.Variables {
-		tfpathbits := strings.Split(item.Pos.Filename, "/")
-		tffile := tfpathbits[len(tfpathbits)-1]
-		objs[item.Name] = TfTableObject{
-			Name:        item.Name,
-			Type:        item.Type,
-			Description: item.Description,
-			Location:    fmt.Sprintf("[%s: %d](%s/%s/%s#L%d)", tffile, item.Pos.Line, baseUrl, modulePath, tffile, item.Pos.Line),
+	// Feed diffs each module of a collection
+	if !cliOpts.Collection && cliOpts.InputJson == "" && cliOpts.Action != "Feed" {
+		collection, err := tfdoc.IsCollection(cliOpts.TfPath)
+		CheckErr(err, "failed checking for child modules")
+		if collection {
+			stderr.Printf("%s has no .tf files of its own, only modules under %s/, so its tables are empty. Use -collection for an index of its modules", cliOpts.TfPath, tfdoc.CollectionModulesDir)
 		}
 	}
-	for _, k := range getSortedKeys(objs) {
-		data = append(data, []string{objs[k].Name, objs[k].Type, objs[k].Description, objs[k].Location})
+
+	experiments, err := tfdoc.FindExperiments(cliOpts.TfPath)
+	CheckErr(err, "failed checking for language experiments")
+	for _, name := range experiments {
+		metrics.Warnings++
+		stderr.Printf("the module enables the %s experiment, so the docs may be incomplete", name)
+	}
+	undocumented, err := tfdoc.FindUndocumentedBlocks(cliOpts.TfPath)
+	CheckErr(err, "failed checking for undocumented blocks")
+	if len(undocumented) > 0 {
+		stderr.Printf("undocumented block types: %s", tfdoc.UndocumentedSummary(undocumented))
 	}
 
-	return MarkdownTable(headings, lengths, data)
-}
+	tableOpts := tfdoc.Options{Sort: cliOpts.Sort, Description: cliOpts.Description, Columns: cliOpts.Columns, StripPrefixes: cliOpts.StripPrefix, ShowDynamic: cliOpts.ShowDynamic, ConstraintBadges: cliOpts.ConstraintBadges, Group: cliOpts.Group, Lang: cliOpts.Lang, Format: cliOpts.Format,
+		LinkTitles: cliOpts.LinkTitles, TextWidth: cliOpts.TextWidth, URLRewrites: cliOpts.URLRewrites, MarkdownWarnings: &tfdoc.MarkdownWarnings{}, Included: &tfdoc.IncludedFiles{}}
+	if cliOpts.AnnotateSince != "" {
+		base, err := tfdoc.LoadModuleAt(cliOpts.TfPath, cliOpts.AnnotateSince)
+		CheckErr(err, fmt.Sprintf("failed loading the module at %s", cliOpts.AnnotateSince))
+		tableOpts.Changes = tfdoc.ChangeMarkers(base, module, cliOpts.AnnotateSince, cliOpts.AnnotateNew, cliOpts.AnnotateChanged)
+	}
+	if cliOpts.Tui {
+		CheckErr(tfdoc.RunTui(module, cliOpts.TfPath, tableOpts), "")
+		return
+	}
+	renderStart := time.Now()
+	var out bytes.Buffer
+	writer := newFileWriter(cliOpts)
 
-func GetOutputsTable(module *tfconfig.Module, baseUrl, modulePath string) string {
-	headings := []string{"Output name", "Description", "Code Position"}
-	lengths := []string{"----", "--------", "------"}
-	data := [][]string{}
+	if cliOpts.Explain != "" {
+		tableOpts.Explainer = &tfdoc.Explainer{}
+	}
 
-	var objs = make(map[string]TfTableObject) // Make a map of output objects
-	for _, item := range module.Outputs {
-		tfpathbits := strings.Split(item.Pos.Filename, "/")
-		tffile := tfpathbits[len(tfpathbits)-1]
-		objs[item.Name] = TfTableObject{
-			Name:        item.Name,
-			Type:        "",
-			Description: item.Description,
-			Location:    fmt.Sprintf("[%s: %d](%s/%s/%s#L%d)", tffile, item.Pos.Line, baseUrl, modulePath, tffile, item.Pos.Line),
-		}
+	if cliOpts.ManifestOut != "" && !cliOpts.DryRun {
+		atExit = append(atExit, func() {
+			if err := tfdoc.WriteManifest(writer, cliOpts.ManifestOut); err != nil {
+				stderr.Printf("failed writing manifest %s: %v", cliOpts.ManifestOut, err)
+			}
+		})
 	}
 
-	for _, k := range getSortedKeys(objs) {
-		data = append(data, []string{objs[k].Name, objs[k].Description, objs[k].Location})
+	tagged := []tfdoc.TaggedResource{}
+	if cliOpts.Action == "TagCoverage" || cliOpts.Action == "RenderTemplate" || len(cliOpts.LocaleOutputs) > 0 || cliOpts.MinTagCoverage > 0 {
+		var err error
+		tagged, err = tfdoc.FindTaggedResources(cliOpts.TfPath, cliOpts.Tags)
+		CheckErr(err, "failed checking resources for tags")
 	}
-	return MarkdownTable(headings, lengths, data)
-}
 
-func GetManagedResourcesTable(module *tfconfig.Module, baseUrl, modulePath string) string {
-	headings := []string{"Resource Name", "Resource Type", "Code Position"}
-	lengths := []string{"----", "--------", "------"}
-	data := [][]string{}
+	todos := []tfdoc.Todo{}
+	if cliOpts.Action == "Todos" || cliOpts.Action == "RenderTemplate" || len(cliOpts.LocaleOutputs) > 0 || cliOpts.FailOnTodos {
+		var err error
+		todos, err = tfdoc.FindTodos(cliOpts.TfPath, cliOpts.TodoMarkers)
+		CheckErr(err, "failed scanning for TODO markers")
+	}
 
-	var objs = make(map[string]TfTableObject) // Make a map of output objects
-	for _, item := range module.ManagedResources {
-		tfpathbits := strings.Split(item.Pos.Filename, "/")
-		tffile := tfpathbits[len(tfpathbits)-1]
-		objs[item.Name] = TfTableObject{
-			Name:        item.Name,
-			Type:        item.Type,
-			Description: "",
-			Location:    fmt.Sprintf("[%s: %d](%s/%s/%s#L%d)", tffile, item.Pos.Line, baseUrl, modulePath, tffile, item.Pos.Line),
-		}
+	// Links are relative to where the document ends up when there's no repoUrl
+	linkUrl, linkModulePath := cliOpts.RepoUrl, cliOpts.ModulePath
+	docPath := cliOpts.Output
+	if cliOpts.OutLayout == "mirror" {
+		var err error
+		docPath, err = tfdoc.MirrorDocPath(cliOpts.OutDir, cliOpts.TfPath, cliOpts.ModulePath)
+		CheckErr(err, "bad -out-layout")
 	}
-	for _, k := range getSortedKeys(objs) {
-		data = append(data, []string{objs[k].Name, objs[k].Type, objs[k].Location})
+	if docPath != "" && linkUrl == "" {
+		var err error
+		linkUrl, err = tfdoc.RelativeBaseUrl(filepath.Dir(docPath), cliOpts.TfPath)
+		CheckErr(err, "")
+		linkModulePath = ""
 	}
-	return MarkdownTable(headings, lengths, data)
-}
 
-func GetDataSourcesTable(module *tfconfig.Module, baseUrl, modulePath string) string {
-	headings := []string{"Resource Name", "Resource Type", "Code Position"}
-	lengths := []string{"----", "--------", "------"}
-	data := [][]string{}
+	if cliOpts.SplitBy == "provider" {
+		CheckErr(tfdoc.WriteProviderPages(writer, module, pageOptions(cliOpts), tableOpts), "failed writing provider pages")
+	}
+
+	// Links in -inject-into are relative to it when there's no repoUrl
+	injectUrl, injectModulePath := cliOpts.RepoUrl, cliOpts.ModulePath
+	if cliOpts.InjectInto != "" && injectUrl == "" {
+		var err error
+		injectUrl, err = tfdoc.RelativeBaseUrl(filepath.Dir(cliOpts.InjectInto), cliOpts.TfPath)
+		CheckErr(err, "")
+		injectModulePath = ""
+	}
 
-	var objs = make(map[string]TfTableObject) // Make a map of output objects
-	for _, item := range module.DataResources {
-		tfpathbits := strings.Split(item.Pos.Filename, "/")
-		tffile := tfpathbits[len(tfpathbits)-1]
-		objs[item.Name] = TfTableObject{
-			Name:        item.Name,
-			Type:        item.Type,
-			Description: "",
-			Location:    fmt.Sprintf("[%s: %d](%s/%s/%s#L%d)", tffile, item.Pos.Line, baseUrl, modulePath, tffile, item.Pos.Line),
+	// Adopt marks the regions of -inject-into itself
+	if cliOpts.InjectInto != "" && cliOpts.Action != "Adopt" {
+		removed, err := tfdoc.FindRemovedBlocks(cliOpts.TfPath)
+		CheckErr(err, "failed reading removed blocks")
+		contents := make(map[string]string)
+		for _, r := range cliOpts.InjectRegions {
+			tableOpts.Explainer.Explain(tfdoc.Explanation{Region: "injected region " + r.Name, File: cliOpts.InjectInto, Notes: []string{fmt.Sprintf("filled with the %s action by -inject-region", r.Action)}})
+			switch r.Action {
+			case "VarsTable":
+				contents[r.Name] = checkTable(tfdoc.VarsTable(module, injectUrl, injectModulePath, tableOpts))
+			case "OutputsTable":
+				contents[r.Name] = checkTable(tfdoc.OutputsTable(module, injectUrl, injectModulePath, tableOpts))
+			case "ManagedResourcesTable":
+				contents[r.Name] = checkTable(tfdoc.ManagedResourcesTable(module, injectUrl, injectModulePath, tableOpts))
+			case "DataSourcesTable":
+				contents[r.Name] = checkTable(tfdoc.DataSourcesTable(module, injectUrl, injectModulePath, tableOpts))
+			case "RequirementsTable":
+				contents[r.Name] = checkTable(tfdoc.RequirementsTable(module, experiments, tableOpts))
+			case "RequiredProvidersTable":
+				contents[r.Name] = checkTable(tfdoc.RequiredProvidersTable(module, tableOpts))
+			case "ProvidersTable":
+				configs, err := tfdoc.FindProviderConfigs(module, cliOpts.TfPath)
+				CheckErr(err, "failed reading provider configurations")
+				contents[r.Name] = checkTable(tfdoc.ProvidersTable(configs, injectUrl, injectModulePath, tableOpts))
+			case "RemovedTable":
+				contents[r.Name] = tfdoc.RemovedTable(removed, injectUrl, injectModulePath, tableOpts)
+			}
 		}
+		CheckErr(tfdoc.InjectRegions(writer, cliOpts.InjectInto, cliOpts.InjectMarker, contents), "failed injecting docs")
 	}
-	for _, k := range getSortedKeys(objs) {
-		data = append(data, []string{objs[k].Name, objs[k].Type, objs[k].Location})
+
+	// renderDocument renders docOpts.TemplatePath into out, with links built from docUrl and docModulePath
+	renderDocument := func(docOpts *CliOpts, docTableOpts tfdoc.Options, docUrl, docModulePath string, out *bytes.Buffer) {
+		// Load the template
+		name := path.Base(docOpts.TemplatePath)
+		docTableOpts.Format = templateFormat(docTableOpts.Format, docOpts.TemplatePath)
+		var tocEntries []tfdoc.TocEntry
+		t, err := template.New(name).Funcs(tfdoc.TemplateFuncs(docOpts.TemplatePath, docOpts.MaxInclude, docOpts.Lang, module, &tocEntries, docTableOpts.Included)).ParseFiles(docOpts.TemplatePath)
+		CheckErr(err, fmt.Sprintf("Problem loading template: %s", docOpts.TemplatePath))
+
+		readmeTemplateBytes, err := ioutil.ReadFile(docOpts.TemplatePath)
+		CheckErr(err, "Failed to read template: %s")
+		tocEntries, err = tfdoc.BuildTocEntries(readmeTemplateBytes, 3, 0)
+		CheckErr(err, fmt.Sprintf("failed building the table of contents of %s", docOpts.TemplatePath))
+
+		var resourcesTable string
+		if docOpts.LargeAbove > 0 && len(module.ManagedResources) > docOpts.LargeAbove {
+			listing, err := tfdoc.WriteResourceListing(writer, module, pageOptions(docOpts), docTableOpts)
+			CheckErr(err, "failed writing the resource listing")
+			resourcesTable = tfdoc.ResourceTypeSummary(module, listing, docTableOpts)
+		} else {
+			resourcesTable = checkTable(tfdoc.ManagedResourcesTable(module, docUrl, docModulePath, docTableOpts))
+		}
+
+		owners, err := tfdoc.FindOwners(docOpts.TfPath, docOpts.RepoUrl, docTableOpts)
+		CheckErr(err, "failed reading CODEOWNERS")
+		deprecation, err := tfdoc.FindDeprecation(docOpts.TfPath)
+		CheckErr(err, "failed checking whether the module is deprecated")
+		removed, err := tfdoc.FindRemovedBlocks(docOpts.TfPath)
+		CheckErr(err, "failed reading removed blocks")
+		providerConfigs, err := tfdoc.FindProviderConfigs(module, docOpts.TfPath)
+		CheckErr(err, "failed reading provider configurations")
+		meta, err := tfdoc.LoadMetadata(docOpts.TfPath, docOpts.MetadataFile, docOpts.MetadataKeys)
+		CheckErr(err, "failed loading module metadata")
+		repetition, err := tfdoc.FindRepetition(docOpts.TfPath)
+		CheckErr(err, "failed checking resources for count and for_each")
+
+		data := tfdoc.DocData{
+			TerraformOutputsTable:           checkTable(tfdoc.OutputsTable(module, docUrl, docModulePath, docTableOpts)),
+			TerraformVarsTable:              checkTable(tfdoc.VarsTable(module, docUrl, docModulePath, docTableOpts)),
+			TerraformManagedResourcesTable:  resourcesTable,
+			TerraformDataSourcesTable:       checkTable(tfdoc.DataSourcesTable(module, docUrl, docModulePath, docTableOpts)),
+			TerraformModulesTable:           checkTable(tfdoc.ModulesTable(module, docUrl, docModulePath, docTableOpts)),
+			TerraformRequirementsTable:      checkTable(tfdoc.RequirementsTable(module, experiments, docTableOpts)),
+			TerraformRequiredProvidersTable: checkTable(tfdoc.RequiredProvidersTable(module, docTableOpts)),
+			TerraformProvidersTable:         checkTable(tfdoc.ProvidersTable(providerConfigs, docUrl, docModulePath, docTableOpts)),
+			TerraformRemovedTable:           tfdoc.RemovedTable(removed, docUrl, docModulePath, docTableOpts),
+			TerraformTodos:                  tfdoc.TodoChecklist(todos),
+			MarkdownTOC:                     strings.Join(tfdoc.FormatToc(tocEntries, docTableOpts), "\n"),
+			TOCEntries:                      tocEntries,
+			RepoBaseUrl:                     docOpts.RepoUrl,
+			Owners:                          owners,
+			Deprecated:                      deprecation.Deprecated,
+			DeprecationMessage:              deprecation.Message,
+			Replacement:                     deprecation.Replacement,
+			Meta:                            meta,
+			Experiments:                     experiments,
+			UndocumentedBlocks:              undocumented,
+			TerraformMetadataTable:          tfdoc.MetadataTable(meta),
+			TerraformImportScaffold:         tfdoc.ImportScaffoldFence(tfdoc.ImportScaffold(module, repetition, docOpts.ImportStyle), docOpts.ImportStyle),
+			TerraformTagCoverage:            tfdoc.TagCoverageTable(tagged, docUrl, docModulePath, docTableOpts),
+		}
+		if docOpts.WithLint {
+			data.LintFindings = tfdoc.TemplateLintFindings(tfdoc.Lint(module, docOpts.LintRules))
+			data.LintSummary = tfdoc.LintSummary(data.LintFindings)
+			data.DocScore = tfdoc.DocScore(module)
+		}
+		if docOpts.Collapsible {
+			for _, t := range []struct {
+				Table   *string
+				Summary string
+				Rows    int
+			}{
+				{&data.TerraformVarsTable, "Variables", len(module.Variables)},
+				{&data.TerraformOutputsTable, "Outputs", len(module.Outputs)},
+				{&data.TerraformManagedResourcesTable, "Resources", len(module.ManagedResources)},
+				{&data.TerraformDataSourcesTable, "Data Sources", len(module.DataResources)},
+				{&data.TerraformModulesTable, "Modules", len(module.ModuleCalls)},
+				{&data.TerraformRequirementsTable, "Requirements", tfdoc.RequirementsRows(module, experiments)},
+				{&data.TerraformRequiredProvidersTable, "Providers", len(module.RequiredProviders)},
+				{&data.TerraformProvidersTable, "Provider Configurations", len(providerConfigs)},
+			} {
+				*t.Table = tfdoc.CollapseTable(tfdoc.LocalHeadings(docOpts.Lang, []string{t.Summary})[0], t.Rows, *t.Table)
+			}
+		}
+		for _, table := range tfdoc.ColumnTableNames() {
+			pageSize, ok := docOpts.Paginate[table]
+			if !ok {
+				continue
+			}
+			summary, err := tfdoc.WriteTablePages(writer, module, table, pageSize, pageOptions(docOpts), docTableOpts)
+			CheckErr(err, fmt.Sprintf("failed writing the pages of the %s table", table))
+			if summary == "" {
+				continue
+			}
+			switch table {
+			case "vars":
+				data.TerraformVarsTable = summary
+			case "outputs":
+				data.TerraformOutputsTable = summary
+			case "resources":
+				data.TerraformManagedResourcesTable = summary
+			case "data":
+				data.TerraformDataSourcesTable = summary
+			case "modules":
+				data.TerraformModulesTable = summary
+			}
+		}
+		if docTableOpts.Explainer != nil {
+			docTableOpts.Explainer.Explain(tfdoc.Explanation{Region: "output", File: docOpts.TemplatePath, Notes: []string{fmt.Sprintf("rendered from template %s", name)}})
+			docTableOpts.Explainer.ExplainTemplateFields(t, templateFieldSources(docOpts, len(module.ManagedResources)))
+		}
+		// -render-timeout bounds only the rendering, not the loading and tables before it
+		ctx, cancel := context.WithTimeout(context.Background(), docOpts.Timeout)
+		defer cancel()
+		if docOpts.HeadingIds == "explicit" {
+			// Build the TOC from the rendered headings so it links to the IDs they will be given
+			var draft bytes.Buffer
+			data.MarkdownTOC = tfdoc.TocHeading
+			CheckErr(tfdoc.ExecuteTemplate(ctx, t, data, &draft, docOpts.MaxOutput), fmt.Sprintf("failed rendering template: %s", docOpts.TemplatePath))
+			tocEntries = tfdoc.ExplicitTocEntries(draft.Bytes(), 3)
+			data.TOCEntries = tocEntries
+			data.MarkdownTOC = strings.Join(tfdoc.FormatToc(tocEntries, docTableOpts), "\n")
+		}
+		if docTableOpts.Format == "confluence" {
+			data.MarkdownTOC = tfdoc.ConfluenceToc
+		}
+		var doc bytes.Buffer
+		CheckErr(tfdoc.Render(ctx, t, module, data, &doc, tfdoc.RenderOptions{MaxSize: docOpts.MaxOutput}), fmt.Sprintf("failed rendering template: %s", docOpts.TemplatePath))
+		if docOpts.InjectInto != "" {
+			CheckErr(tfdoc.CheckNoMarkers(docOpts.TemplatePath, doc.Bytes(), docOpts.InjectMarker), "the template conflicts with -inject-into")
+		}
+		if docOpts.HeadingIds == "explicit" {
+			out.Write(tfdoc.AddHeadingIDs(doc.Bytes()))
+		} else {
+			out.Write(doc.Bytes())
+		}
 	}
-	return MarkdownTable(headings, lengths, data)
-}
 
-func GetModulesTable(module *tfconfig.Module, baseUrl, modulePath string) string {
-	headings := []string{"Module Name", "Module Source", "Module Location"}
-	lengths := []string{"----", "--------", "------"}
-	data := [][]string{}
+	for _, l := range cliOpts.LocaleOutputs {
+		localeOpts := *cliOpts
+		localeOpts.TemplatePath = l.Template
+		localeTableOpts := tableOpts
+		localeTableOpts.Lang = l.Lang
+		localeUrl, localeModulePath := cliOpts.RepoUrl, cliOpts.ModulePath
+		if localeUrl == "" {
+			var err error
+			localeUrl, err = tfdoc.RelativeBaseUrl(filepath.Dir(l.Out), cliOpts.TfPath)
+			CheckErr(err, "")
+			localeModulePath = ""
+		}
+		var doc bytes.Buffer
+		renderDocument(&localeOpts, localeTableOpts, localeUrl, localeModulePath, &doc)
+		if note := tfdoc.CaveatsNote(skipped); note != "" && templateFormat(cliOpts.Format, l.Template) == "markdown" {
+			fmt.Fprintf(&doc, "\n%s\n", note)
+		}
+		CheckErr(writer.WriteFile(l.Out, doc.Bytes()), fmt.Sprintf("failed writing %s", l.Out))
+	}
 
-	var objs = make(map[string]TfTableObject) // Make a map of output objects
-	for _, item := range module.ModuleCalls {
-		tfpathbits := strings.Split(item.Pos.Filename, "/")
-		tffile := tfpathbits[len(tfpathbits)-1]
-		objs[item.Name] = TfTableObject{
-			Name:        item.Name,
-			Type:        item.Source,
-			Description: item.Version,
-			Location:    fmt.Sprintf("[%s: %d](%s/%s/%s#L%d)", tffile, item.Pos.Line, baseUrl, modulePath, tffile, item.Pos.Line),
+	if cliOpts.Action != "" && cliOpts.Action != "RenderTemplate" {
+		tableOpts.Explainer.Explain(tfdoc.Explanation{Region: "output", Notes: []string{fmt.Sprintf("the %s action", cliOpts.Action)}})
+	}
+	lintFindings := []tfdoc.LintFinding{}
+	readmeChanged := false
+	// collapsed wraps a table in a <details> section with -collapsible
+	collapsed := func(summary string, rows int, table string) string {
+		if !cliOpts.Collapsible {
+			return table
 		}
+		return tfdoc.CollapseTable(tfdoc.LocalHeadings(cliOpts.Lang, []string{summary})[0], rows, table)
 	}
-	for _, k := range getSortedKeys(objs) {
-		data = append(data, []string{objs[k].Name, objs[k].Type, objs[k].Location})
+	// actionHandlers write each action's output to out. Update, MigrateConfig and ValidateConfig don't need the
+	// module, so they are run before it's loaded.
+	actionHandlers := map[string]func(){
+		"VarsTable": func() {
+			fmt.Fprintln(&out, collapsed("Variables", len(module.Variables), checkTable(tfdoc.VarsTable(module, linkUrl, linkModulePath, tableOpts))))
+		},
+		"OutputsTable": func() {
+			fmt.Fprintln(&out, collapsed("Outputs", len(module.Outputs), checkTable(tfdoc.OutputsTable(module, linkUrl, linkModulePath, tableOpts))))
+		},
+		"ManagedResourcesTable": func() {
+			fmt.Fprintln(&out, collapsed("Resources", len(module.ManagedResources), checkTable(tfdoc.ManagedResourcesTable(module, linkUrl, linkModulePath, tableOpts))))
+		},
+		"DataSourcesTable": func() {
+			fmt.Fprintln(&out, collapsed("Data Sources", len(module.DataResources), checkTable(tfdoc.DataSourcesTable(module, linkUrl, linkModulePath, tableOpts))))
+		},
+		"ModulesTable": func() {
+			fmt.Fprintln(&out, collapsed("Modules", len(module.ModuleCalls), checkTable(tfdoc.ModulesTable(module, linkUrl, linkModulePath, tableOpts))))
+		},
+		"RequirementsTable": func() {
+			fmt.Fprintln(&out, collapsed("Requirements", tfdoc.RequirementsRows(module, experiments), checkTable(tfdoc.RequirementsTable(module, experiments, tableOpts))))
+		},
+		"RequiredProvidersTable": func() {
+			fmt.Fprintln(&out, collapsed("Providers", len(module.RequiredProviders), checkTable(tfdoc.RequiredProvidersTable(module, tableOpts))))
+		},
+		"ProvidersTable": func() {
+			configs, err := tfdoc.FindProviderConfigs(module, cliOpts.TfPath)
+			CheckErr(err, "failed reading provider configurations")
+			fmt.Fprintln(&out, collapsed("Provider Configurations", len(configs), checkTable(tfdoc.ProvidersTable(configs, linkUrl, linkModulePath, tableOpts))))
+		},
+		"Feed": func() {
+			entries, err := tfdoc.FindFeedEntries(cliOpts.TfPath, cliOpts.FeedSince, linkUrl, linkModulePath, tableOpts.URLRewrites)
+			CheckErr(err, fmt.Sprintf("failed comparing the modules with %s", cliOpts.FeedSince))
+			feed, err := tfdoc.Feed(entries, cliOpts.FeedSince, linkUrl, cliOpts.FeedFormat)
+			CheckErr(err, "failed writing the feed")
+			fmt.Fprintln(&out, feed)
+		},
+		"MarkdownDocument": func() {
+			fmt.Fprintln(&out, tfdoc.MarkdownDocument(module, linkUrl, linkModulePath, !cliOpts.NoPositions, tableOpts.URLRewrites))
+		},
+		"All": func() {
+			fmt.Fprintln(&out, checkTable(tfdoc.AllTables(module, experiments, linkUrl, linkModulePath, tableOpts)))
+		},
+		"InjectReadme": func() {
+			// The README is read and written back under its lock, so it isn't written as -output is below
+			CheckErr(tfdoc.InjectReadme(writer, cliOpts.Output, checkTable(tfdoc.AllTables(module, experiments, linkUrl, linkModulePath, tableOpts))), "failed injecting the tables")
+		},
+		"DiffReadme": func() {
+			diff, err := tfdoc.DiffReadme(cliOpts.Output, checkTable(tfdoc.AllTables(module, experiments, linkUrl, linkModulePath, tableOpts)))
+			CheckErr(err, "failed injecting the tables")
+			if diff != "" {
+				fmt.Fprintln(&out, diff)
+				readmeChanged = true
+			}
+		},
+		"RemovedTable": func() {
+			removed, err := tfdoc.FindRemovedBlocks(cliOpts.TfPath)
+			CheckErr(err, "failed reading removed blocks")
+			fmt.Fprintln(&out, tfdoc.RemovedTable(removed, linkUrl, linkModulePath, tableOpts))
+		},
+		"EnvMatrix": func() {
+			envs, err := tfdoc.LoadTfvarsFiles(cliOpts.TfPath, cliOpts.TfvarsGlob)
+			CheckErr(err, "")
+			table, findings := tfdoc.EnvMatrixTable(module, envs)
+			for _, finding := range findings {
+				stderr.Println(finding)
+			}
+			fmt.Fprintln(&out, table)
+		},
+		"Names": func() {
+			fmt.Fprint(&out, tfdoc.Names(module, cliOpts.Kind, cliOpts.RequiredOnly, cliOpts.Separator, tableOpts))
+			if cliOpts.Separator == "comma" {
+				fmt.Fprintln(&out)
+			}
+		},
+		"ImportScaffold": func() {
+			repetition, err := tfdoc.FindRepetition(cliOpts.TfPath)
+			CheckErr(err, "failed checking resources for count and for_each")
+			fmt.Fprintln(&out, tfdoc.ImportScaffold(module, repetition, cliOpts.ImportStyle))
+		},
+		"TagCoverage": func() {
+			fmt.Fprintln(&out, tfdoc.TagCoverageTable(tagged, linkUrl, linkModulePath, tableOpts))
+		},
+		"Json": func() {
+			doc, err := tfdoc.ModuleJson(limitModuleDoc(cliOpts, writer, tfdoc.BuildModuleDoc(module, skipped), ".json", tfdoc.ModuleJson))
+			CheckErr(err, "failed building the module JSON")
+			fmt.Fprintln(&out, doc)
+		},
+		"Yaml": func() {
+			doc, err := tfdoc.ModuleYaml(limitModuleDoc(cliOpts, writer, tfdoc.BuildModuleDoc(module, skipped), ".yaml", tfdoc.ModuleYaml))
+			CheckErr(err, "failed building the module YAML")
+			fmt.Fprint(&out, doc)
+		},
+		"Lint": func() {
+			lintFindings = tfdoc.Lint(module, cliOpts.LintRules)
+			for _, finding := range lintFindings {
+				stderr.Println(finding)
+			}
+		},
+		"Todos": func() {
+			for _, todo := range todos {
+				fmt.Fprintln(&out, todo)
+			}
+		},
+		"RenderTemplate": func() {
+			renderDocument(cliOpts, tableOpts, linkUrl, linkModulePath, &out)
+		},
+		"Adopt": func() {
+			contents := map[string]string{
+				"inputs":       checkTable(tfdoc.VarsTable(module, injectUrl, injectModulePath, tableOpts)),
+				"outputs":      checkTable(tfdoc.OutputsTable(module, injectUrl, injectModulePath, tableOpts)),
+				"requirements": checkTable(tfdoc.RequirementsTable(module, experiments, tableOpts)),
+				"resources":    checkTable(tfdoc.ManagedResourcesTable(module, injectUrl, injectModulePath, tableOpts)),
+			}
+			diff, err := tfdoc.AdoptSections(writer, cliOpts.InjectInto, cliOpts.InjectMarker, contents)
+			CheckErr(err, "failed adopting the README")
+			if cliOpts.DryRun && diff != "" {
+				fmt.Fprintln(&out, diff)
+			}
+		},
 	}
-	return MarkdownTable(headings, lengths, data)
-}
 
-func main() {
+	// Without an action only pages were requested, unless -compact-pr or -collection was
+	if cliOpts.Collection {
+		index, err := tfdoc.CollectionIndex(cliOpts.TfPath, linkUrl, linkModulePath, cliOpts.MetadataFile, tableOpts)
+		CheckErr(err, "failed rendering the collection index")
+		fmt.Fprintln(&out, index)
+	} else if cliOpts.CompactPR {
+		fmt.Fprintln(&out, checkTable(tfdoc.CompactPR(module, cliOpts.Action, linkUrl, linkModulePath, cliOpts.CompactBudget, tableOpts)))
+	} else if cliOpts.Action != "" {
+		handler, ok := actionHandlers[cliOpts.Action]
+		if !ok {
+			implemented := []string{"Update", "MigrateConfig", "ValidateConfig"}
+			for name := range actionHandlers {
+				implemented = append(implemented, name)
+			}
+			sort.Strings(implemented)
+			CheckErr(fmt.Errorf("Action %s is not implemented, the implemented actions are: %s", cliOpts.Action, implemented), "")
+		}
+		handler()
+		if table, ok := tfdoc.OverflowTables[cliOpts.Action]; ok && cliOpts.Format == "csv" && cliOpts.MaxItems > 0 {
+			limited := limitCsvTable(cliOpts, writer, table, strings.TrimSuffix(out.String(), "\n"))
+			out.Reset()
+			fmt.Fprintln(&out, limited)
+		}
+		// Names, ImportScaffold, Json, Yaml, Lint and Feed are for scripts, which the warnings on stderr already tell,
+		// and Adopt and InjectReadme write their output into the README. The note is markdown, so tables in
+		// other formats, like CSV for a spreadsheet, are left to the warnings too.
+		if note := tfdoc.CaveatsNote(skipped); note != "" && cliOpts.Format == "markdown" && !tfdoc.StringInSlice(cliOpts.Action, []string{"Names", "ImportScaffold", "Json", "Yaml", "Lint", "Adopt", "InjectReadme", "DiffReadme", "Feed"}) {
+			fmt.Fprintf(&out, "\n%s\n", note)
+		}
+	}
 
-	cliOpts := ParseCli()
+	CheckErr(tfdoc.CheckStrictMarkdown(tableOpts.MarkdownWarnings, cliOpts.StrictMarkdown), "")
 
-	module, diags := tfconfig.LoadModule(cliOpts.TfPath)
-	//baseUrl := GitLabBaseUrl(cliOpts.TfPath)
+	// DiffReadme and Adopt print their diff rather than writing -output, and InjectReadme has written it already
+	if docPath != "" && !tfdoc.StringInSlice(cliOpts.Action, diffActions) && cliOpts.Action != "InjectReadme" && (cliOpts.Action != "" || cliOpts.CompactPR || cliOpts.Collection) {
+		CheckErr(writer.WriteFile(docPath, out.Bytes()), fmt.Sprintf("failed writing %s", docPath))
+		out.Reset()
+	}
 
-	if diags.HasErrors() {
-		panic("Problem Loading Module: " + diags.Error())
+	if cliOpts.MetricsOut != "" {
+		metrics.AddModule(module, loadDuration, time.Since(renderStart), skipped)
+		CheckErr(metrics.Write(writer, cliOpts.MetricsOut), fmt.Sprintf("failed writing metrics: %s", cliOpts.MetricsOut))
 	}
 
-	if cliOpts.Action == "VarsTable" {
-		fmt.Println(GetVarsTable(module, cliOpts.RepoUrl, cliOpts.ModulePath))
-	} else if cliOpts.Action == "OutputsTable" {
-		fmt.Println(GetOutputsTable(module, cliOpts.RepoUrl, cliOpts.ModulePath))
-	} else if cliOpts.Action == "ManagedResourcesTable" {
-		fmt.Println(GetManagedResourcesTable(module, cliOpts.RepoUrl, cliOpts.ModulePath))
-	} else if cliOpts.Action == "RenderTemplate" {
+	if tableOpts.Explainer != nil {
+		CheckErr(tableOpts.Explainer.WriteReport(writer, cliOpts.Explain, cliOpts.ExplainFormat), "failed writing the explain report")
+	}
 
-		// Load the template
-		name := path.Base(cliOpts.TemplatePath)
-		t, err := template.New(name).Funcs(template.FuncMap{
-			"rawfile": func(filepath string) (string, error) {
-				parent := path.Dir(cliOpts.TemplatePath)
-				rawFilePath := parent + "/" + filepath
-				fileBytes, err := ioutil.ReadFile(rawFilePath)
-
-				return string(fileBytes), err
-			},
-		}).ParseFiles(cliOpts.TemplatePath)
-		CheckErr(err, fmt.Sprintf("Problem loading template: %s", cliOpts.TemplatePath))
-
-		readmeTemplateBytes, err := ioutil.ReadFile(cliOpts.TemplatePath)
-		CheckErr(err, "Failed to read template: %s")
-		toc, err := BuildMarkdownToc(readmeTemplateBytes, 3, 0)
+	writeOutput(cliOpts, writer, out.Bytes())
+	if cliOpts.DryRun {
+		return
+	}
 
-		data := TemplateData{
-			TerraformOutputsTable:          GetOutputsTable(module, cliOpts.RepoUrl, cliOpts.ModulePath),
-			TerraformVarsTable:             GetVarsTable(module, cliOpts.RepoUrl, cliOpts.ModulePath),
-			TerraformManagedResourcesTable: GetManagedResourcesTable(module, cliOpts.RepoUrl, cliOpts.ModulePath),
-			TerraformDataSourcesTable:      GetDataSourcesTable(module, cliOpts.RepoUrl, cliOpts.ModulePath),
-			TerraformModulesTable:          GetModulesTable(module, cliOpts.RepoUrl, cliOpts.ModulePath),
-			MarkdownTOC:                    strings.Join(toc, "\n"),
-			RepoBaseUrl:                    cliOpts.RepoUrl,
+	CheckErr(tfdoc.CheckTagCoverage(tagged, cliOpts.MinTagCoverage), "")
+	if len(lintFindings) > 0 {
+		stderr.Printf("found %d problems breaking the lint rules", len(lintFindings))
+		runAtExit()
+		os.Exit(exitLintFindings)
+	}
+	if readmeChanged {
+		// The diff says what's out of date, so there's nothing more to print
+		runAtExit()
+		os.Exit(exitFailed)
+	}
+	if cliOpts.FailOnTodos && len(todos) > 0 {
+		CheckErr(fmt.Errorf("found %d comments marked %s", len(todos), strings.Join(cliOpts.TodoMarkers, "/")), "")
+	}
+
+	// Only runs that passed their checks are cached, so a cached run would pass them too
+	if cache != nil {
+		// The metrics are of this run, and are written again by the next
+		written := []string{}
+		for _, file := range writer.Written() {
+			if file != cliOpts.MetricsOut {
+				written = append(written, file)
+			}
+		}
+		inputs, err := renderedInputs(tableOpts.Included, written)
+		if err == nil {
+			err = cache.Store(out.Bytes(), written, inputs)
+		}
+		if err != nil {
+			stderr.Printf("failed caching the output in %s: %v", cliOpts.CacheDir, err)
 		}
-		CheckErr(t.Execute(os.Stdout, data), fmt.Sprintf("failed rendering template: %s", cliOpts.TemplatePath))
+	}
+}
 
-	} else {
-		CheckErr(errors.New(fmt.Sprintf("Action %s not implented yet", cliOpts.Action)), "")
+// newFileWriter returns a writer allowed to write in the module and the outputs cliOpts name
+func newFileWriter(cliOpts *CliOpts) *tfdoc.FileWriter {
+	writer := &tfdoc.FileWriter{DryRun: cliOpts.DryRun, Allowed: []string{cliOpts.TfPath}}
+	for _, explicit := range []string{cliOpts.OutDir, cliOpts.Output, cliOpts.MetricsOut, cliOpts.InjectInto, cliOpts.Explain} {
+		if explicit != "" && explicit != "-" {
+			writer.Allowed = append(writer.Allowed, explicit)
+		}
+	}
+	for _, l := range cliOpts.LocaleOutputs {
+		writer.Allowed = append(writer.Allowed, l.Out)
+	}
+	return writer
+}
 
+// overflowDirs are where -overflow-mode split writes its parts, -out-dir or else beside the module, and
+// where the manifest links to them from, the -output file's directory or else the working directory
+func overflowDirs(cliOpts *CliOpts) (string, string) {
+	dir, linkDir := cliOpts.TfPath, "."
+	if cliOpts.OutDir != "" {
+		dir = cliOpts.OutDir
+	}
+	if cliOpts.Output != "" && cliOpts.Output != "-" {
+		linkDir = filepath.Dir(cliOpts.Output)
 	}
+	return dir, linkDir
+}
+
+// limitModuleDoc applies -max-items to the Json and Yaml actions' document, truncating its lists or
+// writing them to parts and giving the manifest that names them
+func limitModuleDoc(cliOpts *CliOpts, writer *tfdoc.FileWriter, doc tfdoc.ModuleDoc, ext string, marshal func(tfdoc.ModuleDoc) (string, error)) tfdoc.ModuleDoc {
+	if cliOpts.MaxItems == 0 {
+		return doc
+	}
+	if cliOpts.OverflowMode == "truncate" {
+		return tfdoc.TruncateModuleDoc(doc, cliOpts.MaxItems)
+	}
+	dir, linkDir := overflowDirs(cliOpts)
+	manifest, err := tfdoc.WriteModuleDocParts(writer, doc, cliOpts.MaxItems, dir, linkDir, ext, cliOpts.Prune, marshal)
+	CheckErr(err, "failed writing the module's parts")
+	return manifest
+}
 
+// limitCsvTable applies -max-items to a table action's CSV, truncating it, which is reported on stderr as
+// CSV has nowhere to say so, or writing it to parts and giving the manifest that names them
+func limitCsvTable(cliOpts *CliOpts, writer *tfdoc.FileWriter, name, table string) string {
+	if cliOpts.OverflowMode == "truncate" {
+		truncated, total, err := tfdoc.TruncateCsvTable(table, cliOpts.MaxItems)
+		CheckErr(err, "failed reading the table's CSV")
+		if total > cliOpts.MaxItems {
+			stderr.Printf("the %s table has %d rows, -max-items left out %d", name, total, total-cliOpts.MaxItems)
+		}
+		return truncated
+	}
+	dir, linkDir := overflowDirs(cliOpts)
+	manifest, err := tfdoc.WriteCsvTableParts(writer, table, name, cliOpts.MaxItems, dir, linkDir, cliOpts.Prune)
+	CheckErr(err, fmt.Sprintf("failed writing the %s table's parts", name))
+	return manifest
+}
+
+// templateFormat is the format of the tables in a document rendered from templatePath: AsciiDoc, RST and org
+// templates get tables in their own format unless another -format than markdown was chosen
+func templateFormat(format, templatePath string) string {
+	if format != "markdown" {
+		return format
+	}
+	switch path.Ext(templatePath) {
+	case ".adoc":
+		return "asciidoc"
+	case ".rst":
+		return "rst"
+	case ".org":
+		return "org"
+	}
+	return format
+}
+
+// pageOptions are where the pages cliOpts asks for beside the document go, and how they link to the module
+func pageOptions(cliOpts *CliOpts) tfdoc.PageOptions {
+	return tfdoc.PageOptions{
+		TfPath:     cliOpts.TfPath,
+		OutDir:     cliOpts.OutDir,
+		RepoUrl:    cliOpts.RepoUrl,
+		ModulePath: cliOpts.ModulePath,
+		HeadingIds: cliOpts.HeadingIds,
+		Prune:      cliOpts.Prune,
+	}
+}
+
+// writeOutput sends the run's output to stdout or the clipboard and reports the files written, or in a dry
+// run prints the manifest instead, after the diff of a diffActions action, exiting 1 if anything would change
+func writeOutput(cliOpts *CliOpts, writer *tfdoc.FileWriter, out []byte) {
+	if cliOpts.DryRun {
+		manifest, err := writer.Manifest(cliOpts.DryRunFormat)
+		CheckErr(err, "failed building the dry run manifest")
+		if tfdoc.StringInSlice(cliOpts.Action, diffActions) {
+			_, err := os.Stdout.Write(out)
+			CheckErr(err, "failed writing output")
+		}
+		fmt.Println(manifest)
+		if writer.Changed() {
+			os.Exit(exitFailed)
+		}
+		return
+	}
+
+	if cliOpts.Out == "clipboard" {
+		CheckErr(CopyToClipboard(out), "failed copying output to the clipboard")
+	} else {
+		_, err := os.Stdout.Write(out)
+		CheckErr(err, "failed writing output")
+	}
+	for _, c := range writer.Changes {
+		stderr.Println(c.Action, c.Path)
+	}
+	runAtExit()
 }
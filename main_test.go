@@ -0,0 +1,882 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+)
+
+// TestMain runs tf2doc itself instead of the tests when runTf2doc asks it to, so tests can check what a
+// whole run prints and how it exits
+func TestMain(m *testing.M) {
+	if os.Getenv("TF2DOC_TEST_MAIN") == "1" {
+		os.Args = append([]string{"tf2doc"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runTf2doc runs tf2doc with args in dir, returning its standard output and error and its exit code
+func runTf2doc(t *testing.T, dir string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TF2DOC_TEST_MAIN=1")
+	// A pipe, not a terminal
+	cmd.Stdin = strings.NewReader("")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+// writeFiles writes each file's content under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRepoUrlRewrites(t *testing.T) {
+	tests := []struct {
+		name     string
+		rewrites []string
+		want     string
+	}{
+		{"no rewrites", nil, "(https://git.corp/team/stack/main.tf#L1)"},
+		{"a capture group", []string{"https://git.corp/(.*)=https://github.com/corp-mirror/$1"}, "(https://github.com/corp-mirror/team/stack/main.tf#L1)"},
+		{
+			"the first matching rule",
+			[]string{"git.corp/team/(.*)=github.com/team-mirror/$1", "https://git.corp/(.*)=https://github.com/corp-mirror/$1"},
+			"(https://github.com/team-mirror/stack/main.tf#L1)",
+		},
+		{"a rule that doesn't match", []string{"https://gitlab.com/(.*)=https://github.com/$1"}, "(https://git.corp/team/stack/main.tf#L1)"},
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": `variable "name" {}`})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := []string{"-path", ".", "-action", "VarsTable", "-repoUrl", "https://git.corp/team/stack"}
+			for _, r := range test.rewrites {
+				args = append(args, "-repo-url-rewrite", r)
+			}
+			stdout, stderr, code := runTf2doc(t, dir, args...)
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			if !strings.Contains(stdout, test.want) {
+				t.Errorf("the table doesn't link to %s:\n%s", test.want, stdout)
+			}
+		})
+	}
+}
+
+func TestInjectingATemplateWithMarkersFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     int
+	}{
+		{"a template without markers", "# Module\n\n{{ .TerraformVarsTable }}\n", 0},
+		{"a template with markers", "# Module\n\n<!-- tf2doc:inputs:begin -->\n{{ .TerraformVarsTable }}\n<!-- tf2doc:inputs:end -->\n", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"main.tf":         `variable "name" {}`,
+				"README.template": test.template,
+				"README.md":       "<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:inputs:end -->\n",
+			})
+			_, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-path", ".", "-action", "RenderTemplate",
+				"-templatePath", "README.template", "-inject-into", "README.md", "-inject-region", "inputs=VarsTable")
+			if code != test.want {
+				t.Errorf("exited %d, want %d, with stderr:\n%s", code, test.want, stderr)
+			}
+			if test.want != 0 && !strings.Contains(stderr, "the template conflicts with -inject-into") {
+				t.Errorf("stderr is %q", stderr)
+			}
+		})
+	}
+}
+
+// actionsFixture is a module with something for every action to document
+var actionsFixture = map[string]string{
+	"main.tf": `# TODO: add an output for the bucket name
+variable "name" {
+  description = "The name of the bucket"
+  type        = string
+}
+
+output "arn" {
+  description = "The bucket's ARN"
+  value       = aws_s3_bucket.this.arn
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+  tags   = { Name = var.name }
+}
+
+data "aws_region" "current" {}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+}
+
+removed {
+  from = aws_s3_bucket.old
+}
+`,
+	"prod.tfvars":         `name = "prod"`,
+	"README.template":     "{{ .TerraformVarsTable }}",
+	"README.md":           "# Module\n\n## Inputs\n\nold\n\n<!-- BEGIN TF2DOC -->\n<!-- END TF2DOC -->\n",
+	".tf2doc.yaml":        "sort: name\n",
+	".terraform-docs.yml": "settings:\n  indent: 2\n",
+}
+
+func TestEveryActionIsImplemented(t *testing.T) {
+	// What each action needs besides the module, what it prints on stdout or stderr and how it exits
+	tests := map[string]struct {
+		args []string
+		want string
+		code int
+	}{
+		"VarsTable":              {nil, "| name | string |", 0},
+		"OutputsTable":           {nil, "| arn |", 0},
+		"ManagedResourcesTable":  {nil, "| this | aws_s3_bucket |", 0},
+		"DataSourcesTable":       {nil, "| current | aws_region |", 0},
+		"ModulesTable":           {nil, "| vpc | terraform-aws-modules/vpc/aws |", 0},
+		"RequirementsTable":      {nil, "aws", 0},
+		"RemovedTable":           {nil, "`aws_s3_bucket.old`", 0},
+		"EnvMatrix":              {nil, "| name | `\"prod\"` |", 0},
+		"Todos":                  {nil, "main.tf:1: TODO: add an output for the bucket name", 0},
+		"RenderTemplate":         {[]string{"-templatePath", "README.template"}, "| name | string |", 0},
+		"Names":                  {nil, "name", 0},
+		"MigrateConfig":          {nil, "# Migrated from .terraform-docs.yml", 0},
+		"ImportScaffold":         {nil, "aws_s3_bucket.this", 0},
+		"TagCoverage":            {nil, "1 of 1 taggable resources set tags", 0},
+		"Json":                   {nil, `"name"`, 0},
+		"Yaml":                   {nil, "name: name", 0},
+		"Adopt":                  {[]string{"-inject-into", "README.md", "-dry-run"}, "+<!-- tf2doc:inputs:begin -->", 1},
+		"ValidateConfig":         {nil, ".tf2doc.yaml is valid", 0},
+		"Lint":                   {nil, "", 0},
+		"All":                    {nil, "## Variables", 0},
+		"Feed":                   {[]string{"-feed-since", "HEAD"}, "Module interface changes since HEAD", 0},
+		"MarkdownDocument":       {nil, "aws_s3_bucket", 0},
+		"RequiredProvidersTable": {nil, "aws", 0},
+		"InjectReadme":           {[]string{"-output", "README.md"}, "update README.md", 0},
+		"ProvidersTable":         {nil, "aws", 0},
+		"DiffReadme":             {[]string{"-output", "README.md"}, "+## Variables", 1},
+	}
+	for _, action := range ValidActions {
+		t.Run(action, func(t *testing.T) {
+			if action == "Update" {
+				t.Skip("Update downloads the latest release")
+			}
+			test, ok := tests[action]
+			if !ok {
+				t.Fatalf("there is no test of %s", action)
+			}
+			dir := t.TempDir()
+			writeFiles(t, dir, actionsFixture)
+			if action == "Feed" {
+				if _, err := exec.LookPath("git"); err != nil {
+					t.Skip("Feed needs git")
+				}
+				for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"}} {
+					cmd := exec.Command("git", args...)
+					cmd.Dir = dir
+					if b, err := cmd.CombinedOutput(); err != nil {
+						t.Fatalf("git %s: %v\n%s", args, err, b)
+					}
+				}
+			}
+			args := append([]string{"-no-detect-repo-url", "-no-config", "-path", ".", "-action", action}, test.args...)
+			stdout, stderr, code := runTf2doc(t, dir, args...)
+			if code != test.code {
+				t.Errorf("exited %d, want %d, with stderr:\n%s", code, test.code, stderr)
+			}
+			if strings.Contains(stderr, "not implemented") {
+				t.Errorf("is not implemented: %s", stderr)
+			}
+			if !strings.Contains(stdout+stderr, test.want) {
+				t.Errorf("printed:\n%s%s\nwant it to contain %q", stdout, stderr, test.want)
+			}
+		})
+	}
+}
+
+func TestAllowErrors(t *testing.T) {
+	fixture, err := filepath.Abs("tfdoc/testdata/partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := filepath.Join(t.TempDir(), "metrics.json")
+
+	_, stderr, code := runTf2doc(t, ".", "-no-detect-repo-url", "-path", fixture, "-action", "VarsTable")
+	if code == 0 || !strings.Contains(stderr, "Missing newline after argument") {
+		t.Errorf("without -allow-errors exited %d with stderr:\n%s", code, stderr)
+	}
+
+	stdout, stderr, code := runTf2doc(t, ".", "-no-detect-repo-url", "-path", fixture, "-action", "VarsTable", "-allow-errors", "-metrics-out", metrics)
+	if code != 0 {
+		t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+	}
+	for _, want := range []string{"| name | string |", "## Documentation caveats", "* `functions.tf`"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("the output doesn't contain %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "region") {
+		t.Errorf("the output documents functions.tf:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "leaving functions.tf out of the docs") {
+		t.Errorf("stderr doesn't name the skipped file:\n%s", stderr)
+	}
+	b, err := ioutil.ReadFile(metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m RunMetrics
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Modules) != 1 || len(m.Modules[0].SkippedFiles) != 1 || m.Modules[0].SkippedFiles[0].Filename != "functions.tf" {
+		t.Errorf("the metrics don't list the skipped file:\n%s", b)
+	}
+}
+
+func TestAllowErrorsCaveatsInOtherFormats(t *testing.T) {
+	fixture, err := filepath.Abs("tfdoc/testdata/partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"json", "yaml", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			stdout, stderr, code := runTf2doc(t, ".", "-no-detect-repo-url", "-path", fixture, "-action", "VarsTable", "-format", format, "-allow-errors")
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			if strings.Contains(stdout, "Documentation caveats") {
+				t.Errorf("the markdown caveats note was added to the %s output:\n%s", format, stdout)
+			}
+			if !strings.Contains(stderr, "leaving functions.tf out of the docs") {
+				t.Errorf("stderr doesn't name the skipped file:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestDataSourcesTableAction(t *testing.T) {
+	stdout, stderr, code := runTf2doc(t, ".", "-no-detect-repo-url", "-path", "testdata/data", "-action", "DataSourcesTable")
+	if code != 0 {
+		t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+	}
+	want := `| Resource Name | Resource Type | Code Position |
+| ---- | -------- | ------ |
+| assume | aws_iam_policy_document | [main.tf: 3](main.tf#L3) |
+| current | aws_region | [main.tf: 1](main.tf#L1) |
+`
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+}
+
+func TestModulesTableAction(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			"the default columns",
+			nil,
+			`| Module Name | Module Source | Module Location |
+| ---- | -------- | ------ |
+| buckets | ./modules/buckets | [main.tf: 8](main.tf#L8) |
+| vpc | terraform-aws-modules/vpc/aws | [main.tf: 1](main.tf#L1) |
+`,
+		},
+		{
+			"with versions",
+			[]string{"-columns", "modules=name,source,version"},
+			`| Module Name | Module Source | Module Version |
+| ---- | -------- | -------- |
+| buckets | ./modules/buckets |  |
+| vpc | terraform-aws-modules/vpc/aws | 5.1.2 |
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := append([]string{"-no-detect-repo-url", "-path", "testdata/modules", "-action", "ModulesTable"}, test.args...)
+			stdout, stderr, code := runTf2doc(t, ".", args...)
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			if stdout != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", stdout, test.want)
+			}
+		})
+	}
+}
+
+func TestOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": `data "aws_region" "current" {}`})
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-path", ".", "-action", "DataSourcesTable", "-output", "docs/README.md")
+	if code != 0 {
+		t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("printed %q, want nothing on stdout", stdout)
+	}
+	got, err := ioutil.ReadFile(filepath.Join(dir, "docs", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "| current | aws_region | [main.tf: 1](../main.tf#L1) |"; !strings.Contains(string(got), want) {
+		t.Errorf("wrote:\n%s\nwant links relative to it, like %q", got, want)
+	}
+
+	_, stderr, code = runTf2doc(t, dir, "-no-detect-repo-url", "-path", ".", "-action", "DataSourcesTable", "-output", "docs")
+	if code != 1 || !strings.Contains(stderr, "can't write -output docs") {
+		t.Errorf("exited %d with stderr:\n%s\nwant 1 for an -output that is a directory", code, stderr)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   string
+		code   int
+	}{
+		{"valid", "sort: name\nstrip-prefix:\n  - aws_\n  - google_\n", nil, "tf2doc.yaml is valid", 0},
+		{"misspelled key", "sort: name\nsortt: name\n", nil, "tf2doc.yaml:2:1: sortt is not a setting, did you mean sort?", 1},
+		{"unknown key with -config-lax", "sort: name\nsortt: name\n", []string{"-config-lax"}, "sortt is not a setting, did you mean sort? (ignored by -config-lax)", 0},
+		{"list for a single value", "sort:\n  - name\n", nil, "tf2doc.yaml:2:3: sort takes a single value", 1},
+		{"bad bool", "dry-run: maybe\n", nil, "tf2doc.yaml:1:10: dry-run takes true or false, not \"maybe\"", 1},
+		{"bad int", "group-min: lots\n", nil, "group-min takes a whole number, not \"lots\"", 1},
+		{"config in a config", "config: other.yaml\n", nil, "config is not a setting, it can only be given on the command line", 1},
+		{"not a mapping", "- sort\n", nil, "tf2doc.yaml:1:1: the config must be a mapping of settings to values", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{".tf2doc.yaml": test.config})
+			args := append([]string{"-path", ".", "-action", "ValidateConfig"}, test.args...)
+			_, stderr, code := runTf2doc(t, dir, args...)
+			if code != test.code {
+				t.Errorf("exited %d, want %d, with stderr:\n%s", code, test.code, stderr)
+			}
+			if !strings.Contains(stderr, test.want) {
+				t.Errorf("printed:\n%s\nwant it to contain %q", stderr, test.want)
+			}
+		})
+	}
+}
+
+func TestBadUsage(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": "variable \"name\" {}\n", "broken.tf": "variable \"oops\" {\n"})
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no module", []string{"-action", "VarsTable"}, "no module given: set -path"},
+		{"missing path", []string{"-path", "no-such-dir", "-action", "VarsTable"}, "-path no-such-dir: stat no-such-dir"},
+		{"path is a file", []string{"-path", "main.tf", "-action", "VarsTable"}, "-path main.tf is not a directory"},
+		{"no action", []string{"-path", "."}, "nothing to do: set -action"},
+		{"unknown action", []string{"-path", ".", "-action", "Nope"}, "action Nope is not one of"},
+		{"module errors", []string{"-path", ".", "-action", "VarsTable"}, "Use -allow-errors to leave out the files with errors"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, stderr, code := runTf2doc(t, dir, test.args...)
+			if code != 1 {
+				t.Errorf("exited %d, want 1", code)
+			}
+			if !strings.Contains(stderr, test.want) || strings.Contains(stderr, "goroutine 1 [") {
+				t.Errorf("printed:\n%s\nwant %q without a stack trace", stderr, test.want)
+			}
+		})
+	}
+}
+
+func TestAllAction(t *testing.T) {
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "all", "All.golden.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		path     string
+		files    map[string]string
+		headings []string
+		want     string
+	}{
+		{"a module with every table", "testdata/all", nil, []string{"Requirements", "Variables", "Outputs", "Resources", "Data Sources", "Modules"}, string(golden)},
+		{"a module with only variables", "", map[string]string{"main.tf": `variable "name" {}`}, []string{"Variables"}, ""},
+		{"an empty module", "", map[string]string{"main.tf": ""}, []string{}, "\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, path := ".", test.path
+			if test.files != nil {
+				dir, path = t.TempDir(), "."
+				writeFiles(t, dir, test.files)
+			}
+			stdout, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-no-config", "-path", path, "-action", "All")
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			if test.want != "" && stdout != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", stdout, test.want)
+			}
+			headings := []string{}
+			lines := strings.Split(stdout, "\n")
+			for i, line := range lines {
+				if !strings.HasPrefix(line, "## ") {
+					continue
+				}
+				headings = append(headings, strings.TrimPrefix(line, "## "))
+				// Each heading is followed by a blank line and its table, so the document renders as markdown
+				if i+2 >= len(lines) || lines[i+1] != "" || !strings.HasPrefix(lines[i+2], "| ") {
+					t.Errorf("the %s heading isn't followed by a table", line)
+				}
+			}
+			if !reflect.DeepEqual(headings, test.headings) {
+				t.Errorf("got headings %q, want %q", headings, test.headings)
+			}
+		})
+	}
+}
+
+// tableNames are the names in the first column of a markdown table
+func tableNames(table string) []string {
+	names := []string{}
+	for _, line := range strings.Split(table, "\n")[2:] {
+		if cells := strings.Split(line, "|"); len(cells) > 2 {
+			names = append(names, strings.TrimSpace(cells[1]))
+		}
+	}
+	return names
+}
+
+func TestModuleConfigFile(t *testing.T) {
+	module := "variable \"zone\" {}\nvariable \"name\" {}\n"
+	tests := []struct {
+		name  string
+		files map[string]string
+		args  []string
+		names []string
+		link  string
+	}{
+		{"no config file", map[string]string{}, nil, []string{"name", "zone"}, "(main.tf#L2)"},
+		{"a .tf2doc.yml", map[string]string{".tf2doc.yml": "sort: position\nrepoUrl: https://git.example.com/stack\n"}, nil, []string{"zone", "name"}, "(https://git.example.com/stack/main.tf#L2)"},
+		{"a .tf2doc.yaml", map[string]string{".tf2doc.yaml": "sort: position\n"}, nil, []string{"zone", "name"}, "(main.tf#L2)"},
+		{".tf2doc.yml before .tf2doc.yaml", map[string]string{".tf2doc.yml": "sort: position\n", ".tf2doc.yaml": "sort: name\n"}, nil, []string{"zone", "name"}, "(main.tf#L2)"},
+		{
+			"flags override the file",
+			map[string]string{".tf2doc.yml": "sort: position\nrepoUrl: https://git.example.com/stack\n"},
+			[]string{"-sort", "name", "-repoUrl", "https://github.com/acme/stack"},
+			[]string{"name", "zone"},
+			"(https://github.com/acme/stack/main.tf#L2)",
+		},
+		{
+			"a flag overrides one setting",
+			map[string]string{".tf2doc.yml": "sort: position\nrepoUrl: https://git.example.com/stack\n"},
+			[]string{"-sort", "name"},
+			[]string{"name", "zone"},
+			"(https://git.example.com/stack/main.tf#L2)",
+		},
+		{"-no-config", map[string]string{".tf2doc.yml": "sort: position\n"}, []string{"-no-config"}, []string{"name", "zone"}, "(main.tf#L2)"},
+		{"-config", map[string]string{".tf2doc.yml": "sort: name\n", "ci.yaml": "sort: position\n"}, []string{"-config", "ci.yaml"}, []string{"zone", "name"}, "(main.tf#L2)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+			writeFiles(t, dir, map[string]string{"main.tf": module})
+			args := append([]string{"-no-detect-repo-url", "-path", ".", "-action", "VarsTable"}, test.args...)
+			stdout, stderr, code := runTf2doc(t, dir, args...)
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			if names := tableNames(stdout); !reflect.DeepEqual(names, test.names) {
+				t.Errorf("got rows %q, want %q", names, test.names)
+			}
+			if !strings.Contains(stdout, test.link) {
+				t.Errorf("the table doesn't link to %s:\n%s", test.link, stdout)
+			}
+		})
+	}
+}
+
+func TestBadModuleConfigFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"an unknown setting", "sortt: position\n", "sortt is not a setting, did you mean sort?"},
+		{"a value of the wrong type", "large-threshold: lots\n", `large-threshold takes a whole number, not "lots"`},
+		{"a file that isn't YAML", "sort: [position\n", "bad -config"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"main.tf": `variable "name" {}`, ".tf2doc.yml": test.config})
+			_, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-path", ".", "-action", "VarsTable")
+			if code != 1 {
+				t.Errorf("exited %d, want 1", code)
+			}
+			if !strings.Contains(stderr, test.want) || !strings.Contains(stderr, ".tf2doc.yml") {
+				t.Errorf("stderr is %q, want it to name .tf2doc.yml and contain %q", stderr, test.want)
+			}
+		})
+	}
+}
+
+func TestEnvironmentVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"the module and action", map[string]string{"TF2DOC_PATH": "stack", "TF2DOC_ACTION": "VarsTable"}, nil, "| name |"},
+		{"a flag wins over a variable", map[string]string{"TF2DOC_PATH": "missing", "TF2DOC_ACTION": "VarsTable"}, []string{"-path", "stack", "-action", "OutputsTable"}, "| id |"},
+		{"a template", map[string]string{"TF2DOC_PATH": "stack", "TF2DOC_ACTION": "RenderTemplate", "TF2DOC_TEMPLATE_PATH": "stack/README.template"}, nil, "Inputs:\n\n| Variable |"},
+		{
+			"the repository URL and module path",
+			map[string]string{"TF2DOC_PATH": "stack", "TF2DOC_ACTION": "VarsTable", "TF2DOC_REPO_URL": "https://git.example.com/infra", "TF2DOC_MODULE_PATH": "modules/stack"},
+			nil,
+			"(https://git.example.com/infra/modules/stack/main.tf#L1)",
+		},
+		{
+			"a variable wins over the config file",
+			map[string]string{"TF2DOC_ACTION": "VarsTable", "TF2DOC_REPO_URL": "https://git.example.com/infra"},
+			[]string{"-path", "configured"},
+			"(https://git.example.com/infra/main.tf#L1)",
+		},
+		{
+			"a flag wins over both",
+			map[string]string{"TF2DOC_ACTION": "VarsTable", "TF2DOC_REPO_URL": "https://git.example.com/infra"},
+			[]string{"-path", "configured", "-repoUrl", "https://github.com/acme/stack"},
+			"(https://github.com/acme/stack/main.tf#L1)",
+		},
+	}
+	dir := t.TempDir()
+	for _, sub := range []string{"stack", "configured"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFiles(t, dir, map[string]string{
+		"stack/main.tf":          "variable \"name\" {}\noutput \"id\" { value = 1 }\n",
+		"stack/README.template":  "Inputs:\n\n{{ .TerraformVarsTable }}",
+		"configured/main.tf":     "variable \"name\" {}\n",
+		"configured/.tf2doc.yml": "repoUrl: https://git.example.com/configured\n",
+	})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			stdout, stderr, code := runTf2doc(t, dir, append([]string{"-no-detect-repo-url"}, test.args...)...)
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			if !strings.Contains(stdout, test.want) {
+				t.Errorf("got:\n%s\nwant it to contain %q", stdout, test.want)
+			}
+		})
+	}
+}
+
+func TestCollapsible(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf":         "variable \"name\" {}\nvariable \"tags\" {}\nmodule \"vpc\" {\n  source = \"./vpc\"\n}\n",
+		"README.template": "{{ .TerraformVarsTable }}\n\n{{ .TerraformRequirementsTable }}",
+	})
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"a table action", []string{"-action", "VarsTable"}, []string{"<details><summary>Variables (2)</summary>\n\n| Variable |", "|\n\n</details>"}},
+		{"requirements of module calls", []string{"-action", "RequirementsTable"}, []string{"<details><summary>Requirements (1)</summary>"}},
+		{"headings in another language", []string{"-action", "VarsTable", "-lang", "ja"}, []string{"<summary>変数 (2)</summary>"}},
+		{
+			"a template",
+			[]string{"-action", "RenderTemplate", "-templatePath", "README.template"},
+			[]string{"<details><summary>Variables (2)</summary>", "<details><summary>Requirements (1)</summary>"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := append([]string{"-no-detect-repo-url", "-no-config", "-path", ".", "-collapsible"}, test.args...)
+			stdout, stderr, code := runTf2doc(t, dir, args...)
+			if code != 0 {
+				t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("got:\n%s\nwant it to contain %q", stdout, want)
+				}
+			}
+		})
+	}
+
+	_, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-no-config", "-path", ".", "-collapsible", "-action", "VarsTable", "-format", "csv")
+	if code != 1 || !strings.Contains(stderr, "-collapsible wraps tables in HTML <details> sections, so it can't use -format csv") {
+		t.Errorf("with -format csv exited %d with stderr:\n%s", code, stderr)
+	}
+}
+
+func TestWithLint(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf":         "variable \"BucketName\" {\n  description = \"The bucket\"\n}\noutput \"id\" {\n  value = 1\n}\n",
+		"README.template": "Score: {{ .DocScore }}%, {{ .LintSummary }}\n{{ range .LintFindings }}{{ .File }}:{{ .Line }} {{ .Rule }}\n{{ end }}",
+	})
+	stdout, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-no-config", "-path", ".", "-action", "RenderTemplate", "-templatePath", "README.template", "-with-lint", "-lint-preset", "hashicorp-style")
+	if code != 0 {
+		t.Fatalf("exited %d with stderr:\n%s", code, stderr)
+	}
+	if want := "Score: 50%, 2 warnings\nmain.tf:1 var-pattern\nmain.tf:4 description\n"; stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+
+	_, stderr, code = runTf2doc(t, dir, "-no-detect-repo-url", "-no-config", "-path", ".", "-action", "VarsTable", "-with-lint")
+	if code != 1 || !strings.Contains(stderr, "-with-lint gives templates the lint results") {
+		t.Errorf("without a template exited %d with stderr:\n%s", code, stderr)
+	}
+}
+
+func TestLintExitCode(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": "variable \"name\" {}\nvariable \"legacy_name\" {}\n"})
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{
+		{"findings", nil, `variable "name" in main.tf:1 has no description`, 2},
+		{"ignored names", []string{"-lint-ignore", "name,legacy_*"}, "", 0},
+		{"a bad pattern", []string{"-lint-ignore", "legacy_["}, "bad -lint-ignore", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := append([]string{"-no-detect-repo-url", "-no-config", "-path", ".", "-action", "Lint"}, test.args...)
+			_, stderr, code := runTf2doc(t, dir, args...)
+			if code != test.code {
+				t.Errorf("exited %d, want %d, with stderr:\n%s", code, test.code, stderr)
+			}
+			if !strings.Contains(stderr, test.want) {
+				t.Errorf("printed:\n%s\nwant it to contain %q", stderr, test.want)
+			}
+		})
+	}
+}
+
+func TestMaxItems(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": "variable \"a\" {}\nvariable \"b\" {}\nvariable \"c\" {}\n"})
+	tests := []struct {
+		name   string
+		args   []string
+		want   string
+		stderr string
+		code   int
+	}{
+		{"truncated json", []string{"-action", "Json"}, `"omitted": {`, "", 0},
+		{"split json", []string{"-action", "Json", "-overflow-mode", "split"}, `"MODULE_2.json"`, "", 0},
+		{"truncated csv", []string{"-action", "VarsTable", "-format", "csv"}, "", "the vars table has 3 rows, -max-items left out 1", 0},
+		{"split csv", []string{"-action", "VarsTable", "-format", "csv", "-overflow-mode", "split"}, "VARS_2.csv,1\nTotal,3", "", 0},
+		{"a markdown table", []string{"-action", "VarsTable"}, "", "-max-items works with -action Json or Yaml", 1},
+		{"a bad mode", []string{"-action", "Json", "-overflow-mode", "drop"}, "", "overflow-mode drop is not one of", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := append([]string{"-no-detect-repo-url", "-no-config", "-path", ".", "-max-items", "2"}, test.args...)
+			stdout, stderr, code := runTf2doc(t, dir, args...)
+			if code != test.code {
+				t.Fatalf("exited %d, want %d, with stderr:\n%s", code, test.code, stderr)
+			}
+			if !strings.Contains(stdout, test.want) {
+				t.Errorf("got:\n%s\nwant it to contain %q", stdout, test.want)
+			}
+			if !strings.Contains(stderr, test.stderr) {
+				t.Errorf("printed:\n%s\nwant it to contain %q", stderr, test.stderr)
+			}
+		})
+	}
+}
+
+func TestDiffReadme(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf":   "variable \"name\" {}\n",
+		"README.md": "# Module\n\n<!-- BEGIN TF2DOC -->\n<!-- END TF2DOC -->\n",
+	})
+	args := []string{"-no-detect-repo-url", "-no-config", "-path", ".", "-output", "README.md"}
+	stdout, stderr, code := runTf2doc(t, dir, append(args, "-action", "DiffReadme")...)
+	if code != 1 || !strings.Contains(stdout, "+| name |") {
+		t.Errorf("an out of date README exited %d with stdout:\n%s\nstderr:\n%s", code, stdout, stderr)
+	}
+	if _, stderr, code := runTf2doc(t, dir, append(args, "-action", "InjectReadme")...); code != 0 {
+		t.Fatalf("InjectReadme exited %d with stderr:\n%s", code, stderr)
+	}
+	stdout, stderr, code = runTf2doc(t, dir, append(args, "-action", "DiffReadme")...)
+	if code != 0 || stdout != "" {
+		t.Errorf("an up to date README exited %d with stdout:\n%s\nstderr:\n%s", code, stdout, stderr)
+	}
+	_, stderr, code = runTf2doc(t, dir, append(args, "-action", "DiffReadme", "-dry-run")...)
+	if code != 1 || !strings.Contains(stderr, "-dry-run would only hide its diff") {
+		t.Errorf("with -dry-run exited %d with stderr:\n%s", code, stderr)
+	}
+}
+
+func TestErrorsAreReportedOnStderr(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "broken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"main.tf":        `variable "name" {}`,
+		"bad.tmpl":       "{{ .Nope\n",
+		"long.tmpl":      "{{/* " + strings.Repeat("x", 100000) + " */}}\n",
+		"broken/main.tf": "variable \"name\" {\n",
+	})
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"a missing module", []string{"-path", "missing", "-action", "VarsTable"}, "-path missing: stat missing: no such file or directory"},
+		{"a module with errors", []string{"-path", "broken", "-action", "VarsTable"}, "Problem Loading Module at broken"},
+		{"an unknown action", []string{"-path", ".", "-action", "Tables"}, "action Tables is not one of"},
+		{"an unknown format", []string{"-path", ".", "-action", "VarsTable", "-format", "xml"}, "format xml is not one of"},
+		{"a missing template", []string{"-path", ".", "-action", "RenderTemplate", "-templatePath", "missing.tmpl"}, "open missing.tmpl: no such file or directory"},
+		{"a template that doesn't parse", []string{"-path", ".", "-action", "RenderTemplate", "-templatePath", "bad.tmpl"}, "unclosed action"},
+		{"a template with a line too long to scan for headings", []string{"-path", ".", "-action", "RenderTemplate", "-templatePath", "long.tmpl"}, "failed building the table of contents of long.tmpl"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout, stderr, code := runTf2doc(t, dir, append([]string{"-no-detect-repo-url", "-no-config"}, test.args...)...)
+			if code != 1 {
+				t.Errorf("exited %d, want 1", code)
+			}
+			if stdout != "" {
+				t.Errorf("printed %q", stdout)
+			}
+			if !strings.Contains(stderr, test.want) {
+				t.Errorf("stderr is %q, want it to contain %q", stderr, test.want)
+			}
+			if strings.Contains(stderr, "panic:") || strings.Contains(stderr, "goroutine ") {
+				t.Errorf("panicked:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestTableFormatsRenderWithoutErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": `
+variable "name" {
+  description = "The name, e.g. \"app|web\""
+  default     = "app"
+}
+
+output "id" {
+  value = var.name
+}
+`})
+	for _, format := range tfdoc.ValidFormats {
+		for _, action := range []string{"VarsTable", "OutputsTable"} {
+			t.Run(format+"/"+action, func(t *testing.T) {
+				stdout, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-no-config", "-path", ".", "-action", action, "-format", format)
+				if code != 0 || stderr != "" {
+					t.Errorf("exited %d with stderr:\n%s", code, stderr)
+				}
+				if stdout == "" {
+					t.Error("printed nothing")
+				}
+			})
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		args  []string
+		want  int
+	}{
+		{"Lint on a clean module", map[string]string{"main.tf": `variable "name" { description = "The name" }`}, []string{"-action", "Lint"}, 0},
+		{"Lint with findings", map[string]string{"main.tf": `variable "name" {}`}, []string{"-action", "Lint"}, 2},
+		{"a dry run that would write", map[string]string{"main.tf": `variable "name" {}`}, []string{"-action", "VarsTable", "-output", "VARS.md", "-dry-run"}, 1},
+		{"-fail-on-todos with a marker", map[string]string{"main.tf": "# TODO: describe it\nvariable \"name\" {}\n"}, []string{"-action", "VarsTable", "-fail-on-todos"}, 1},
+		{"-min-tag-coverage not met", map[string]string{"main.tf": `resource "aws_s3_bucket" "this" {}`}, []string{"-action", "TagCoverage", "-min-tag-coverage", "50"}, 1},
+		{"a missing module", map[string]string{}, []string{"-path", "missing", "-action", "Lint"}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+			args := append([]string{"-no-detect-repo-url", "-no-config", "-path", "."}, test.args...)
+			if _, stderr, code := runTf2doc(t, dir, args...); code != test.want {
+				t.Errorf("exited %d, want %d, with stderr:\n%s", code, test.want, stderr)
+			}
+		})
+	}
+}
+
+func TestAdoptDryRun(t *testing.T) {
+	dir := t.TempDir()
+	readme := "## Outputs\n\nhand written\n"
+	writeFiles(t, dir, map[string]string{"main.tf": `output "id" { value = 1 }`, "README.md": readme})
+	stdout, stderr, code := runTf2doc(t, dir, "-no-detect-repo-url", "-no-config", "-path", ".", "-action", "Adopt", "-inject-into", "README.md", "-dry-run")
+	if code != 1 {
+		t.Errorf("exited %d, want 1, with stderr:\n%s", code, stderr)
+	}
+	diff, manifest := strings.Index(stdout, "+<!-- tf2doc:outputs:begin -->"), strings.Index(stdout, "update    README.md")
+	if diff < 0 || manifest < diff {
+		t.Errorf("got:\n%s\nwant the diff followed by the manifest", stdout)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "README.md")); err != nil || string(b) != readme {
+		t.Errorf("the README was changed to %q (%v)", b, err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// Version is the tool version, set at build time with -ldflags "-X main.Version=..."
+var Version = "dev"
+
+// MetricsSchemaVersion is bumped whenever a field is removed or changes meaning
+const MetricsSchemaVersion = 1
+
+type ModuleMetrics struct {
+	Path             string              `json:"path"`
+	LoadDurationMs   float64             `json:"load_duration_ms"`
+	RenderDurationMs float64             `json:"render_duration_ms"`
+	Variables        int                 `json:"variables"`
+	Outputs          int                 `json:"outputs"`
+	ManagedResources int                 `json:"managed_resources"`
+	DataResources    int                 `json:"data_resources"`
+	ModuleCalls      int                 `json:"module_calls"`
+	Warnings         int                 `json:"warnings"`
+	SkippedFiles     []tfdoc.SkippedFile `json:"skipped_files,omitempty"` // Left out by -allow-errors
+	Cached           bool                `json:"cached,omitempty"`        // Reused from -cache-dir, so only Path is known
+}
+
+// CacheMetrics counts the modules whose output -cache-dir had, and those it didn't
+type CacheMetrics struct {
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+type RunMetrics struct {
+	SchemaVersion    int             `json:"schema_version"`
+	ToolVersion      string          `json:"tool_version"`
+	Action           string          `json:"action"`
+	DurationMs       float64         `json:"duration_ms"`
+	ModulesProcessed int             `json:"modules_processed"`
+	Warnings         int             `json:"warnings"`
+	Cache            CacheMetrics    `json:"cache"`
+	Modules          []ModuleMetrics `json:"modules"`
+
+	start time.Time
+}
+
+func NewRunMetrics(action string) *RunMetrics {
+	return &RunMetrics{
+		SchemaVersion: MetricsSchemaVersion,
+		ToolVersion:   Version,
+		Action:        action,
+		Modules:       []ModuleMetrics{},
+		start:         time.Now(),
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// AddModule records the counts and timings for a single processed module, and the files left out of it
+func (m *RunMetrics) AddModule(module *tfconfig.Module, load, render time.Duration, skipped []tfdoc.SkippedFile) {
+	warnings := 0
+	for _, d := range module.Diagnostics {
+		if d.Severity == tfconfig.DiagWarning {
+			warnings++
+		}
+	}
+	m.Modules = append(m.Modules, ModuleMetrics{
+		Path:             module.Path,
+		LoadDurationMs:   durationMs(load),
+		RenderDurationMs: durationMs(render),
+		Variables:        len(module.Variables),
+		Outputs:          len(module.Outputs),
+		ManagedResources: len(module.ManagedResources),
+		DataResources:    len(module.DataResources),
+		ModuleCalls:      len(module.ModuleCalls),
+		Warnings:         warnings,
+		SkippedFiles:     skipped,
+	})
+	m.ModulesProcessed++
+	m.Warnings += warnings
+}
+
+// AddCachedModule records a module whose output came from the cache, without loading it
+func (m *RunMetrics) AddCachedModule(path string) {
+	m.Modules = append(m.Modules, ModuleMetrics{Path: path, Cached: true})
+	m.ModulesProcessed++
+	m.CacheLookup(true)
+}
+
+// CacheLookup counts a module looked up in the cache, and whether it was found
+func (m *RunMetrics) CacheLookup(hit bool) {
+	if hit {
+		m.Cache.Hits++
+	} else {
+		m.Cache.Misses++
+	}
+	m.Cache.HitRate = float64(m.Cache.Hits) / float64(m.Cache.Hits+m.Cache.Misses)
+}
+
+// Write finalises the run duration and writes the metrics as JSON to filePath
+func (m *RunMetrics) Write(w *tfdoc.FileWriter, filePath string) error {
+	m.DurationMs = durationMs(time.Since(m.start))
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.WriteFile(filePath, append(b, '\n'))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheLookup(t *testing.T) {
+	tests := []struct {
+		lookups []bool
+		want    CacheMetrics
+	}{
+		{nil, CacheMetrics{}},
+		{[]bool{true}, CacheMetrics{Hits: 1, HitRate: 1}},
+		{[]bool{false}, CacheMetrics{Misses: 1}},
+		{[]bool{true, false, false, true}, CacheMetrics{Hits: 2, Misses: 2, HitRate: 0.5}},
+		{[]bool{false, true, true, true}, CacheMetrics{Hits: 3, Misses: 1, HitRate: 0.75}},
+	}
+	for _, test := range tests {
+		metrics := NewRunMetrics("VarsTable")
+		for _, hit := range test.lookups {
+			metrics.CacheLookup(hit)
+		}
+		if metrics.Cache != test.want {
+			t.Errorf("after lookups %v the cache metrics are %+v, want %+v", test.lookups, metrics.Cache, test.want)
+		}
+	}
+}
+
+// readMetrics reads the -metrics-out file of a run
+func readMetrics(t *testing.T, file string) RunMetrics {
+	t.Helper()
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metrics RunMetrics
+	if err := json.Unmarshal(b, &metrics); err != nil {
+		t.Fatalf("%s: %v", file, err)
+	}
+	return metrics
+}
+
+func TestMetricsCountCacheHits(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.tf": `variable "name" {}`})
+	metricsFile := filepath.Join(dir, "metrics.json")
+	args := []string{"-path", ".", "-no-detect-repo-url", "-action", "VarsTable", "-cache-dir", ".tf2doc-cache", "-metrics-out", metricsFile}
+
+	first, _, code := runTf2doc(t, dir, args...)
+	if code != 0 {
+		t.Fatalf("the first run exited %d", code)
+	}
+	metrics := readMetrics(t, metricsFile)
+	if metrics.Cache != (CacheMetrics{Misses: 1}) || len(metrics.Modules) != 1 || metrics.Modules[0].Cached || metrics.Modules[0].Variables != 1 {
+		t.Errorf("the first run's metrics are %+v", metrics)
+	}
+
+	second, _, code := runTf2doc(t, dir, args...)
+	if code != 0 {
+		t.Fatalf("the second run exited %d", code)
+	}
+	if second != first {
+		t.Errorf("the cached output is %q, want %q", second, first)
+	}
+	metrics = readMetrics(t, metricsFile)
+	if metrics.Cache != (CacheMetrics{Hits: 1, HitRate: 1}) || len(metrics.Modules) != 1 || !metrics.Modules[0].Cached {
+		t.Errorf("the second run's metrics are %+v", metrics)
+	}
+}
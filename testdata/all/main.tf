@@ -0,0 +1,47 @@
+terraform {
+  required_version = ">= 1.3"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+    }
+  }
+}
+
+variable "name" {
+  description = "The name of the stack"
+  type        = string
+}
+
+variable "cidr" {
+  description = "The CIDR block of the VPC"
+  default     = "10.0.0.0/16"
+}
+
+output "bucket_arn" {
+  description = "The ARN of the logs bucket"
+  value       = aws_s3_bucket.logs.arn
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "${var.name}-logs"
+}
+
+resource "aws_iam_role" "app" {
+  name = var.name
+}
+
+resource "aws_iam_role_policy" "app_inline" {
+  role = aws_iam_role.app.id
+}
+
+data "aws_region" "current" {}
+
+data "aws_caller_identity" "this" {}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+  cidr    = var.cidr
+}
@@ -0,0 +1,7 @@
+data "aws_region" "current" {}
+
+data "aws_iam_policy_document" "assume" {
+  statement {
+    actions = ["sts:AssumeRole"]
+  }
+}
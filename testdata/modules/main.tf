@@ -0,0 +1,12 @@
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+
+  name = "main"
+}
+
+module "buckets" {
+  source = "./modules/buckets"
+
+  names = ["logs", "assets"]
+}
@@ -0,0 +1,234 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// adoptSection is a section tf2doc generates, which Adopt recognises in a hand-written README by its heading
+type adoptSection struct {
+	Region, Action string
+	Headings       []string // Normalised, as adoptHeading leaves them
+}
+
+// AdoptableSections are the sections Adopt replaces, with the headings each is commonly written under
+var AdoptableSections = []adoptSection{
+	{"inputs", "VarsTable", []string{"inputs", "input variables", "variables", "parameters", "arguments"}},
+	{"outputs", "OutputsTable", []string{"outputs", "output values"}},
+	{"requirements", "RequirementsTable", []string{"requirements", "providers", "provider requirements"}},
+	{"resources", "ManagedResourcesTable", []string{"resources", "managed resources"}},
+}
+
+var rHeadingNoise = regexp.MustCompile(`[^a-z ]+`)
+
+// adoptHeading normalises a heading for matching, keeping only its lower cased words
+func adoptHeading(title string) string {
+	return strings.Join(strings.Fields(rHeadingNoise.ReplaceAllString(strings.ToLower(title), " ")), " ")
+}
+
+// EditDistance is the Levenshtein distance between a and b
+func EditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = cur[j-1] + 1
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// matchAdoptSection finds the section a heading is for. Headings match when they are one of a section's
+// headings once normalised, allowing a typo or two in the longer ones, e.g. "Ouputs:".
+func matchAdoptSection(title string) (adoptSection, bool) {
+	heading := adoptHeading(title)
+	for _, s := range AdoptableSections {
+		for _, h := range s.Headings {
+			if heading == h || (len(h) >= 6 && EditDistance(heading, h) <= len(h)/6) {
+				return s, true
+			}
+		}
+	}
+	return adoptSection{}, false
+}
+
+// AdoptSections replaces the bodies of the sections of a hand-written markdown file whose headings match
+// AdoptableSections with marked regions holding contents, keyed by region, so -inject-into can keep them up to
+// date. Everything else is kept as it is, and what was replaced and left alone is reported on stderr.
+// Sections whose region the file already marks are left alone. The returned diff shows the change.
+func AdoptSections(w *FileWriter, file, marker string, contents map[string]string) (string, error) {
+	d, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(string(d), "\n")
+	marked, err := FindMarkedRegions(lines, marker)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", file, err)
+	}
+	adopted := make(map[string]bool)
+	for _, r := range marked {
+		adopted[r.Name] = true
+	}
+
+	headings := findMarkdownHeadings(lines)
+	result := []string{}
+	previous := 0
+	for i := 0; i < len(headings); i++ {
+		h := headings[i]
+		section, ok := matchAdoptSection(h.Title)
+		switch {
+		case !ok:
+			stderr.Printf("%s: left %q on line %d alone", file, h.Title, h.Start+1)
+			continue
+		case adopted[section.Region]:
+			stderr.Printf("%s: left %q on line %d alone, as region %s is already marked", file, h.Title, h.Start+1, section.Region)
+			continue
+		}
+
+		// The section runs to the next heading of the same or a higher level, taking its subsections
+		end := len(lines)
+		next := i + 1
+		for ; next < len(headings); next++ {
+			if headings[next].Level <= h.Level {
+				end = headings[next].Start
+				break
+			}
+		}
+		if holdsMarkers(lines[h.End+1:end], marker) {
+			stderr.Printf("%s: left %q on line %d alone, as it holds region markers", file, h.Title, h.Start+1)
+			continue
+		}
+
+		adopted[section.Region] = true
+		stderr.Printf("%s: replaced the body of %q on lines %d-%d with region %s, filled by %s", file, h.Title, h.End+2, end, section.Region, section.Action)
+		crlf := strings.HasSuffix(lines[h.Start], "\r")
+		eol := ""
+		if crlf {
+			eol = "\r"
+		}
+		result = append(result, lines[previous:h.End+1]...)
+		result = append(result, "", fmt.Sprintf("<!-- %s:%s:begin -->%s", marker, section.Region, eol))
+		result = append(result, regionBlock(contents[section.Region], crlf)...)
+		result = append(result, fmt.Sprintf("<!-- %s:%s:end -->%s", marker, section.Region, eol), eol)
+		previous = end
+		i = next - 1
+	}
+	result = append(result, lines[previous:]...)
+
+	diff := unifiedDiff(file, lines, result)
+	return diff, w.WriteFile(file, []byte(strings.Join(result, "\n")))
+}
+
+// holdsMarkers reports whether any of lines is a region marker
+func holdsMarkers(lines []string, marker string) bool {
+	rMarker := markerRegexp(marker)
+	for _, line := range lines {
+		if rMarker.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// unifiedDiff shows the lines changed from a to b as a unified diff of file, with three lines of context
+func unifiedDiff(file string, a, b []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type diffLine struct {
+		Op   byte
+		Text string
+		A, B int // Line indexes before and after the change
+	}
+	ops := []diffLine{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffLine{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffLine{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffLine{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	out := []string{fmt.Sprintf("--- %s", file), fmt.Sprintf("+++ %s", file)}
+	for start := 0; start < len(ops); {
+		if ops[start].Op == ' ' {
+			start++
+			continue
+		}
+		// A hunk takes the changes that are within twice the context of each other
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		last := start
+		for k := start; k < len(ops) && k-last <= 2*context; k++ {
+			if ops[k].Op != ' ' {
+				last = k
+			}
+		}
+		end := last + context + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		hunk := []string{}
+		aCount, bCount := 0, 0
+		for _, op := range ops[first:end] {
+			hunk = append(hunk, string(op.Op)+strings.TrimSuffix(op.Text, "\r"))
+			if op.Op != '+' {
+				aCount++
+			}
+			if op.Op != '-' {
+				bCount++
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", ops[first].A+1, aCount, ops[first].B+1, bCount))
+		out = append(out, hunk...)
+		start = end
+	}
+	if len(out) == 2 {
+		return ""
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,64 @@
+package tfdoc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchAdoptSection(t *testing.T) {
+	tests := []struct {
+		title  string
+		region string
+	}{
+		{"Inputs", "inputs"},
+		{"Input Variables:", "inputs"},
+		{"Ouputs", "outputs"},
+		{"## Provider requirements", "requirements"},
+		{"Managed resorces", "resources"},
+		{"Usage", ""},
+		{"Notes", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			section, ok := matchAdoptSection(test.title)
+			if ok != (test.region != "") || section.Region != test.region {
+				t.Errorf("matched %q, %v, want %q", section.Region, ok, test.region)
+			}
+		})
+	}
+}
+
+func TestAdoptSections(t *testing.T) {
+	defer func(l *log.Logger) { stderr = l }(stderr)
+	var buf bytes.Buffer
+	stderr = log.New(&buf, "", 0)
+
+	file := filepath.Join(t.TempDir(), "README.md")
+	readme := "# Module\n\n## Usage\n\nkept\n\n## Inputs\n\nold inputs\n\n### Notes\n\nold notes\n\n## Outputs\n\n<!-- tf2doc:outputs:begin -->\nold outputs\n<!-- tf2doc:outputs:end -->\n"
+	if err := ioutil.WriteFile(file, []byte(readme), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := &FileWriter{Allowed: []string{file}}
+	diff, err := AdoptSections(w, file, DefaultInjectMarker, map[string]string{"inputs": "new inputs", "outputs": "new outputs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Module\n\n## Usage\n\nkept\n\n## Inputs\n\n<!-- tf2doc:inputs:begin -->\n\nnew inputs\n\n<!-- tf2doc:inputs:end -->\n\n## Outputs\n\n<!-- tf2doc:outputs:begin -->\nold outputs\n<!-- tf2doc:outputs:end -->\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if !strings.Contains(diff, "-old notes") || !strings.Contains(diff, "+<!-- tf2doc:inputs:begin -->") {
+		t.Errorf("got diff:\n%s", diff)
+	}
+	if !strings.Contains(buf.String(), `left "Outputs" on line 15 alone, as region outputs is already marked`) {
+		t.Errorf("got warnings:\n%s", buf.String())
+	}
+}
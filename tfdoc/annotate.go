@@ -0,0 +1,94 @@
+package tfdoc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+const (
+	// DefaultAnnotateNew marks rows added since -annotate-since. {ref} is replaced with the ref.
+	DefaultAnnotateNew = "_new since {ref}_"
+	// DefaultAnnotateChanged marks variables whose type or default changed since -annotate-since
+	DefaultAnnotateChanged = "_changed since {ref}_"
+)
+
+// gitOutput runs git in dir and returns its stdout, or an error with its stderr
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// LoadModuleAt loads the module at modulePath as it was at the git ref, from its .tf files at that ref
+func LoadModuleAt(modulePath, ref string) (*tfconfig.Module, error) {
+	names, err := gitOutput(modulePath, "ls-tree", "--name-only", ref, "./")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "tf2doc-annotate")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range strings.Split(strings.TrimSpace(string(names)), "\n") {
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+		src, err := gitOutput(modulePath, "show", fmt.Sprintf("%s:./%s", ref, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), src, 0644); err != nil {
+			return nil, err
+		}
+	}
+	module, diags := tfconfig.LoadModule(dir)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("the module at %s: %s", ref, diags.Error())
+	}
+	return module, nil
+}
+
+// variableChanged reports whether v differs from old in its type, its default or whether it's required
+func variableChanged(old, v *tfconfig.Variable) bool {
+	return old.Type != v.Type || old.Required != v.Required || !reflect.DeepEqual(old.Default, v.Default)
+}
+
+// ChangeMarkers marks the variables and outputs of module that base lacks with newMarker, and the
+// variables variableChanged finds changed with changedMarker, keyed by table then name. Items unchanged
+// since base get no marker.
+func ChangeMarkers(base, module *tfconfig.Module, ref, newMarker, changedMarker string) map[string]map[string]string {
+	newMarker = strings.Replace(newMarker, "{ref}", ref, -1)
+	changedMarker = strings.Replace(changedMarker, "{ref}", ref, -1)
+	markers := map[string]map[string]string{"vars": {}, "outputs": {}}
+	for name, v := range module.Variables {
+		old, ok := base.Variables[name]
+		switch {
+		case !ok:
+			markers["vars"][name] = newMarker
+		case variableChanged(old, v):
+			markers["vars"][name] = changedMarker
+		}
+	}
+	for name := range module.Outputs {
+		if _, ok := base.Outputs[name]; !ok {
+			markers["outputs"][name] = newMarker
+		}
+	}
+	return markers
+}
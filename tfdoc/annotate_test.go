@@ -0,0 +1,101 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// gitCommitAll commits everything in dir, making it a git repo first if it isn't one
+func gitCommitAll(t *testing.T, dir string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+}
+
+func TestChangeMarkers(t *testing.T) {
+	base := &tfconfig.Module{
+		Variables: map[string]*tfconfig.Variable{
+			"same":    {Name: "same", Type: "string", Default: "a"},
+			"typed":   {Name: "typed", Type: "string"},
+			"default": {Name: "default", Type: "number", Default: 1},
+		},
+		Outputs: map[string]*tfconfig.Output{"arn": {Name: "arn"}},
+	}
+	module := &tfconfig.Module{
+		Variables: map[string]*tfconfig.Variable{
+			"same":    {Name: "same", Type: "string", Default: "a"},
+			"typed":   {Name: "typed", Type: "number"},
+			"default": {Name: "default", Type: "number", Default: 2},
+			"added":   {Name: "added", Type: "bool"},
+		},
+		Outputs: map[string]*tfconfig.Output{"arn": {Name: "arn"}, "id": {Name: "id"}},
+	}
+	got := ChangeMarkers(base, module, "v1.0.0", DefaultAnnotateNew, DefaultAnnotateChanged)
+	want := map[string]map[string]string{
+		"vars": {
+			"typed":   "_changed since v1.0.0_",
+			"default": "_changed since v1.0.0_",
+			"added":   "_new since v1.0.0_",
+		},
+		"outputs": {"id": "_new since v1.0.0_"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestVariableChanged(t *testing.T) {
+	old := &tfconfig.Variable{Name: "tags", Type: "map(string)", Default: map[string]interface{}{"Team": "platform"}}
+	tests := []struct {
+		v    tfconfig.Variable
+		want bool
+	}{
+		{tfconfig.Variable{Name: "tags", Type: "map(string)", Default: map[string]interface{}{"Team": "platform"}}, false},
+		{tfconfig.Variable{Name: "tags", Type: "map(any)", Default: map[string]interface{}{"Team": "platform"}}, true},
+		{tfconfig.Variable{Name: "tags", Type: "map(string)", Default: map[string]interface{}{"Team": "network"}}, true},
+		{tfconfig.Variable{Name: "tags", Type: "map(string)", Required: true}, true},
+		{tfconfig.Variable{Name: "tags", Type: "map(string)", Description: "Tags", Default: map[string]interface{}{"Team": "platform"}}, false},
+	}
+	for _, test := range tests {
+		if got := variableChanged(old, &test.v); got != test.want {
+			t.Errorf("variableChanged(%+v) = %v, want %v", test.v, got, test.want)
+		}
+	}
+}
+
+func TestLoadModuleAt(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.tf")
+	if err := ioutil.WriteFile(file, []byte("variable \"name\" {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommitAll(t, dir)
+	if err := ioutil.WriteFile(file, []byte("variable \"name\" {}\nvariable \"added\" {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	module, err := LoadModuleAt(dir, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := module.Variables["added"]; ok || len(module.Variables) != 1 {
+		t.Errorf("got variables %v, want only those committed at HEAD", module.Variables)
+	}
+	if _, err := LoadModuleAt(dir, "no-such-ref"); err == nil {
+		t.Error("got no error for a ref that doesn't exist")
+	}
+}
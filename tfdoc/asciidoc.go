@@ -0,0 +1,50 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AsciiDocTableCellEscape escapes what would end an AsciiDoc table cell, and keeps line breaks as hard
+// breaks within it
+func AsciiDocTableCellEscape(cellText string) string {
+	cellText = strings.Replace(cellText, "\r\n", "\n", -1)
+	cellText = strings.Replace(cellText, "|", "\\|", -1)
+	return strings.Replace(cellText, "\n", " +\n", -1)
+}
+
+// asciiDocCell turns a table cell's markdown links, images and code spans into AsciiDoc and escapes the rest
+func asciiDocCell(cell string) string {
+	return convertCellMarkdown(cell, AsciiDocTableCellEscape, func(image bool, text, src, title string) string {
+		text = strings.Replace(AsciiDocTableCellEscape(text), "]", "\\]", -1)
+		if image {
+			return fmt.Sprintf("image:%s[%s]", src, text)
+		}
+		if title != "" {
+			// Quoted so commas in the text don't start another attribute
+			return fmt.Sprintf("link:%s[\"%s\",title=\"%s\"]", src, strings.Replace(text, `"`, "&quot;", -1), strings.Replace(AsciiDocTableCellEscape(title), `"`, "&quot;", -1))
+		}
+		return fmt.Sprintf("link:%s[%s]", src, text)
+	}, func(c string) string {
+		return fmt.Sprintf("`+%s+`", AsciiDocTableCellEscape(c))
+	})
+}
+
+// AsciiDocTable renders a table as an AsciiDoc |=== table with a header row
+func AsciiDocTable(headings []string, data [][]string) string {
+	lines := []string{fmt.Sprintf("[cols=\"%d*\", options=\"header\"]", len(headings)), "|==="}
+	heading := []string{}
+	for _, h := range headings {
+		heading = append(heading, "|"+AsciiDocTableCellEscape(h))
+	}
+	lines = append(lines, strings.Join(heading, " "))
+	for _, d := range data {
+		row := []string{}
+		for _, val := range d {
+			row = append(row, "|"+asciiDocCell(val))
+		}
+		lines = append(lines, strings.Join(row, " "))
+	}
+	lines = append(lines, "|===")
+	return strings.Join(lines, "\n")
+}
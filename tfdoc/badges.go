@@ -0,0 +1,31 @@
+package tfdoc
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// shieldsEscape escapes text for a path segment of a shields.io static badge, where - and _ are separators
+func shieldsEscape(s string) string {
+	s = strings.Replace(s, "-", "--", -1)
+	s = strings.Replace(s, "_", "__", -1)
+	s = strings.Replace(s, " ", "_", -1)
+	return url.PathEscape(s)
+}
+
+// Badge renders a shields.io static badge image. The alt text is what plain text renderings show.
+func Badge(label, message, color, alt string) string {
+	src := fmt.Sprintf("https://img.shields.io/badge/%s-%s-%s", shieldsEscape(label), shieldsEscape(message), url.PathEscape(color))
+	alt = strings.NewReplacer("[", "\\[", "]", "\\]").Replace(alt)
+	return fmt.Sprintf("![%s](%s)", alt, src)
+}
+
+// constraintCell shows a version constraint as code, followed by a badge for it when badges is set
+func constraintCell(name, constraint string, badges bool) string {
+	cell := codeSpan(constraint)
+	if badges && constraint != "" {
+		cell += " " + Badge(name, constraint, "blue", constraint)
+	}
+	return cell
+}
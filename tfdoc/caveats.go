@@ -0,0 +1,88 @@
+package tfdoc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// SkippedFile is a .tf file left out of the docs by -allow-errors because it has errors
+type SkippedFile struct {
+	Filename    string   `json:"filename" yaml:"filename"`
+	Diagnostics []string `json:"diagnostics" yaml:"diagnostics"`
+}
+
+// IsolateBrokenFiles removes everything declared in files with errors from module, so the rest of it can
+// still be documented, and returns those files sorted by name. Errors that belong to no file can't be
+// isolated, and are returned as an error.
+func IsolateBrokenFiles(module *tfconfig.Module, diags tfconfig.Diagnostics) ([]SkippedFile, error) {
+	broken := make(map[string][]string)
+	for _, d := range diags {
+		if d.Severity != tfconfig.DiagError {
+			continue
+		}
+		message := d.Summary
+		if d.Detail != "" {
+			message += ": " + d.Detail
+		}
+		if d.Pos == nil || d.Pos.Filename == "" {
+			return nil, errors.New(message)
+		}
+		broken[d.Pos.Filename] = append(broken[d.Pos.Filename], fmt.Sprintf("line %d: %s", d.Pos.Line, message))
+	}
+
+	for key, v := range module.Variables {
+		if _, ok := broken[v.Pos.Filename]; ok {
+			delete(module.Variables, key)
+		}
+	}
+	for key, o := range module.Outputs {
+		if _, ok := broken[o.Pos.Filename]; ok {
+			delete(module.Outputs, key)
+		}
+	}
+	for _, resources := range []map[string]*tfconfig.Resource{module.ManagedResources, module.DataResources} {
+		for key, r := range resources {
+			if _, ok := broken[r.Pos.Filename]; ok {
+				delete(resources, key)
+			}
+		}
+	}
+	for key, c := range module.ModuleCalls {
+		if _, ok := broken[c.Pos.Filename]; ok {
+			delete(module.ModuleCalls, key)
+		}
+	}
+
+	skipped := []SkippedFile{}
+	for file, messages := range broken {
+		skipped = append(skipped, SkippedFile{Filename: tfpath.Base(file), Diagnostics: messages})
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Filename < skipped[j].Filename })
+	return skipped, nil
+}
+
+// CaveatsNote tells readers which files the docs leave out and why, or is empty when none are
+func CaveatsNote(skipped []SkippedFile) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	lines := []string{
+		"## Documentation caveats",
+		"",
+		"These files have errors, so nothing declared in them is documented:",
+		"",
+	}
+	for _, f := range skipped {
+		lines = append(lines, fmt.Sprintf("* %s", codeSpan(f.Filename)))
+		for _, d := range f.Diagnostics {
+			lines = append(lines, fmt.Sprintf("  * %s", strings.Join(strings.Fields(d), " ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,57 @@
+package tfdoc
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func TestIsolateBrokenFiles(t *testing.T) {
+	module, diags := tfconfig.LoadModule("testdata/partial")
+	if !diags.HasErrors() {
+		t.Fatal("the partial fixture loaded without errors")
+	}
+	skipped, err := IsolateBrokenFiles(module, diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SkippedFile{{"functions.tf", []string{"line 7: Missing newline after argument: An argument definition must end with a newline."}}}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped %+v, want %+v", skipped, want)
+	}
+
+	names := []string{}
+	for name := range module.Variables {
+		names = append(names, name)
+	}
+	for name := range module.Outputs {
+		names = append(names, name)
+	}
+	for name := range module.ManagedResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"arn", "aws_s3_bucket.this", "name"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("documenting %q, want only main.tf's %q", names, want)
+	}
+}
+
+func TestIsolateBrokenFilesWithoutAFile(t *testing.T) {
+	diags := tfconfig.Diagnostics{{Severity: tfconfig.DiagError, Summary: "Failed to read module directory"}}
+	if _, err := IsolateBrokenFiles(&tfconfig.Module{}, diags); err == nil || err.Error() != "Failed to read module directory" {
+		t.Errorf("got error %v, want the diagnostic's", err)
+	}
+}
+
+func TestCaveatsNote(t *testing.T) {
+	if note := CaveatsNote(nil); note != "" {
+		t.Errorf("with nothing skipped the note is %q", note)
+	}
+	got := CaveatsNote([]SkippedFile{{"functions.tf", []string{"line 7: Missing newline\n  after argument"}}})
+	want := "## Documentation caveats\n\nThese files have errors, so nothing declared in them is documented:\n\n* `functions.tf`\n  * line 7: Missing newline after argument"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
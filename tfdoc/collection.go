@@ -0,0 +1,133 @@
+package tfdoc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// CollectionModulesDir is where a collection keeps its modules, as in the registry's layout
+const CollectionModulesDir = "modules"
+
+// HasTfFiles reports whether dir holds any Terraform files
+func HasTfFiles(dir string) (bool, error) {
+	for _, pattern := range []string{"*.tf", "*.tf.json"} {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return false, err
+		}
+		if len(files) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindChildModules lists the directories under root's modules directory that hold Terraform files, in
+// natural order
+func FindChildModules(root string) ([]string, error) {
+	dirs, err := filepath.Glob(filepath.Join(root, CollectionModulesDir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	children := []string{}
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		ok, err := HasTfFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			children = append(children, dir)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return naturalLess(children[i], children[j]) })
+	return children, nil
+}
+
+// IsCollection reports whether root is a collection of modules with none of its own: it has no Terraform
+// files, but modules under its modules directory. An empty directory isn't one.
+func IsCollection(root string) (bool, error) {
+	own, err := HasTfFiles(root)
+	if err != nil || own {
+		return false, err
+	}
+	children, err := FindChildModules(root)
+	return len(children) > 0, err
+}
+
+// moduleSummary is the description of a child module: the description in its metadata file, or else the
+// first paragraph of its README
+func moduleSummary(dir, metadataFile string) string {
+	if meta, err := LoadMetadata(dir, metadataFile, nil); err == nil {
+		if d, ok := meta["description"].(string); ok && d != "" {
+			return d
+		}
+	}
+	f, err := os.Open(filepath.Join(dir, "README.md"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	paragraph := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" && len(paragraph) > 0:
+			return strings.Join(paragraph, " ")
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "<"):
+			// Headings, badges and HTML aren't a description
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return strings.Join(paragraph, " ")
+}
+
+// CollectionIndex renders a table of the modules of a collection, one row for each with a link to it, its
+// description and how many inputs, outputs and resources it has
+func CollectionIndex(root, baseUrl, modulePath, metadataFile string, opts Options) (string, error) {
+	children, err := FindChildModules(root)
+	if err != nil {
+		return "", err
+	}
+	headings := []string{"Module", "Description", "Inputs", "Outputs", "Resources"}
+	lengths := []string{"------", "-----------", "------", "-------", "---------"}
+	data := [][]string{}
+	for _, dir := range children {
+		name := tfpath.Base(dir)
+		child, diags := tfconfig.LoadModule(dir)
+		if diags.HasErrors() {
+			stderr.Printf("%s: %s", dir, diags.Error())
+		}
+		rel := path.Join(CollectionModulesDir, name)
+		url := DocsUrl(baseUrl, path.Join(modulePath, rel), opts.URLRewrites)
+		if url == "" {
+			url = rel
+		}
+		data = append(data, []string{
+			opts.link(name, url, "Documentation of the "+name+" module"),
+			TableDescription(moduleSummary(dir, metadataFile), url, "Documentation of the "+name+" module", opts),
+			strconv.Itoa(len(child.Variables)),
+			strconv.Itoa(len(child.Outputs)),
+			strconv.Itoa(len(child.ManagedResources)),
+		})
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("%s has no modules under %s", root, CollectionModulesDir)
+	}
+	return renderTable(opts, LocalHeadings(opts.Lang, headings), lengths, data)
+}
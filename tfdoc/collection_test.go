@@ -0,0 +1,58 @@
+package tfdoc
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsCollection(t *testing.T) {
+	tests := []struct {
+		root string
+		want bool
+	}{
+		{"testdata/collection", true},
+		{"testdata/collection/modules/network", false},
+		{"testdata/declaration", false},
+		{t.TempDir(), false},
+	}
+	for _, test := range tests {
+		got, err := IsCollection(test.root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("IsCollection(%s) = %v, want %v", test.root, got, test.want)
+		}
+	}
+}
+
+func TestFindChildModules(t *testing.T) {
+	got, err := FindChildModules("testdata/collection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join("testdata", "collection", "modules", "network"), filepath.Join("testdata", "collection", "modules", "storage")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCollectionIndex(t *testing.T) {
+	got, err := CollectionIndex("testdata/collection", "", "", DefaultMetadataFile, Options{Description: "full"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Module", "Description", "Inputs", "Outputs", "Resources"},
+		{"[network](modules/network)", "", "2", "1", "1"},
+		{"[storage](modules/storage)", "A bucket for the logs.", "1", "0", "1"},
+	}
+	if rows := markdownRows(got); !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows %q, want %q", rows, want)
+	}
+
+	if _, err := CollectionIndex(t.TempDir(), "", "", DefaultMetadataFile, Options{}); err == nil {
+		t.Error("got no error for a root without modules")
+	}
+}
@@ -0,0 +1,393 @@
+package tfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var ValidOverflows = []string{
+	"truncate",
+	"wrap",
+}
+
+type tableColumn struct {
+	Heading, Length string
+	Value           func(o TableObject) string
+}
+
+var (
+	nameColumn        = func(o TableObject) string { return strings.TrimSpace(o.Name + " " + o.Change) }
+	typeColumn        = func(o TableObject) string { return o.Type }
+	descriptionColumn = func(o TableObject) string { return o.Description }
+	positionColumn    = func(o TableObject) string { return o.Location }
+	dynamicColumn     = func(o TableObject) string { return o.Dynamic }
+	defaultColumn     = func(o TableObject) string { return defaultCell(o.Default) }
+	requiredColumn    = func(o TableObject) string { return o.Required }
+	sensitiveColumn   = func(o TableObject) string { return o.Sensitive }
+	providerColumn    = func(o TableObject) string { return o.Provider }
+	lookupColumn      = func(o TableObject) string { return o.Lookup }
+	aliasedColumn     = func(o TableObject) string { return o.Aliased }
+)
+
+// tableColumns is the registry of columns each table can show, keyed by table then column name
+var tableColumns = map[string]map[string]tableColumn{
+	"vars": {
+		"name":        {"Variable", "----", nameColumn},
+		"type":        {"Type", "------", typeColumn},
+		"default":     {"Default", "------", defaultColumn},
+		"required":    {"Required", "----", requiredColumn},
+		"sensitive":   {"Sensitive", "----", sensitiveColumn},
+		"description": {"Description", "--------", descriptionColumn},
+		"position":    {"Code Position", "------", positionColumn},
+	},
+	"outputs": {
+		"name":        {"Output name", "----", nameColumn},
+		"description": {"Description", "--------", descriptionColumn},
+		"position":    {"Code Position", "------", positionColumn},
+	},
+	"resources": {
+		"name":     {"Resource Name", "----", nameColumn},
+		"type":     {"Resource Type", "--------", typeColumn},
+		"position": {"Code Position", "------", positionColumn},
+		"dynamic":  {"Dynamic blocks", "--------", dynamicColumn},
+	},
+	"data": {
+		"name":     {"Resource Name", "----", nameColumn},
+		"type":     {"Resource Type", "--------", typeColumn},
+		"position": {"Code Position", "------", positionColumn},
+		"provider": {"Provider", "----", providerColumn},
+		"lookup":   {"Lookup", "----", lookupColumn},
+		"aliased":  {"Aliased", "----", aliasedColumn},
+	},
+	"modules": {
+		"name":     {"Module Name", "----", nameColumn},
+		"source":   {"Module Source", "--------", typeColumn},
+		"version":  {"Module Version", "--------", descriptionColumn},
+		"position": {"Module Location", "------", positionColumn},
+	},
+}
+
+// defaultColumns are the columns each table shows when -columns doesn't mention it
+var defaultColumns = map[string][]string{
+	"vars":      {"name", "type", "default", "required", "sensitive", "description", "position"},
+	"outputs":   {"name", "description", "position"},
+	"resources": {"name", "type", "position"},
+	"data":      {"name", "type", "position"},
+	"modules":   {"name", "source", "position"},
+}
+
+// ColumnSpec selects a column and how long its cells may be. A Width of 0 means no limit.
+type ColumnSpec struct {
+	Name     string
+	Width    int
+	Overflow string // One of ValidOverflows, or empty to leave long cells alone
+}
+
+// ParseColumnSpecs parses "table=col[:width[:overflow]],..." with several tables separated by ";",
+// e.g. "vars=name,type:40:truncate,description::wrap".
+func ParseColumnSpecs(specs []string) (map[string][]ColumnSpec, error) {
+	tables := make(map[string][]ColumnSpec)
+	for _, spec := range specs {
+		for _, tableSpec := range strings.Split(spec, ";") {
+			if strings.TrimSpace(tableSpec) == "" {
+				continue
+			}
+			parts := strings.SplitN(tableSpec, "=", 2)
+			table := strings.TrimSpace(parts[0])
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("column spec %q: expected table=columns", tableSpec)
+			}
+			available, ok := tableColumns[table]
+			if !ok {
+				return nil, fmt.Errorf("column spec %q: unknown table %q, expected one of %s", tableSpec, table, ColumnTableNames())
+			}
+			columns := []ColumnSpec{}
+			for _, colSpec := range strings.Split(parts[1], ",") {
+				col, err := parseColumnSpec(colSpec, available)
+				if err != nil {
+					return nil, fmt.Errorf("column spec %q in %s: %v", colSpec, table, err)
+				}
+				columns = append(columns, col)
+			}
+			tables[table] = columns
+		}
+	}
+	return tables, nil
+}
+
+func parseColumnSpec(spec string, available map[string]tableColumn) (ColumnSpec, error) {
+	fields := strings.Split(strings.TrimSpace(spec), ":")
+	if len(fields) > 3 {
+		return ColumnSpec{}, fmt.Errorf("expected name[:width[:overflow]]")
+	}
+	col := ColumnSpec{Name: fields[0]}
+	if _, ok := available[col.Name]; !ok {
+		names := []string{}
+		for name := range available {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return col, fmt.Errorf("unknown column %q, expected one of %s", col.Name, names)
+	}
+	if len(fields) > 1 && fields[1] != "" {
+		width, err := strconv.Atoi(fields[1])
+		if err != nil || width < 1 {
+			return col, fmt.Errorf("width %q is not a positive number", fields[1])
+		}
+		col.Width = width
+	}
+	if len(fields) > 2 && fields[2] != "" {
+		if !StringInSlice(fields[2], ValidOverflows) {
+			return col, fmt.Errorf("overflow %q is not one of: %s", fields[2], ValidOverflows)
+		}
+		col.Overflow = fields[2]
+	}
+	if col.Width > 0 && col.Overflow == "" {
+		col.Overflow = "truncate"
+	}
+	return col, nil
+}
+
+// hasColumn reports whether opts selects column for table
+func hasColumn(opts Options, table, column string) bool {
+	for _, c := range opts.Columns[table] {
+		if c.Name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// showsColumn reports whether table will show column, selected by opts or by default
+func showsColumn(opts Options, table, column string) bool {
+	if _, ok := opts.Columns[table]; ok {
+		return hasColumn(opts, table, column)
+	}
+	return StringInSlice(column, defaultColumns[table])
+}
+
+func ColumnTableNames() []string {
+	names := []string{}
+	for name := range tableColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fit applies the column's width and overflow behaviour to a cell
+func (c ColumnSpec) Fit(text string) string {
+	if c.Width == 0 {
+		return text
+	}
+	switch c.Overflow {
+	case "truncate":
+		return truncateMarkdown(text, c.Width)
+	case "wrap":
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = wrapLine(line, c.Width)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return text
+}
+
+// markdownSpan is a piece of a cell's markdown that is shown, or cut, as a whole: a character, an escape, an
+// HTML tag, a code span, a link or an image
+type markdownSpan struct {
+	raw   string
+	text  string // What the reader sees of a code span or link, cut to fit
+	open  string // What comes before text in raw, when a span can be cut
+	close string // What comes after text in raw, when a span can be cut
+	image bool
+}
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`^</?[a-zA-Z][^<>]*>`)
+	linkPattern      = regexp.MustCompile(`^(!?)\[([^\]]*)\]\(([^()\s]*)\)`)
+	backticksPattern = regexp.MustCompile("^`+")
+)
+
+// nextMarkdownSpan splits the first span off text
+func nextMarkdownSpan(text string) (markdownSpan, string) {
+	if m := linkPattern.FindStringSubmatch(text); m != nil {
+		return markdownSpan{raw: m[0], text: m[2], open: m[1] + "[", close: "](" + m[3] + ")", image: m[1] == "!"}, text[len(m[0]):]
+	}
+	if m := htmlTagPattern.FindString(text); m != "" {
+		return markdownSpan{raw: m}, text[len(m):]
+	}
+	if delim := backticksPattern.FindString(text); delim != "" {
+		// A code span ends at the next run of as many backticks, and without one the backticks are text
+		for i := len(delim); i < len(text); {
+			run := backticksPattern.FindString(text[i:])
+			if run == delim {
+				end := i + len(run)
+				return markdownSpan{raw: text[:end], text: text[len(delim):i], open: delim, close: delim}, text[end:]
+			}
+			if run == "" {
+				_, size := utf8.DecodeRuneInString(text[i:])
+				i += size
+			} else {
+				i += len(run)
+			}
+		}
+		return markdownSpan{raw: delim, text: delim}, text[len(delim):]
+	}
+	size := 1
+	if text[0] == '\\' && len(text) > 1 {
+		_, size = utf8.DecodeRuneInString(text[1:])
+		size++
+	} else {
+		_, size = utf8.DecodeRuneInString(text)
+	}
+	return markdownSpan{raw: text[:size], text: "."}, text[size:]
+}
+
+// shownWidth is how many characters the reader sees of span
+func (s markdownSpan) shownWidth() int {
+	return utf8.RuneCountInString(s.text)
+}
+
+// truncateMarkdown cuts text to width characters as the reader sees them, ending with "…". A code span or a
+// link's text that is cut keeps its backticks or link, and escapes, HTML tags and images are kept or left out
+// whole, so a cut never leaves markdown open to swallow the rest of the table.
+func truncateMarkdown(text string, width int) string {
+	spans := []markdownSpan{}
+	shown := 0
+	for rest := text; rest != ""; {
+		var span markdownSpan
+		span, rest = nextMarkdownSpan(rest)
+		spans = append(spans, span)
+		shown += span.shownWidth()
+	}
+	if shown <= width {
+		return text
+	}
+
+	var cut strings.Builder
+	room := width - 1 // Leaving a character for the "…"
+	for _, span := range spans {
+		w := span.shownWidth()
+		if w <= room {
+			cut.WriteString(span.raw)
+			room -= w
+			continue
+		}
+		if room > 0 && span.close != "" && !span.image {
+			part := string([]rune(span.text)[:room])
+			if span.open[0] == '`' {
+				// A backtick at the end would run into the closing ones
+				part = strings.TrimRight(part, "`")
+			}
+			if part != "" {
+				cut.WriteString(span.open + part + span.close)
+			}
+		}
+		break
+	}
+	return cut.String() + "…"
+}
+
+// wrapLine breaks text at spaces so no line is longer than width, unless a single word is
+func wrapLine(text string, width int) string {
+	words := strings.Fields(text)
+	lines := []string{}
+	current := ""
+	for _, word := range words {
+		if current != "" && len([]rune(current))+1+len([]rune(word)) > width {
+			lines = append(lines, current)
+			current = word
+		} else if current == "" {
+			current = word
+		} else {
+			current += " " + word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderColumns builds the markdown table for objs, sorted and with the columns selected for table
+// in opts or its default columns
+func renderColumns(table string, objs map[string]TableObject, opts Options) (string, error) {
+	objs, strippedPrefixes := stripNamePrefixes(table, objs, opts.StripPrefixes)
+
+	var rendered string
+	if opts.Group.By != "" && opts.Group.Tables[table] {
+		names, groups := groupByPrefix(objs, opts.Group)
+		sections := []string{}
+		for _, name := range names {
+			rows, err := renderRows(table, groups[name], opts)
+			if err != nil {
+				return "", err
+			}
+			sections = append(sections, fmt.Sprintf("## %s\n\n%s", name, rows))
+			opts.Explainer.Explain(Explanation{Region: table + " table", Notes: []string{fmt.Sprintf("section %q holds %d rows, by -group-by %s", name, len(groups[name]), opts.Group.By)}})
+		}
+		rendered = strings.Join(sections, "\n\n")
+	} else {
+		var err error
+		if rendered, err = renderRows(table, objs, opts); err != nil {
+			return "", err
+		}
+	}
+	if len(strippedPrefixes) > 0 {
+		rendered += stripFootnote(strippedPrefixes)
+	}
+	return rendered, nil
+}
+
+// renderRows renders objs as one table with the selected columns
+func renderRows(table string, objs map[string]TableObject, opts Options) (string, error) {
+	keys := getSortedKeys(objs, opts.Sort)
+
+	columns, ok := opts.Columns[table]
+	if !ok {
+		for _, name := range defaultColumns[table] {
+			columns = append(columns, ColumnSpec{Name: name})
+		}
+	}
+
+	headings := []string{}
+	lengths := []string{}
+	for _, c := range columns {
+		headings = append(headings, tableColumns[table][c.Name].Heading)
+		lengths = append(lengths, tableColumns[table][c.Name].Length)
+	}
+	data := [][]string{}
+	for i, k := range keys {
+		row := []string{}
+		notes := append([]string{}, objs[k].trace...)
+		for _, c := range columns {
+			value := tableColumns[table][c.Name].Value(objs[k])
+			if c.Name == "position" && (opts.Format == "csv" || opts.Format == "text") {
+				// Spreadsheets and terminals can't follow the link, so give the position as text
+				value = fmt.Sprintf("%s:%d", objs[k].Filename, objs[k].Line)
+			}
+			cell := c.Fit(value)
+			if cell != value {
+				notes = append(notes, fmt.Sprintf("%s column fitted to %d characters (%s) by -columns", c.Name, c.Width, c.Overflow))
+			}
+			if opts.Format != "html" && opts.Format != "csv" && opts.Format != "text" {
+				checkTableCell(table, c.Name, objs[k], cell, opts)
+			}
+			row = append(row, cell)
+		}
+		data = append(data, row)
+		if opts.Explainer != nil {
+			if tableKey(table, objs[k]) != k {
+				notes = append(notes, fmt.Sprintf("shown as %s by -strip-prefix", objs[k].Name))
+			}
+			notes = append(notes, fmt.Sprintf("row %d of %d, by -sort %s", i+1, len(keys), opts.Sort))
+			opts.Explainer.Explain(Explanation{Region: table + " table", Item: k, File: objs[k].Filename, Line: objs[k].Line, Notes: notes})
+		}
+	}
+	return renderTable(opts, LocalHeadings(opts.Lang, headings), lengths, data)
+}
@@ -0,0 +1,59 @@
+package tfdoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func TestFitTruncates(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"a longer description", 10, "a longer …"},
+		{"`\"a long default\"`", 8, "`\"a long`…"},
+		{"`\"ab\"`", 4, "`\"ab\"`"},
+		{"``a`b`` and more", 4, "``a`b``…"},
+		{"``a`b`` and more", 3, "``a``…"},
+		{"[main.tf: 12](https://example.com/main.tf#L12)", 6, "[main.](https://example.com/main.tf#L12)…"},
+		{"![badge](https://example.com/b.svg) text", 4, "…"},
+		{"one<br>two<br>three", 6, "one<br>tw…"},
+		{"a \\| b \\| c", 4, "a \\|…"},
+		{"`unclosed and long", 6, "`uncl…"},
+		{"日本語のテキスト", 4, "日本語…"},
+	}
+	for _, test := range tests {
+		c := ColumnSpec{Name: "description", Width: test.width, Overflow: "truncate"}
+		if got := c.Fit(test.text); got != test.want {
+			t.Errorf("Fit(%q) to %d = %q, want %q", test.text, test.width, got, test.want)
+		}
+	}
+}
+
+func TestTruncatedLongDefault(t *testing.T) {
+	module := &tfconfig.Module{Variables: map[string]*tfconfig.Variable{
+		"tags": {Name: "tags", Default: map[string]interface{}{"Environment": "production", "Team": "platform"}, Pos: tfconfig.SourcePos{Filename: "main.tf", Line: 1}},
+		"name": {Name: "name", Default: "bucket", Pos: tfconfig.SourcePos{Filename: "main.tf", Line: 5}},
+	}}
+	opts := Options{Sort: "name", Format: "markdown", Columns: map[string][]ColumnSpec{
+		"vars": {{Name: "name"}, {Name: "default", Width: 16, Overflow: "truncate"}, {Name: "position"}},
+	}}
+	table, err := VarsTable(module, "", "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := markdownRows(table)
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows in:\n%s", len(rows), table)
+	}
+	if want := []string{"tags", "`{\"Environment\":`…", "[main.tf: 1](main.tf#L1)"}; strings.Join(rows[2], "|") != strings.Join(want, "|") {
+		t.Errorf("got row %q, want %q", rows[2], want)
+	}
+	if want := "`\"bucket\"`"; rows[1][1] != want {
+		t.Errorf("got the short default %q, want %q", rows[1][1], want)
+	}
+}
@@ -0,0 +1,100 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// DefaultCompactBudget keeps -compact-pr output inside GitHub's limit on PR descriptions
+const DefaultCompactBudget = 60000
+
+// compactCollapseRows is how many rows a -compact-pr table may have before it's collapsed
+const compactCollapseRows = 20
+
+// compactColumns are the columns -compact-pr shows, without code positions as PRs are read beside the diff
+var compactColumns = map[string][]ColumnSpec{
+	"vars":    {{Name: "name"}, {Name: "type"}, {Name: "required"}},
+	"outputs": {{Name: "name"}, {Name: "description"}},
+}
+
+// CompactActions are the actions -compact-pr can shorten, and the tables each includes
+var CompactActions = map[string][]string{
+	"":             {"vars", "outputs"},
+	"VarsTable":    {"vars"},
+	"OutputsTable": {"outputs"},
+}
+
+// DocsUrl links to the module in the repository, after the first of rewrites that matches, or is empty
+// without a repoUrl
+func DocsUrl(baseUrl, modulePath string, rewrites []URLRewrite) string {
+	if baseUrl == "" {
+		return ""
+	}
+	parts := []string{strings.TrimSuffix(baseUrl, "/")}
+	if p := strings.Trim(modulePath, "/"); p != "" {
+		parts = append(parts, p)
+	}
+	return rewriteUrl(rewrites, strings.Join(parts, "/"))
+}
+
+// CompactPR renders the action's tables with compactColumns for a PR description. Tables of more than
+// compactCollapseRows rows are collapsed, and when the whole is longer than budget rows are cut from the
+// end, with a note pointing at the full docs.
+func CompactPR(module *tfconfig.Module, action, baseUrl, modulePath string, budget int, opts Options) (string, error) {
+	opts.Columns = compactColumns
+	opts.Description = "short"
+	sections := []string{}
+	for _, table := range CompactActions[action] {
+		var heading, rendered string
+		var rows int
+		var err error
+		switch table {
+		case "vars":
+			heading, rows = "Inputs", len(module.Variables)
+			rendered, err = VarsTable(module, baseUrl, modulePath, opts)
+		case "outputs":
+			heading, rows = "Outputs", len(module.Outputs)
+			rendered, err = OutputsTable(module, baseUrl, modulePath, opts)
+		}
+		if err != nil {
+			return "", err
+		}
+		if rows > compactCollapseRows {
+			rendered = CollapseTable(heading, rows, rendered)
+		} else {
+			rendered = fmt.Sprintf("### %s\n\n%s", heading, rendered)
+		}
+		sections = append(sections, rendered)
+	}
+	return compactToBudget(strings.Join(sections, "\n\n"), budget, DocsUrl(baseUrl, modulePath, opts.URLRewrites), opts), nil
+}
+
+// CollapseTable wraps a rendered table in a <details> section whose summary gives its row count. GitHub
+// only renders the table as markdown when blank lines separate it from the tags.
+func CollapseTable(summary string, rows int, table string) string {
+	return fmt.Sprintf("<details><summary>%s (%d)</summary>\n\n%s\n\n</details>", summary, rows, table)
+}
+
+// compactToBudget cuts doc to budget characters at a line break, closing a collapsed section it cut into
+// and noting where the rest is
+func compactToBudget(doc string, budget int, url string, opts Options) string {
+	if budget <= 0 || len(doc) <= budget {
+		return doc
+	}
+	note := "\n\n*Cut to fit the PR description, see the full docs for the rest.*"
+	if url != "" {
+		note = fmt.Sprintf("\n\n*Cut to fit the PR description, see the %s for the rest.*", opts.link("full docs", url, "Full documentation of the module"))
+	}
+	closing := "\n\n</details>"
+	cut := budget - len(note) - len(closing)
+	if cut < 0 {
+		cut = 0
+	}
+	doc = doc[:strings.LastIndex(doc[:cut], "\n")+1]
+	if strings.Count(doc, "<details>") > strings.Count(doc, "</details>") {
+		doc += closing
+	}
+	return strings.TrimRight(doc, "\n") + note
+}
@@ -0,0 +1,70 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfluenceToc replaces the table of contents in -format confluence, as Confluence builds its own anchors
+const ConfluenceToc = "{toc}"
+
+// ConfluenceTableCellEscape escapes the characters Confluence wiki markup would read as formatting, links,
+// macros or the end of the cell, and keeps line breaks as forced breaks within it
+func ConfluenceTableCellEscape(cellText string) string {
+	var escaped strings.Builder
+	for _, r := range rCellNewline.ReplaceAllString(cellText, "\n") {
+		switch r {
+		case '\\', '|', '[', ']', '{', '}', '*', '_', '+', '^', '~', '-', '!':
+			escaped.WriteRune('\\')
+		case '\n':
+			escaped.WriteString(" \\\\ ")
+			continue
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// confluenceCell turns a table cell's markdown links, images and code spans into Confluence wiki markup and
+// escapes the rest. Link titles become the link's tooltip, and images, such as badges, lose their alt text.
+func confluenceCell(cell string) string {
+	return convertCellMarkdown(cell, ConfluenceTableCellEscape, func(image bool, text, src, title string) string {
+		if image {
+			return fmt.Sprintf("!%s!", src)
+		}
+		if title != "" {
+			return fmt.Sprintf("[%s|%s|%s]", ConfluenceTableCellEscape(text), src, ConfluenceTableCellEscape(title))
+		}
+		return fmt.Sprintf("[%s|%s]", ConfluenceTableCellEscape(text), src)
+	}, func(c string) string {
+		return fmt.Sprintf("{{%s}}", ConfluenceTableCellEscape(c))
+	})
+}
+
+// ConfluenceTable renders a table as Confluence wiki markup, with a ||heading|| row
+func ConfluenceTable(headings []string, data [][]string) string {
+	return wikiTable(headings, data, confluenceCell)
+}
+
+// wikiTable lays out a table in the wiki markup Confluence and Jira share, with a ||heading|| row, writing each
+// cell with cell
+func wikiTable(headings []string, data [][]string, cell func(string) string) string {
+	heading := "||"
+	for _, h := range headings {
+		heading += ConfluenceTableCellEscape(h) + "||"
+	}
+	lines := []string{heading}
+	for _, d := range data {
+		row := "|"
+		for _, val := range d {
+			// Empty cells would merge into the cell separator
+			c := cell(val)
+			if c == "" {
+				c = " "
+			}
+			row += c + "|"
+		}
+		lines = append(lines, row)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,48 @@
+package tfdoc
+
+import "testing"
+
+func TestConfluenceTableCellEscape(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"a | b", `a \| b`},
+		{"{code} [link] *bold* _em_", `\{code\} \[link\] \*bold\* \_em\_`},
+		{"one\ntwo", `one \\ two`},
+		{"one\r\ntwo", `one \\ two`},
+		{`C:\path`, `C:\\path`},
+	}
+	for _, test := range tests {
+		if got := ConfluenceTableCellEscape(test.cell); got != test.want {
+			t.Errorf("got %q for %q, want %q", got, test.cell, test.want)
+		}
+	}
+}
+
+func TestConfluenceTable(t *testing.T) {
+	got := ConfluenceTable([]string{"Name", "Default"}, [][]string{
+		{"[name](https://example.com/main.tf#L1 \"main.tf line 1\")", "`\"a-b\"`"},
+		{"![badge](https://example.com/b.svg)", ""},
+	})
+	want := "||Name||Default||\n" +
+		"|[name|https://example.com/main.tf#L1|main.tf line 1]|{{\"a\\-b\"}}|\n" +
+		"|!https://example.com/b.svg!| |"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJiraTable(t *testing.T) {
+	got := JiraTable([]string{"Name", "Default"}, [][]string{
+		{"[name](https://example.com/main.tf#L1 \"main.tf line 1\")", "`\"a-b\"`"},
+		{"![badge](https://example.com/b.svg)", "a | b"},
+	})
+	want := "||Name||Default||\n" +
+		"|[name|https://example.com/main.tf#L1]|{{\"a\\-b\"}}|\n" +
+		"|!https://example.com/b.svg!|a \\| b|"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// coveredBlockTypes are the top-level blocks the docs account for, removed blocks in the Removed table.
+// locals and provider blocks are implementation details rather than part of a module's interface, so they
+// aren't reported either.
+var coveredBlockTypes = map[string]bool{
+	"terraform": true,
+	"variable":  true,
+	"output":    true,
+	"resource":  true,
+	"data":      true,
+	"module":    true,
+	"locals":    true,
+	"provider":  true,
+	"removed":   true,
+}
+
+// undocumentedBlock is a top-level block of a type the docs don't cover, and where it starts
+type undocumentedBlock struct {
+	Type string
+	Pos  tfconfig.SourcePos
+}
+
+// FindUndocumentedBlocks counts the top-level blocks in the module's .tf files of types the docs don't
+// cover, such as moved and check blocks. Files that don't parse are skipped, as tfconfig has
+// already reported them.
+func FindUndocumentedBlocks(modulePath string) (map[string]int, error) {
+	blocks, err := findUndocumentedBlocks(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, b := range blocks {
+		counts[b.Type]++
+	}
+	return counts, nil
+}
+
+// findUndocumentedBlocks lists the blocks FindUndocumentedBlocks counts
+func findUndocumentedBlocks(modulePath string) ([]undocumentedBlock, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := []undocumentedBlock{}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if !coveredBlockTypes[block.Type] {
+				blocks = append(blocks, undocumentedBlock{block.Type, tfconfig.SourcePos{Filename: file, Line: block.TypeRange.Start.Line}})
+			}
+		}
+	}
+	return blocks, nil
+}
+
+// UndocumentedSummary describes block counts like "moved (2), check (1)", most common first
+func UndocumentedSummary(counts map[string]int) string {
+	types := []string{}
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	parts := []string{}
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s (%d)", t, counts[t]))
+	}
+	return strings.Join(parts, ", ")
+}
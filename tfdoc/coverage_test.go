@@ -0,0 +1,46 @@
+package tfdoc
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindUndocumentedBlocks(t *testing.T) {
+	tests := []struct {
+		module  string
+		want    map[string]int
+		summary string
+	}{
+		{"coverage", map[string]int{"moved": 2, "check": 1}, "moved (2), check (1)"},
+		{"names", map[string]int{}, ""},
+	}
+	for _, test := range tests {
+		got, err := FindUndocumentedBlocks(filepath.Join("testdata", test.module))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.module, got, test.want)
+		}
+		if summary := UndocumentedSummary(got); summary != test.summary {
+			t.Errorf("%s: summary %q, want %q", test.module, summary, test.summary)
+		}
+	}
+}
+
+func TestUndocumentedSummary(t *testing.T) {
+	tests := []struct {
+		counts map[string]int
+		want   string
+	}{
+		{nil, ""},
+		{map[string]int{"check": 1}, "check (1)"},
+		{map[string]int{"check": 1, "moved": 3, "import": 1}, "moved (3), check (1), import (1)"},
+	}
+	for _, test := range tests {
+		if got := UndocumentedSummary(test.counts); got != test.want {
+			t.Errorf("UndocumentedSummary(%v) = %q, want %q", test.counts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package tfdoc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+)
+
+// csvCell reduces a table cell's markdown links, images and code spans to their text, for spreadsheets
+func csvCell(cell string) string {
+	return convertCellMarkdown(cell, func(s string) string { return s }, func(image bool, text, src, title string) string {
+		return text
+	}, func(c string) string {
+		return c
+	})
+}
+
+// CsvTable renders a table as RFC 4180 CSV with a header row
+func CsvTable(headings []string, data [][]string) (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	records := [][]string{headings}
+	for _, d := range data {
+		row := []string{}
+		for _, val := range d {
+			row = append(row, csvCell(val))
+		}
+		records = append(records, row)
+	}
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
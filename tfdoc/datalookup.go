@@ -0,0 +1,84 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// dataLookupKinds are the ways a data source can find what it reads, in the order they're listed
+var dataLookupKinds = []string{"ID", "name", "tag", "filter"}
+
+// FindDataLookups summarizes how each data source of the module's .tf files finds what it reads, from
+// the names of its arguments and filter blocks, keyed by type.name. Data sources with none of them are
+// left out. Files that don't parse are skipped, as tfconfig has already reported them.
+func FindDataLookups(modulePath string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]string)
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "data" || len(block.Labels) != 2 {
+				continue
+			}
+			if lookup := dataLookup(block.Body, src); lookup != "" {
+				found[block.Labels[0]+"."+block.Labels[1]] = lookup
+			}
+		}
+	}
+	return found, nil
+}
+
+// dataLookup lists the dataLookupKinds a data source's body, parsed from src, uses, e.g. "name, tag"
+func dataLookup(body *hclsyntax.Body, src []byte) string {
+	used := make(map[string]bool)
+	for name := range body.Attributes {
+		switch {
+		case name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_ids"):
+			used["ID"] = true
+		case name == "name" || strings.HasPrefix(name, "name_") || strings.HasSuffix(name, "_name"):
+			used["name"] = true
+		case strings.HasPrefix(name, "tag"):
+			used["tag"] = true
+		}
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "filter" {
+			continue
+		}
+		// AWS filters on tags are named like tag:Name
+		kind := "filter"
+		if attr, ok := block.Body.Attributes["name"]; ok {
+			if strings.HasPrefix(strings.Trim(string(attr.Expr.Range().SliceBytes(src)), `"`), "tag") {
+				kind = "tag"
+			}
+		}
+		used[kind] = true
+	}
+
+	kinds := []string{}
+	for _, kind := range dataLookupKinds {
+		if used[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return strings.Join(kinds, ", ")
+}
@@ -0,0 +1,72 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// DeprecatedFile beside a module marks it deprecated, its content giving the reason
+const DeprecatedFile = "DEPRECATED.md"
+
+const deprecatedDirective = "tf2doc:deprecated"
+
+// Deprecation says whether the module is deprecated, why, and what to use instead
+type Deprecation struct {
+	Deprecated  bool
+	Message     string
+	Replacement string
+}
+
+// FindDeprecation looks for a DEPRECATED.md beside the module, whose content is the message, and for a
+// comment in main.tf like
+//
+//	# tf2doc:deprecated replacement=org/new-module Moved to the new network layout
+//
+// where text other than replacement= is the message. A DEPRECATED.md message wins over the directive's.
+func FindDeprecation(modulePath string) (Deprecation, error) {
+	d := Deprecation{}
+
+	src, err := ioutil.ReadFile(filepath.Join(modulePath, "main.tf"))
+	if err != nil && !os.IsNotExist(err) {
+		return d, err
+	}
+	tokens, _ := hclsyntax.LexConfig(src, "main.tf", hcl.Pos{Line: 1, Column: 1})
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(string(token.Bytes)), "#/*"))
+		text = strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+		if !strings.HasPrefix(text, deprecatedDirective) {
+			continue
+		}
+		d.Deprecated = true
+		message := []string{}
+		for _, field := range strings.Fields(strings.TrimPrefix(text, deprecatedDirective)) {
+			if strings.HasPrefix(field, "replacement=") {
+				d.Replacement = strings.TrimPrefix(field, "replacement=")
+			} else {
+				message = append(message, field)
+			}
+		}
+		d.Message = strings.Join(message, " ")
+		break
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(modulePath, DeprecatedFile))
+	switch {
+	case err == nil:
+		d.Deprecated = true
+		if message := strings.TrimSpace(string(b)); message != "" {
+			d.Message = message
+		}
+	case !os.IsNotExist(err):
+		return d, err
+	}
+	return d, nil
+}
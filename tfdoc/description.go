@@ -0,0 +1,64 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Words ending in a full stop that don't end a sentence
+var sentenceAbbreviations = []string{
+	"e.g.", "eg.", "i.e.", "ie.", "etc.", "vs.", "cf.", "approx.", "incl.", "min.", "max.", "no.", "mr.", "dr.",
+}
+
+func endsWithAbbreviation(s string) bool {
+	word := s
+	if i := strings.LastIndexFunc(s, unicode.IsSpace); i >= 0 {
+		word = s[i+1:]
+	}
+	word = strings.ToLower(strings.TrimLeft(word, "(\"'"))
+	for _, abbr := range sentenceAbbreviations {
+		if word == abbr {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstSentence returns the first sentence of s, and whether anything followed it. A sentence ends at
+// '.', '!' or '?' followed by whitespace, or at a blank line. Full stops inside numbers or file names
+// and after common abbreviations don't end a sentence.
+func FirstSentence(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\n' && strings.HasPrefix(strings.TrimLeft(s[i+1:], " \t\r"), "\n") {
+			return strings.TrimSpace(s[:i]), true
+		}
+		if c != '.' && c != '!' && c != '?' {
+			continue
+		}
+		if i+1 < len(s) && !unicode.IsSpace(rune(s[i+1])) {
+			continue
+		}
+		if c == '.' && endsWithAbbreviation(s[:i+1]) {
+			continue
+		}
+		rest := strings.TrimSpace(s[i+1:])
+		return s[:i+1], rest != ""
+	}
+	return s, false
+}
+
+// TableDescription returns the description to show in a table cell. When opts.Description is short only the
+// first sentence is kept, followed by an ellipsis linking to the full definition at url, titled title.
+func TableDescription(description, url, title string, opts Options) string {
+	if opts.Description != "short" {
+		return description
+	}
+	first, truncated := FirstSentence(description)
+	if !truncated {
+		return first
+	}
+	return fmt.Sprintf("%s %s", first, opts.link("…", url, title))
+}
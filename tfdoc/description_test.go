@@ -0,0 +1,52 @@
+package tfdoc
+
+import "testing"
+
+func TestFirstSentence(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+		truncated   bool
+	}{
+		{"The name. It must be unique.", "The name.", true},
+		{"The tags, e.g. Owner and Team. Merged with the defaults.", "The tags, e.g. Owner and Team.", true},
+		{"The zones, i.e. where it runs. One per subnet.", "The zones, i.e. where it runs.", true},
+		{"Some sizes (e.g. small) etc. are allowed. Others aren't.", "Some sizes (e.g. small) etc. are allowed.", true},
+		{"The ratio, from 0.5 to 2.25 times the default. Defaults to 1.0.", "The ratio, from 0.5 to 2.25 times the default.", true},
+		{"Costs $1.50 per hour. Billed monthly.", "Costs $1.50 per hour.", true},
+		{"Read from config.yaml in the module. Optional.", "Read from config.yaml in the module.", true},
+		{"Version 1.2.3 or later is needed", "Version 1.2.3 or later is needed", false},
+		{"Is it public? Defaults to no.", "Is it public?", true},
+		{"Deletes everything! Use with care.", "Deletes everything!", true},
+		{"The name of the bucket, which must be globally unique, lowercase, between 3 and 63 characters long and made of letters, numbers, dots and hyphens only, e.g. my-logs-bucket.", "The name of the bucket, which must be globally unique, lowercase, between 3 and 63 characters long and made of letters, numbers, dots and hyphens only, e.g. my-logs-bucket.", false},
+		{"A single sentence without a full stop", "A single sentence without a full stop", false},
+		{"The first paragraph\n\nThe second paragraph.", "The first paragraph", true},
+		{"Wrapped over\ntwo lines. Then more.", "Wrapped over\ntwo lines.", true},
+		{"  Surrounded by space.  ", "Surrounded by space.", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		got, truncated := FirstSentence(test.description)
+		if got != test.want || truncated != test.truncated {
+			t.Errorf("FirstSentence(%q) = %q, %v, want %q, %v", test.description, got, truncated, test.want, test.truncated)
+		}
+	}
+}
+
+func TestTableDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		mode        string
+		want        string
+	}{
+		{"The name. It must be unique.", "full", "The name. It must be unique."},
+		{"The name. It must be unique.", "short", "The name. […](#name)"},
+		{"The name.", "short", "The name."},
+		{"The tags, e.g. Owner. Merged.", "short", "The tags, e.g. Owner. […](#name)"},
+	}
+	for _, test := range tests {
+		if got := TableDescription(test.description, "#name", "", Options{Description: test.mode}); got != test.want {
+			t.Errorf("TableDescription(%q, %s) = %q, want %q", test.description, test.mode, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// FindDynamicBlocks returns the dynamic blocks in each managed resource of the module's .tf files, keyed
+// by type.name. Nested blocks are given as dotted paths of the blocks they're inside, e.g. "setting.rule".
+// Files that don't parse are reported on stderr and skipped.
+func FindDynamicBlocks(modulePath string) (map[string][]string, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	found := make(map[string][]string)
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			stderr.Printf("not checking %s for dynamic blocks: %s", file, diags.Error())
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "resource" || len(block.Labels) != 2 {
+				continue
+			}
+			key := block.Labels[0] + "." + block.Labels[1]
+			found[key] = append(found[key], dynamicBlockPaths(block.Body, nil)...)
+		}
+	}
+	return found, nil
+}
+
+// dynamicBlockPaths lists the dynamic blocks in body, and in the blocks nested in it, prefixed by parents
+func dynamicBlockPaths(body *hclsyntax.Body, parents []string) []string {
+	paths := []string{}
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == "dynamic" && len(block.Labels) == 1:
+			path := append(append([]string{}, parents...), block.Labels[0])
+			paths = append(paths, strings.Join(path, "."))
+			paths = append(paths, dynamicBlockPaths(block.Body, path)...)
+		case block.Type == "content":
+			// The body of the enclosing dynamic block, already part of the path
+			paths = append(paths, dynamicBlockPaths(block.Body, parents)...)
+		default:
+			paths = append(paths, dynamicBlockPaths(block.Body, append(append([]string{}, parents...), block.Type))...)
+		}
+	}
+	return paths
+}
+
+// DynamicBlocksTable lists the managed resources that use dynamic blocks
+func DynamicBlocksTable(dynamic map[string][]string) string {
+	keys := []string{}
+	for key, paths := range dynamic {
+		if len(paths) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	data := [][]string{}
+	for _, key := range keys {
+		data = append(data, []string{key, strings.Join(dynamic[key], ", ")})
+	}
+	return MarkdownTable([]string{"Resource", "Dynamic blocks"}, []string{"----", "--------"}, data)
+}
@@ -0,0 +1,139 @@
+package tfdoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// Values for variables with names like these are never printed in the environment matrix
+var rSecretName = regexp.MustCompile(`(?i)(password|passwd|secret|token|private_key|api_key|credential)`)
+
+type TfvarsValue struct {
+	Value string
+	Line  int
+}
+
+type TfvarsFile struct {
+	Env    string // File name without the .tfvars / .tfvars.json extension
+	Path   string
+	Values map[string]TfvarsValue
+}
+
+// LoadTfvarsFiles parses every .tfvars or .tfvars.json file matching glob, relative to the module directory
+func LoadTfvarsFiles(modulePath, glob string) ([]TfvarsFile, error) {
+	matches, err := filepath.Glob(filepath.Join(modulePath, glob))
+	if err != nil {
+		return nil, fmt.Errorf("bad tfvars glob %q: %v", glob, err)
+	}
+	sort.Strings(matches)
+
+	parser := hclparse.NewParser()
+	files := []TfvarsFile{}
+	for _, match := range matches {
+		var f *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(match, ".json") {
+			f, diags = parser.ParseJSONFile(match)
+		} else {
+			f, diags = parser.ParseHCLFile(match)
+		}
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("problem parsing %s: %s", match, diags.Error())
+		}
+		attrs, diags := f.Body.JustAttributes()
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("problem reading %s: %s", match, diags.Error())
+		}
+
+		env := strings.TrimSuffix(strings.TrimSuffix(tfpath.Base(match), ".json"), ".tfvars")
+		tfvars := TfvarsFile{Env: env, Path: match, Values: make(map[string]TfvarsValue)}
+		for name, attr := range attrs {
+			rng := attr.Expr.Range()
+			tfvars.Values[name] = TfvarsValue{
+				Value: string(rng.SliceBytes(f.Bytes)),
+				Line:  attr.NameRange.Start.Line,
+			}
+		}
+		files = append(files, tfvars)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no tfvars files match %q in %s", glob, modulePath)
+	}
+	return files, nil
+}
+
+// EnvMatrixTable renders one row per variable and one column per environment, showing the value each
+// environment sets. It also returns findings for keys the module does not declare.
+func EnvMatrixTable(module *tfconfig.Module, envs []TfvarsFile) (string, []string) {
+	headings := []string{"Variable"}
+	lengths := []string{"----"}
+	for _, env := range envs {
+		headings = append(headings, env.Env)
+		lengths = append(lengths, "----")
+	}
+	headings = append(headings, "Notes")
+	lengths = append(lengths, "----")
+
+	names := make(map[string]bool)
+	for name := range module.Variables {
+		names[name] = true
+	}
+	findings := []string{}
+	for _, env := range envs {
+		for name, v := range env.Values {
+			if _, ok := module.Variables[name]; !ok {
+				names[name] = true
+				findings = append(findings, fmt.Sprintf("%s:%d: %q is not a variable of this module", env.Path, v.Line, name))
+			}
+		}
+	}
+	sort.Strings(findings)
+
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	data := [][]string{}
+	for _, name := range sortedNames {
+		row := []string{name}
+		missing := []string{}
+		setCount := 0
+		for _, env := range envs {
+			v, ok := env.Values[name]
+			switch {
+			case !ok:
+				row = append(row, "")
+				missing = append(missing, env.Env)
+			case rSecretName.MatchString(name):
+				row = append(row, "✔")
+				setCount++
+			default:
+				row = append(row, codeSpan(v.Value))
+				setCount++
+			}
+		}
+
+		notes := []string{}
+		if _, ok := module.Variables[name]; !ok {
+			notes = append(notes, "not declared by the module")
+		}
+		if setCount > 0 && len(missing) > 0 {
+			notes = append(notes, fmt.Sprintf("not set in %s", strings.Join(missing, ", ")))
+		}
+		row = append(row, strings.Join(notes, "; "))
+		data = append(data, row)
+	}
+
+	return MarkdownTable(headings, lengths, data), findings
+}
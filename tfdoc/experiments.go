@@ -0,0 +1,71 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// FindExperiments returns the language experiments enabled by `experiments = [...]` in the module's
+// terraform blocks, sorted. tfconfig ignores the setting, so the files are parsed again here. Files that
+// don't parse are skipped, as tfconfig has already reported them.
+func FindExperiments(modulePath string) ([]string, error) {
+	positions, err := findExperimentPositions(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	experiments := []string{}
+	for name := range positions {
+		experiments = append(experiments, name)
+	}
+	sort.Strings(experiments)
+	return experiments, nil
+}
+
+// findExperimentPositions finds where each experiment FindExperiments returns is first enabled
+func findExperimentPositions(modulePath string) (map[string]tfconfig.SourcePos, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]tfconfig.SourcePos)
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "terraform" {
+				continue
+			}
+			attr, ok := block.Body.Attributes["experiments"]
+			if !ok {
+				continue
+			}
+			exprs, diags := hcl.ExprList(attr.Expr)
+			if diags.HasErrors() {
+				continue
+			}
+			for _, expr := range exprs {
+				name := hcl.ExprAsKeyword(expr)
+				if _, seen := found[name]; name != "" && !seen {
+					found[name] = tfconfig.SourcePos{Filename: file, Line: expr.Range().Start.Line}
+				}
+			}
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,194 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+var ValidExplainFormats = []string{
+	"text",
+	"json",
+}
+
+// Explanation says where one part of the output came from
+type Explanation struct {
+	Region string   `json:"region"`         // The part of the output, e.g. "vars table" or "template field .TerraformVarsTable"
+	Item   string   `json:"item,omitempty"` // The row, for table rows
+	File   string   `json:"file,omitempty"` // The .tf file or template it came from
+	Line   int      `json:"line,omitempty"`
+	Notes  []string `json:"notes,omitempty"` // The filters, sorts and link construction applied, in order
+}
+
+// Explainer records an Explanation for each table row, template field and region rendered during a run.
+// Set Options.Explainer to have the tables rendered with it record theirs. A nil Explainer records nothing.
+type Explainer struct {
+	Explanations []Explanation
+	seen         map[string]bool
+}
+
+// Explain records x, unless an identical explanation already was. Tables are often rendered more than
+// once, e.g. for a template field and an injected region, and each row only needs explaining once.
+func (e *Explainer) Explain(x Explanation) {
+	if e == nil {
+		return
+	}
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%s", x.Region, x.Item, x.File, x.Line, strings.Join(x.Notes, "\x00"))
+	if e.seen == nil {
+		e.seen = make(map[string]bool)
+	}
+	if e.seen[key] {
+		return
+	}
+	e.seen[key] = true
+	e.Explanations = append(e.Explanations, x)
+}
+
+// linkNotes describes how a source link was built: the base it starts with and any rewrite applied
+func linkNotes(baseUrl, modulePath, raw, url string, rewrites []URLRewrite) []string {
+	notes := []string{}
+	switch {
+	case baseUrl == "" && modulePath == "":
+		notes = append(notes, fmt.Sprintf("link %s is relative to the module", raw))
+	case baseUrl == "":
+		notes = append(notes, fmt.Sprintf("link %s is relative, with -modulePath %q", raw, modulePath))
+	default:
+		notes = append(notes, fmt.Sprintf("link %s is built from base %q and -modulePath %q", raw, baseUrl, modulePath))
+	}
+	if raw != url {
+		notes = append(notes, fmt.Sprintf("link rewritten to %s by -repo-url-rewrite %s", url, rewrites[matchingRewrite(rewrites, raw)].Pattern))
+	}
+	return notes
+}
+
+// ExplainTemplateFields records each field of the template data that t and the templates it defines
+// use, with where in the template it's used
+func (e *Explainer) ExplainTemplateFields(t *template.Template, sources map[string]string) {
+	if e == nil {
+		return
+	}
+	for _, defined := range t.Templates() {
+		if defined.Tree == nil || defined.Tree.Root == nil {
+			continue
+		}
+		tree := defined.Tree
+		walkTemplateNodes(tree.Root, func(n parse.Node) {
+			field, ok := n.(*parse.FieldNode)
+			if !ok {
+				return
+			}
+			location, _ := tree.ErrorContext(n)
+			name := "." + strings.Join(field.Ident, ".")
+			notes := []string{fmt.Sprintf("used at %s in template %q", location, defined.Name())}
+			if source, ok := sources[field.Ident[0]]; ok {
+				notes = append(notes, source)
+			}
+			e.Explain(Explanation{Region: "template field " + name, Notes: notes})
+		})
+	}
+}
+
+// walkTemplateNodes calls f for n and everything below it
+func walkTemplateNodes(n parse.Node, f func(parse.Node)) {
+	if n == nil {
+		return
+	}
+	f(n)
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplateNodes(c, f)
+		}
+	case *parse.ActionNode:
+		walkTemplateNodes(n.Pipe, f)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplateNodes(c, f)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			walkTemplateNodes(a, f)
+		}
+	case *parse.ChainNode:
+		walkTemplateNodes(n.Node, f)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, f)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, f)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, f)
+	case *parse.TemplateNode:
+		walkTemplateNodes(n.Pipe, f)
+	}
+}
+
+func walkBranch(b *parse.BranchNode, f func(parse.Node)) {
+	walkTemplateNodes(b.Pipe, f)
+	walkTemplateNodes(b.List, f)
+	if b.ElseList != nil {
+		walkTemplateNodes(b.ElseList, f)
+	}
+}
+
+// Report describes the explanations as text grouped by region, or as JSON
+func (e *Explainer) Report(format string) ([]byte, error) {
+	explanations := append([]Explanation{}, e.Explanations...)
+	sort.SliceStable(explanations, func(i, j int) bool {
+		return explanations[i].Region < explanations[j].Region
+	})
+	if format == "json" {
+		b, err := json.MarshalIndent(explanations, "", "  ")
+		return append(b, '\n'), err
+	}
+
+	var report strings.Builder
+	region := ""
+	for _, x := range explanations {
+		if x.Region != region {
+			if region != "" {
+				report.WriteString("\n")
+			}
+			region = x.Region
+			fmt.Fprintf(&report, "%s\n", region)
+		}
+		indent := "  "
+		if x.Item != "" || x.File != "" {
+			where := x.File
+			if x.Line > 0 {
+				where = fmt.Sprintf("%s:%d", x.File, x.Line)
+			}
+			if x.Item != "" && where != "" {
+				where = fmt.Sprintf(" (%s)", where)
+			}
+			fmt.Fprintf(&report, "  %s%s\n", x.Item, where)
+			indent = "    "
+		}
+		for _, note := range x.Notes {
+			fmt.Fprintf(&report, "%s%s\n", indent, note)
+		}
+	}
+	return []byte(report.String()), nil
+}
+
+// WriteReport writes the report to filePath, or to stderr when filePath is -
+func (e *Explainer) WriteReport(w *FileWriter, filePath, format string) error {
+	report, err := e.Report(format)
+	if err != nil {
+		return err
+	}
+	if filePath == "-" {
+		_, err := os.Stderr.Write(report)
+		return err
+	}
+	return w.WriteFile(filePath, report)
+}
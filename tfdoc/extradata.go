@@ -0,0 +1,56 @@
+package tfdoc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// ExtraDataFunc computes extra values for templates from the loaded module, e.g. cost estimates
+type ExtraDataFunc func(m *tfconfig.Module) (map[string]interface{}, error)
+
+// templateDataFields are the names of the built-in template data fields
+func templateDataFields() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(DocData{})
+	for i := 0; i < t.NumField(); i++ {
+		fields[t.Field(i).Name] = true
+	}
+	return fields
+}
+
+// CollectExtraData runs opts.ExtraData and merges their values. A function failing is an error with
+// opts.Strict, and otherwise a warning, leaving out its values. Two functions setting the same key, or a
+// key named like a built-in field, is always an error, since {{ .Extra.X }} and {{ .X }} would be easily
+// confused.
+func CollectExtraData(module *tfconfig.Module, opts RenderOptions) (map[string]interface{}, error) {
+	extra := make(map[string]interface{})
+	builtIn := templateDataFields()
+	for i, fn := range opts.ExtraData {
+		values, err := fn(module)
+		if err != nil && opts.Strict {
+			return nil, fmt.Errorf("extra data function %d: %v", i+1, err)
+		}
+		if err != nil {
+			stderr.Printf("leaving out the values of extra data function %d: %v", i+1, err)
+			continue
+		}
+		keys := []string{}
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if builtIn[key] {
+				return nil, fmt.Errorf("extra data function %d sets %q, which is the name of a built-in template field", i+1, key)
+			}
+			if _, ok := extra[key]; ok {
+				return nil, fmt.Errorf("extra data function %d sets %q, which an earlier function already set", i+1, key)
+			}
+			extra[key] = values[key]
+		}
+	}
+	return extra, nil
+}
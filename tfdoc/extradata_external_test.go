@@ -0,0 +1,76 @@
+package tfdoc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/JoeButler99/TF_2_DOC/tfdoc"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// TestRenderWithExtraData renders a template as a program embedding the package would, from outside it
+func TestRenderWithExtraData(t *testing.T) {
+	module, diags := tfconfig.LoadModule("testdata/typed")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	table, err := tfdoc.VarsTable(module, "", "", tfdoc.Options{Sort: "name", Description: "full", Format: "markdown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := tfdoc.DocData{TerraformVarsTable: table}
+	tmpl := template.Must(template.New("README").Parse(`{{ .Extra.MonthlyCost }} for {{ .Extra.Resources }} resources, owned by {{ .Extra.Owner }}
+{{ .TerraformVarsTable }}`))
+
+	costs := func(m *tfconfig.Module) (map[string]interface{}, error) {
+		return map[string]interface{}{"MonthlyCost": "$12.40", "Resources": len(m.ManagedResources)}, nil
+	}
+	owner := func(*tfconfig.Module) (map[string]interface{}, error) {
+		return map[string]interface{}{"Owner": "platform"}, nil
+	}
+	unpriced := func(*tfconfig.Module) (map[string]interface{}, error) {
+		return nil, errors.New("no prices for the region")
+	}
+	values := func(v map[string]interface{}) tfdoc.ExtraDataFunc {
+		return func(*tfconfig.Module) (map[string]interface{}, error) { return v, nil }
+	}
+	tests := []struct {
+		name    string
+		opts    tfdoc.RenderOptions
+		want    string
+		wantErr string
+	}{
+		{"every function", tfdoc.RenderOptions{ExtraData: []tfdoc.ExtraDataFunc{costs, owner}}, "$12.40 for 1 resources, owned by platform", ""},
+		{"a failing function", tfdoc.RenderOptions{ExtraData: []tfdoc.ExtraDataFunc{unpriced, owner}}, "<no value> for <no value> resources, owned by platform", ""},
+		{"a failing function in strict mode", tfdoc.RenderOptions{ExtraData: []tfdoc.ExtraDataFunc{owner, unpriced}, Strict: true}, "", "extra data function 2: no prices for the region"},
+		{"a built-in field name", tfdoc.RenderOptions{ExtraData: []tfdoc.ExtraDataFunc{values(map[string]interface{}{"Owners": "x"})}}, "", `extra data function 1 sets "Owners", which is the name of a built-in template field`},
+		{"a key set twice", tfdoc.RenderOptions{ExtraData: []tfdoc.ExtraDataFunc{costs, values(map[string]interface{}{"MonthlyCost": "$0"})}}, "", `extra data function 2 sets "MonthlyCost", which an earlier function already set`},
+		{"a render over the size limit", tfdoc.RenderOptions{ExtraData: []tfdoc.ExtraDataFunc{costs, owner}, MaxSize: 10}, "", "output is over the limit of 10 bytes"},
+	}
+	tfdoc.SetWarningOutput(ioutil.Discard)
+	defer tfdoc.SetWarningOutput(os.Stderr)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := tfdoc.Render(context.Background(), tmpl, module, data, &out, test.opts)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); !strings.HasPrefix(got, test.want+"\n") || !strings.Contains(got, table) {
+				t.Errorf("rendered:\n%s\nwant %q followed by the variables table", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tfdoc
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// values returns an ExtraDataFunc giving v
+func values(v map[string]interface{}) ExtraDataFunc {
+	return func(*tfconfig.Module) (map[string]interface{}, error) {
+		return v, nil
+	}
+}
+
+func TestCollectExtraData(t *testing.T) {
+	tests := []struct {
+		name    string
+		funcs   []ExtraDataFunc
+		strict  bool
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"no functions", nil, false, map[string]interface{}{}, ""},
+		{
+			"values merged from every function",
+			[]ExtraDataFunc{
+				values(map[string]interface{}{"MonthlyCost": 12.5}),
+				func(m *tfconfig.Module) (map[string]interface{}, error) {
+					return map[string]interface{}{"Resources": len(m.ManagedResources)}, nil
+				},
+			},
+			false, map[string]interface{}{"MonthlyCost": 12.5, "Resources": 1}, "",
+		},
+		{
+			"a function failing",
+			[]ExtraDataFunc{
+				values(map[string]interface{}{"Team": "a"}),
+				func(*tfconfig.Module) (map[string]interface{}, error) { return nil, errors.New("no pricing API") },
+			},
+			false, map[string]interface{}{"Team": "a"}, "",
+		},
+		{
+			"a function failing in strict mode",
+			[]ExtraDataFunc{
+				values(nil),
+				func(*tfconfig.Module) (map[string]interface{}, error) { return nil, errors.New("no pricing API") },
+			},
+			true, nil, "extra data function 2: no pricing API",
+		},
+		{
+			"a built-in field name",
+			[]ExtraDataFunc{values(map[string]interface{}{"TerraformVarsTable": "x"})},
+			false, nil, `extra data function 1 sets "TerraformVarsTable", which is the name of a built-in template field`,
+		},
+		{
+			"a key set twice",
+			[]ExtraDataFunc{values(map[string]interface{}{"Team": "a"}), values(map[string]interface{}{"Team": "b"})},
+			false, nil, `extra data function 2 sets "Team", which an earlier function already set`,
+		},
+	}
+	module := &tfconfig.Module{ManagedResources: map[string]*tfconfig.Resource{"aws_s3_bucket.logs": {}}}
+	var warnings bytes.Buffer
+	SetWarningOutput(&warnings)
+	defer SetWarningOutput(os.Stderr)
+	for _, test := range tests {
+		got, err := CollectExtraData(module, RenderOptions{ExtraData: test.funcs, Strict: test.strict})
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCollectExtraDataWarns(t *testing.T) {
+	var warnings bytes.Buffer
+	SetWarningOutput(&warnings)
+	defer SetWarningOutput(os.Stderr)
+	failing := func(*tfconfig.Module) (map[string]interface{}, error) { return nil, errors.New("no pricing API") }
+	if _, err := CollectExtraData(&tfconfig.Module{}, RenderOptions{ExtraData: []ExtraDataFunc{failing}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "leaving out the values of extra data function 1: no pricing API"; !strings.Contains(warnings.String(), want) {
+		t.Errorf("warned %q, want it to contain %q", warnings.String(), want)
+	}
+}
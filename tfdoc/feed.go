@@ -0,0 +1,246 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// ValidFeedFormats are the feeds the Feed action can write
+var ValidFeedFormats = []string{
+	"json",
+	"atom",
+}
+
+// InterfaceChange is how a module's inputs and outputs changed since a ref, each list in natural order
+type InterfaceChange struct {
+	InputsAdded    []string
+	InputsRemoved  []string
+	InputsChanged  []string // Type, default or whether it's required
+	OutputsAdded   []string
+	OutputsRemoved []string
+}
+
+// Empty reports whether nothing changed
+func (c InterfaceChange) Empty() bool {
+	return len(c.InputsAdded)+len(c.InputsRemoved)+len(c.InputsChanged)+len(c.OutputsAdded)+len(c.OutputsRemoved) == 0
+}
+
+// Summary lists what changed, a sentence for each kind of change, e.g. "Inputs added: name, tags."
+func (c InterfaceChange) Summary() string {
+	sentences := []string{}
+	for _, part := range []struct {
+		Label string
+		Names []string
+	}{
+		{"Inputs added", c.InputsAdded},
+		{"Inputs removed", c.InputsRemoved},
+		{"Inputs changed", c.InputsChanged},
+		{"Outputs added", c.OutputsAdded},
+		{"Outputs removed", c.OutputsRemoved},
+	} {
+		if len(part.Names) > 0 {
+			sentences = append(sentences, fmt.Sprintf("%s: %s.", part.Label, strings.Join(part.Names, ", ")))
+		}
+	}
+	return strings.Join(sentences, " ")
+}
+
+// naturalSorted sorts names in natural order and returns them
+func naturalSorted(names []string) []string {
+	sort.Slice(names, func(i, j int) bool { return naturalLess(names[i], names[j]) })
+	return names
+}
+
+// DiffInterface compares the variables and outputs of module with those of base, the module at an earlier ref
+func DiffInterface(base, module *tfconfig.Module) InterfaceChange {
+	c := InterfaceChange{}
+	for name, v := range module.Variables {
+		old, ok := base.Variables[name]
+		switch {
+		case !ok:
+			c.InputsAdded = append(c.InputsAdded, name)
+		case variableChanged(old, v):
+			c.InputsChanged = append(c.InputsChanged, name)
+		}
+	}
+	for name := range base.Variables {
+		if _, ok := module.Variables[name]; !ok {
+			c.InputsRemoved = append(c.InputsRemoved, name)
+		}
+	}
+	for name := range module.Outputs {
+		if _, ok := base.Outputs[name]; !ok {
+			c.OutputsAdded = append(c.OutputsAdded, name)
+		}
+	}
+	for name := range base.Outputs {
+		if _, ok := module.Outputs[name]; !ok {
+			c.OutputsRemoved = append(c.OutputsRemoved, name)
+		}
+	}
+	naturalSorted(c.InputsAdded)
+	naturalSorted(c.InputsRemoved)
+	naturalSorted(c.InputsChanged)
+	naturalSorted(c.OutputsAdded)
+	naturalSorted(c.OutputsRemoved)
+	return c
+}
+
+// FeedEntry is a module whose interface changed since the feed's ref
+type FeedEntry struct {
+	ID      string // The module's path and the commit it changed by, so the entry stays the same between runs
+	Module  string
+	Url     string
+	Updated string // RFC 3339, when the commit was made
+	Change  InterfaceChange
+}
+
+// FindFeedEntries diffs the module at root, or each module of a collection at root, against the ref, giving an
+// entry for each whose inputs or outputs changed. Modules that didn't change have no entry. Links to the modules
+// are rewritten by rewrites.
+func FindFeedEntries(root, ref, baseUrl, modulePath string, rewrites []URLRewrite) ([]FeedEntry, error) {
+	dirs := []string{root}
+	collection, err := IsCollection(root)
+	if err != nil {
+		return nil, err
+	}
+	if collection {
+		if dirs, err = FindChildModules(root); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := []FeedEntry{}
+	for _, dir := range dirs {
+		module, diags := tfconfig.LoadModule(dir)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%s: %s", dir, diags.Error())
+		}
+		// A module that didn't exist at the ref loads empty, so everything it has is added
+		base, err := LoadModuleAt(dir, ref)
+		if err != nil {
+			return nil, err
+		}
+		change := DiffInterface(base, module)
+		if change.Empty() {
+			continue
+		}
+
+		log, err := gitOutput(dir, "log", "-1", "--format=%H %cI", "--", ".")
+		if err != nil {
+			return nil, err
+		}
+		commit := strings.Fields(string(log))
+		if len(commit) != 2 {
+			return nil, fmt.Errorf("%s has no commits", dir)
+		}
+		docsPath := modulePath
+		if collection {
+			docsPath = path.Join(modulePath, CollectionModulesDir, tfpath.Base(dir))
+		}
+		// Without -modulePath a module is known by its directory's name
+		name := docsPath
+		if name == "" {
+			name = tfpath.Base(dir)
+			if abs, err := filepath.Abs(dir); err == nil {
+				name = tfpath.Base(abs)
+			}
+		}
+		entries = append(entries, FeedEntry{
+			ID:      fmt.Sprintf("urn:tf2doc:%s@%s", name, commit[0]),
+			Module:  name,
+			Url:     DocsUrl(baseUrl, docsPath, rewrites),
+			Updated: commit[1],
+			Change:  change,
+		})
+	}
+	return entries, nil
+}
+
+// jsonFeed is a JSON Feed 1.1 document, https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageUrl string         `json:"home_page_url,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID           string `json:"id"`
+	Url          string `json:"url,omitempty"`
+	Title        string `json:"title"`
+	ContentText  string `json:"content_text"`
+	DateModified string `json:"date_modified"`
+}
+
+// atomFeed is an Atom document, RFC 4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Summary string    `xml:"summary"`
+}
+
+// Feed writes entries as a feed in format, json or atom, newest first, titled after the ref they changed
+// since
+func Feed(entries []FeedEntry, ref, baseUrl, format string) (string, error) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Updated != entries[j].Updated {
+			return entries[i].Updated > entries[j].Updated
+		}
+		return naturalLess(entries[i].Module, entries[j].Module)
+	})
+	title := fmt.Sprintf("Module interface changes since %s", ref)
+
+	switch format {
+	case "json":
+		feed := jsonFeed{Version: "https://jsonfeed.org/version/1.1", Title: title, HomePageUrl: baseUrl, Items: []jsonFeedItem{}}
+		for _, e := range entries {
+			feed.Items = append(feed.Items, jsonFeedItem{ID: e.ID, Url: e.Url, Title: e.Module, ContentText: e.Change.Summary(), DateModified: e.Updated})
+		}
+		b, err := json.MarshalIndent(feed, "", "  ")
+		return string(b), err
+	case "atom":
+		// The feed is as new as its newest entry, which keeps it the same while nothing changes
+		feed := atomFeed{ID: "urn:tf2doc:feed:" + ref, Title: title, Updated: "1970-01-01T00:00:00Z"}
+		if baseUrl != "" {
+			feed.Link = &atomLink{Href: baseUrl}
+		}
+		if len(entries) > 0 {
+			feed.Updated = entries[0].Updated
+		}
+		for _, e := range entries {
+			entry := atomEntry{ID: e.ID, Title: e.Module, Updated: e.Updated, Summary: e.Change.Summary()}
+			if e.Url != "" {
+				entry.Link = &atomLink{Href: e.Url}
+			}
+			feed.Entries = append(feed.Entries, entry)
+		}
+		b, err := xml.MarshalIndent(feed, "", "  ")
+		return xml.Header + string(b), err
+	}
+	return "", fmt.Errorf("feed-format %s is not one of: %s", format, ValidFeedFormats)
+}
@@ -0,0 +1,136 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func TestDiffInterface(t *testing.T) {
+	base := &tfconfig.Module{
+		Variables: map[string]*tfconfig.Variable{
+			"same":     {Name: "same", Type: "string"},
+			"removed":  {Name: "removed", Type: "string"},
+			"typed":    {Name: "typed", Type: "string"},
+			"required": {Name: "required", Type: "string", Default: "a"},
+		},
+		Outputs: map[string]*tfconfig.Output{"arn": {Name: "arn"}, "old": {Name: "old"}},
+	}
+	module := &tfconfig.Module{
+		Variables: map[string]*tfconfig.Variable{
+			"same":     {Name: "same", Type: "string"},
+			"typed":    {Name: "typed", Type: "number"},
+			"required": {Name: "required", Type: "string", Required: true},
+			"name10":   {Name: "name10", Type: "string"},
+			"name2":    {Name: "name2", Type: "string"},
+		},
+		Outputs: map[string]*tfconfig.Output{"arn": {Name: "arn"}, "id": {Name: "id"}},
+	}
+	want := InterfaceChange{
+		InputsAdded:    []string{"name2", "name10"},
+		InputsRemoved:  []string{"removed"},
+		InputsChanged:  []string{"required", "typed"},
+		OutputsAdded:   []string{"id"},
+		OutputsRemoved: []string{"old"},
+	}
+	got := DiffInterface(base, module)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if want := "Inputs added: name2, name10. Inputs removed: removed. Inputs changed: required, typed. Outputs added: id. Outputs removed: old."; got.Summary() != want {
+		t.Errorf("got summary %q, want %q", got.Summary(), want)
+	}
+	if !DiffInterface(base, base).Empty() {
+		t.Error("a module compared with itself changed")
+	}
+}
+
+func TestFeed(t *testing.T) {
+	entries := []FeedEntry{
+		{ID: "urn:tf2doc:network@1", Module: "network", Updated: "2024-01-01T00:00:00Z", Change: InterfaceChange{InputsAdded: []string{"cidr"}}},
+		{ID: "urn:tf2doc:storage@2", Module: "storage", Url: "https://example.com/storage", Updated: "2024-02-01T00:00:00Z", Change: InterfaceChange{OutputsRemoved: []string{"arn"}}},
+	}
+
+	got, err := Feed(entries, "v1.0.0", "https://example.com", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Module interface changes since v1.0.0",
+  "home_page_url": "https://example.com",
+  "items": [
+    {
+      "id": "urn:tf2doc:storage@2",
+      "url": "https://example.com/storage",
+      "title": "storage",
+      "content_text": "Outputs removed: arn.",
+      "date_modified": "2024-02-01T00:00:00Z"
+    },
+    {
+      "id": "urn:tf2doc:network@1",
+      "title": "network",
+      "content_text": "Inputs added: cidr.",
+      "date_modified": "2024-01-01T00:00:00Z"
+    }
+  ]
+}`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = Feed(entries, "v1.0.0", "", "atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		"<updated>2024-02-01T00:00:00Z</updated>",
+		`<link href="https://example.com/storage"></link>`,
+		"<summary>Inputs added: cidr.</summary>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got:\n%s\nwant it to contain %s", got, want)
+		}
+	}
+
+	if _, err := Feed(entries, "v1.0.0", "", "rss"); err == nil {
+		t.Error("got no error for an unknown format")
+	}
+}
+
+func TestFindFeedEntries(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.tf")
+	if err := ioutil.WriteFile(file, []byte("variable \"name\" {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommitAll(t, dir)
+
+	entries, err := FindFeedEntries(dir, "HEAD", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("got entries %+v for a module that didn't change", entries)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("variable \"name\" {}\noutput \"id\" {\n  value = var.name\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = FindFeedEntries(dir, "HEAD", "https://example.com", "modules/app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got entries %+v, want one", entries)
+	}
+	e := entries[0]
+	if e.Module != "modules/app" || !strings.HasPrefix(e.ID, "urn:tf2doc:modules/app@") || !reflect.DeepEqual(e.Change.OutputsAdded, []string{"id"}) {
+		t.Errorf("got entry %+v", e)
+	}
+}
@@ -0,0 +1,275 @@
+package tfdoc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+const (
+	// lockWait is how long a write waits for another run to release the file
+	lockWait = 30 * time.Second
+	// lockStale is how old a lock file must be before it's assumed its run died and it's removed. A lock
+	// is held only while one file is compared and written, and this is well under lockWait, so a run waiting
+	// on the lock of a run that died takes it over rather than timing out.
+	lockStale = 10 * time.Second
+)
+
+var ValidDryRunFormats = []string{
+	"text",
+	"json",
+}
+
+// FileChange records what a run did, or would do in a dry run, to one file
+type FileChange struct {
+	Path      string `json:"path"`
+	Action    string `json:"action"` // create, update, unchanged or prune
+	Bytes     int    `json:"bytes"`
+	ByteDelta int    `json:"byte_delta"`
+	Sha256    string `json:"-"` // Of the content written, empty when pruned
+}
+
+// FileWriter performs every file write and removal for a run, recording each change.
+// In dry run mode the changes are only recorded.
+//
+// Several runs may share a checkout, so each file is locked while it's compared and written, and content
+// is written to a temp file that's renamed into place. Only paths inside Allowed may be touched.
+type FileWriter struct {
+	DryRun  bool
+	Allowed []string // Directories, or single files, the run may write to
+	Changes []FileChange
+}
+
+// allow reports an error unless filePath is inside one of w.Allowed
+func (w *FileWriter) allow(filePath string) error {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	for _, a := range w.Allowed {
+		allowed, err := filepath.Abs(a)
+		if err != nil {
+			return err
+		}
+		if rel, err := tfpath.Rel(allowed, abs); err == nil && !tfpath.Host.LeavesDir(rel) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to write %s: it is outside the module directory and the configured outputs %s", filePath, w.Allowed)
+}
+
+// lock takes filePath.lock, waiting for other runs and removing locks left by runs that died. The
+// returned function releases it.
+func lock(filePath string) (func(), error) {
+	lockPath := filePath + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStale {
+			stderr.Printf("removing stale lock %s", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s, remove it if no other run is writing %s", lockPath, filePath)
+		}
+		time.Sleep(time.Duration(50+rand.Intn(100)) * time.Millisecond)
+	}
+}
+
+// ReplaceFile writes content to a temp file beside filePath, named so concurrent runs can't collide,
+// then renames it over filePath so readers never see a partial file. An existing file keeps its mode.
+func ReplaceFile(filePath string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp := fmt.Sprintf("%s.%d-%d.tmp", filePath, os.Getpid(), rand.Int63())
+	if err := ioutil.WriteFile(tmp, content, mode); err != nil {
+		return err
+	}
+	// The umask applies to a new file's mode, and the mode is kept whatever it is
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filePath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// CheckWritable reports an error if filePath couldn't be written, opening it if it exists or creating and
+// removing a temp file in the nearest directory of it that does, so a run fails before doing any work
+func CheckWritable(filePath string) error {
+	if info, err := os.Stat(filePath); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", filePath)
+		}
+		f, err := os.OpenFile(filePath, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	dir := filepath.Dir(filePath)
+	for {
+		if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	f, err := ioutil.TempFile(dir, ".tf2doc-check")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// WriteFile writes content to filePath, creating parent directories, unless the file already holds exactly that content.
+// Line endings follow the file being replaced, or .editorconfig for new files, see fitLineEndings.
+func (w *FileWriter) WriteFile(filePath string, content []byte) error {
+	return w.UpdateFile(filePath, func([]byte, bool) ([]byte, error) {
+		return content, nil
+	})
+}
+
+// UpdateFile writes what update makes of filePath's content as WriteFile does, holding the file's lock from
+// reading it to writing it so another run can't change it in between. update is told whether the file
+// exists, and an error from it leaves the file as it was.
+func (w *FileWriter) UpdateFile(filePath string, update func(old []byte, exists bool) ([]byte, error)) error {
+	if err := w.allow(filePath); err != nil {
+		return err
+	}
+	if !w.DryRun {
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		unlock, err := lock(filePath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	old, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+	content, err := update(old, exists)
+	if err != nil {
+		return err
+	}
+	if content, err = fitLineEndings(filePath, content, old, exists); err != nil {
+		return err
+	}
+	change := FileChange{Path: filePath, Bytes: len(content), Sha256: fmt.Sprintf("%x", sha256.Sum256(content))}
+	switch {
+	case !exists:
+		change.Action = "create"
+		change.ByteDelta = len(content)
+	case bytes.Equal(old, content):
+		change.Action = "unchanged"
+	default:
+		change.Action = "update"
+		change.ByteDelta = len(content) - len(old)
+	}
+	w.Changes = append(w.Changes, change)
+
+	if w.DryRun || change.Action == "unchanged" {
+		return nil
+	}
+	return ReplaceFile(filePath, content)
+}
+
+// Remove deletes a stale file
+func (w *FileWriter) Remove(filePath string) error {
+	if err := w.allow(filePath); err != nil {
+		return err
+	}
+	if !w.DryRun {
+		unlock, err := lock(filePath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	w.Changes = append(w.Changes, FileChange{Path: filePath, Action: "prune", ByteDelta: -int(info.Size())})
+	if w.DryRun {
+		return nil
+	}
+	return os.Remove(filePath)
+}
+
+// Written returns the files written so far, whether or not their content changed
+func (w *FileWriter) Written() []string {
+	paths := []string{}
+	for _, c := range w.Changes {
+		if c.Action != "prune" {
+			paths = append(paths, c.Path)
+		}
+	}
+	return paths
+}
+
+// Changed reports whether any file was, or in a dry run would be, created, updated or pruned
+func (w *FileWriter) Changed() bool {
+	for _, c := range w.Changes {
+		if c.Action != "unchanged" {
+			return true
+		}
+	}
+	return false
+}
+
+// Manifest describes the recorded changes as aligned text or JSON
+func (w *FileWriter) Manifest(format string) (string, error) {
+	if format == "json" {
+		changes := w.Changes
+		if changes == nil {
+			changes = []FileChange{}
+		}
+		b, err := json.MarshalIndent(map[string]interface{}{
+			"changed": w.Changed(),
+			"files":   changes,
+		}, "", "  ")
+		return string(b), err
+	}
+
+	lines := []string{}
+	for _, c := range w.Changes {
+		line := fmt.Sprintf("%-9s %s", c.Action, c.Path)
+		if c.Action != "unchanged" {
+			line += fmt.Sprintf(" (%+d bytes)", c.ByteDelta)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "no files would be written")
+	}
+	return strings.Join(lines, "\n"), nil
+}
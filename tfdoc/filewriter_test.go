@@ -0,0 +1,74 @@
+package tfdoc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockTakesOverStaleLocks(t *testing.T) {
+	if lockStale >= lockWait {
+		t.Fatalf("a run waits %v for a lock, but a lock is only stale after %v", lockWait, lockStale)
+	}
+	var warnings bytes.Buffer
+	SetWarningOutput(&warnings)
+	defer SetWarningOutput(os.Stderr)
+
+	file := filepath.Join(t.TempDir(), "README.md")
+	if err := ioutil.WriteFile(file+".lock", []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-lockStale - time.Second)
+	if err := os.Chtimes(file+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	unlock, err := lock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to take over the stale lock", elapsed)
+	}
+	if _, err := os.Stat(file + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("the lock wasn't released: %v", err)
+	}
+}
+
+func TestReplaceFileKeepsTheMode(t *testing.T) {
+	dir := t.TempDir()
+	for _, mode := range []os.FileMode{0600, 0755} {
+		file := filepath.Join(dir, "README.md")
+		if err := ioutil.WriteFile(file, []byte("old\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(file, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReplaceFile(file, []byte("new\n")); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("replacing a file with mode %v left mode %v", mode, info.Mode().Perm())
+		}
+	}
+	created := filepath.Join(dir, "NEW.md")
+	if err := ReplaceFile(created, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("a new file got mode %v, want 0644", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,65 @@
+package tfdoc
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// rScpRemote matches the scp-like syntax of ssh remotes, e.g. git@github.com:org/repo.git
+var rScpRemote = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// RepoWebUrl converts a git remote URL, https or ssh, to the web URL of the repository's files at ref on
+// GitHub, GitLab or Bitbucket, which forge being told by the host's name
+func RepoWebUrl(remote, ref string) (string, error) {
+	remote = strings.TrimSpace(remote)
+	var host, repoPath string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, repoPath = u.Hostname(), u.Path
+	} else if m := rScpRemote.FindStringSubmatch(remote); m != nil {
+		host, repoPath = m[1], m[2]
+	} else {
+		return "", fmt.Errorf("can't read the remote URL %q", remote)
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if repoPath == "" {
+		return "", fmt.Errorf("the remote URL %q has no repository path", remote)
+	}
+
+	base := fmt.Sprintf("https://%s/%s", host, repoPath)
+	switch {
+	case strings.Contains(host, "github"):
+		return fmt.Sprintf("%s/blob/%s", base, ref), nil
+	case strings.Contains(host, "gitlab"):
+		return fmt.Sprintf("%s/-/blob/%s", base, ref), nil
+	case strings.Contains(host, "bitbucket"):
+		return fmt.Sprintf("%s/src/%s", base, ref), nil
+	}
+	return "", fmt.Errorf("%s isn't GitHub, GitLab or Bitbucket", host)
+}
+
+// DetectRepoUrl works out the -repoUrl and -modulePath of the module in dir from its repository's origin
+// remote: the web URL of the files on the checked out branch, or commit when there's none, and the module's
+// path within the repository.
+func DetectRepoUrl(dir string) (repoUrl, modulePath string, err error) {
+	remote, err := gitOutput(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", "", err
+	}
+	ref, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(string(ref)) == "HEAD" {
+		if ref, err = gitOutput(dir, "rev-parse", "HEAD"); err != nil {
+			return "", "", err
+		}
+	}
+	prefix, err := gitOutput(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", "", err
+	}
+	repoUrl, err = RepoWebUrl(string(remote), strings.TrimSpace(string(ref)))
+	return repoUrl, strings.Trim(strings.TrimSpace(string(prefix)), "/"), err
+}
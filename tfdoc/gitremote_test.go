@@ -0,0 +1,69 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoWebUrl(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"https://github.com/acme/stack.git", "https://github.com/acme/stack/blob/main"},
+		{"https://github.com/acme/stack", "https://github.com/acme/stack/blob/main"},
+		{"git@github.com:acme/stack.git\n", "https://github.com/acme/stack/blob/main"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/stack.git", "https://gitlab.example.com/group/sub/stack/-/blob/main"},
+		{"git@bitbucket.org:acme/stack.git", "https://bitbucket.org/acme/stack/src/main"},
+	}
+	for _, test := range tests {
+		got, err := RepoWebUrl(test.remote, "main")
+		if err != nil {
+			t.Errorf("%s: %v", test.remote, err)
+		} else if got != test.want {
+			t.Errorf("got %s for %s, want %s", got, test.remote, test.want)
+		}
+	}
+
+	for _, remote := range []string{"https://git.example.com/acme/stack.git", "https://github.com/", "/srv/git/stack.git"} {
+		if got, err := RepoWebUrl(remote, "main"); err == nil {
+			t.Errorf("got %s for %s, want an error", got, remote)
+		}
+	}
+}
+
+func TestDetectRepoUrl(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "modules", "vpc")
+	if err := os.MkdirAll(module, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := DetectRepoUrl(module); err == nil {
+		t.Error("got no error outside a repository")
+	}
+	if err := ioutil.WriteFile(filepath.Join(module, "main.tf"), []byte("variable \"name\" {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommitAll(t, dir)
+	for _, args := range [][]string{
+		{"checkout", "-q", "-b", "release"},
+		{"remote", "add", "origin", "git@github.com:acme/stack.git"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	repoUrl, modulePath, err := DetectRepoUrl(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repoUrl != "https://github.com/acme/stack/blob/release" || modulePath != "modules/vpc" {
+		t.Errorf("got %s and %s, want https://github.com/acme/stack/blob/release and modules/vpc", repoUrl, modulePath)
+	}
+}
@@ -0,0 +1,81 @@
+package tfdoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ValidGroupBys = []string{
+	"prefix",
+}
+
+// GroupedTables are the tables -group-by may split into sub-sections
+var GroupedTables = []string{
+	"vars",
+	"outputs",
+}
+
+// otherGroup holds the items with no prefix, or whose prefix is shared by too few items
+const otherGroup = "other"
+
+// GroupOptions controls how -group-by splits a table into one sub-section per group
+type GroupOptions struct {
+	By        string          // One of ValidGroupBys, or empty to not group
+	Tables    map[string]bool // The GroupedTables to split
+	Delimiter string          // The prefix is everything before the first Delimiter
+	MinSize   int             // Groups with fewer items go into otherGroup
+}
+
+// ParseGroupTables parses the comma separated -group-tables value
+func ParseGroupTables(value string) (map[string]bool, error) {
+	tables := make(map[string]bool)
+	for _, table := range strings.Split(value, ",") {
+		table = strings.TrimSpace(table)
+		if !StringInSlice(table, GroupedTables) {
+			return nil, fmt.Errorf("group table %q is not one of: %s", table, GroupedTables)
+		}
+		tables[table] = true
+	}
+	return tables, nil
+}
+
+// groupByPrefix splits objs by the prefix of their names, which are the map keys, and returns the
+// group names in order with otherGroup last
+func groupByPrefix(objs map[string]TableObject, opts GroupOptions) ([]string, map[string]map[string]TableObject) {
+	prefixOf := func(name string) string {
+		if i := strings.Index(name, opts.Delimiter); i > 0 {
+			return name[:i]
+		}
+		return otherGroup
+	}
+
+	sizes := make(map[string]int)
+	for key := range objs {
+		sizes[prefixOf(key)]++
+	}
+
+	groups := make(map[string]map[string]TableObject)
+	for key, o := range objs {
+		group := prefixOf(key)
+		if sizes[group] < opts.MinSize {
+			group = otherGroup
+		}
+		if groups[group] == nil {
+			groups[group] = make(map[string]TableObject)
+		}
+		groups[group][key] = o
+	}
+
+	names := []string{}
+	for group := range groups {
+		if group != otherGroup {
+			names = append(names, group)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[otherGroup]; ok {
+		names = append(names, otherGroup)
+	}
+	return names, groups
+}
@@ -0,0 +1,79 @@
+package tfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ValidHeadingIds = []string{
+	"off",
+	"explicit",
+}
+
+// TocHeading is the heading BuildMarkdownToc puts above the table of contents
+const TocHeading = "Table of Contents\n================="
+
+var rExplicitId = regexp.MustCompile(`\s*\{#([^}\s]+)\}\s*$`)
+
+// headingIDs gives each heading the anchor GitHub would, de-duplicated the same way as BuildMarkdownToc.
+// A heading with an explicit {#id} keeps it.
+func headingIDs(headings []markdownHeading) []string {
+	seen := make(map[string]int)
+	ids := []string{}
+	for _, h := range headings {
+		if m := rExplicitId.FindStringSubmatch(h.Title); m != nil {
+			ids = append(ids, m[1])
+			continue
+		}
+		id := slugify(h.Title)
+		if n, ok := seen[id]; ok {
+			seen[id]++
+			id = fmt.Sprintf("%s-%d", id, n)
+		} else {
+			seen[id] = 1
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// AddHeadingIDs appends {#id} attributes to every heading in the document outside code fences, for
+// renderers whose own anchors differ from GitHub's
+func AddHeadingIDs(d []byte) []byte {
+	lines := strings.Split(string(d), "\n")
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimSuffix(line, "\r")
+	}
+	headings := findMarkdownHeadings(trimmed)
+	ids := headingIDs(headings)
+	for i, h := range headings {
+		if rExplicitId.MatchString(h.Title) {
+			continue
+		}
+		line := trimmed[h.Start]
+		if h.Start == h.End {
+			line = fmt.Sprintf("%s %s", strings.Repeat("#", h.Level), h.Title)
+		}
+		lines[h.Start] = fmt.Sprintf("%s {#%s}%s", strings.TrimRight(line, " \t"), ids[i], lines[h.Start][len(trimmed[h.Start]):])
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// ExplicitTocEntries lists the headings of a rendered document for its table of contents, with the IDs
+// AddHeadingIDs will give them, down to depth levels. The table of contents' own heading is left out.
+func ExplicitTocEntries(d []byte, depth int) []TocEntry {
+	entries := []TocEntry{}
+	lines := strings.Split(strings.Replace(string(d), "\r\n", "\n", -1), "\n")
+	headings := findMarkdownHeadings(lines)
+	ids := headingIDs(headings)
+	for i, h := range headings {
+		title := rExplicitId.ReplaceAllString(h.Title, "")
+		if (depth > 0 && h.Level > depth) || title == strings.Split(TocHeading, "\n")[0] {
+			continue
+		}
+		entries = append(entries, TocEntry{Title: title, Anchor: ids[i], Level: h.Level})
+	}
+	return entries
+}
@@ -0,0 +1,158 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ValidFormats = []string{
+	"markdown",
+	"html",
+	"json",
+	"asciidoc",
+	"yaml",
+	"rst",
+	"csv",
+	"confluence",
+	"mediawiki",
+	"org",
+	"jira",
+	"text",
+}
+
+// singleTableFormats are the formats whose output is one table that no other can follow
+var singleTableFormats = []string{"json", "yaml", "csv"}
+
+// rCellMarkdown matches the markdown the tables put in cells: links and images, with an optional title,
+// and code spans
+var rCellMarkdown = regexp.MustCompile(`(!?)\[((?:[^\]\\]|\\.)*)\]\(([^ )]+)(?: "((?:[^"\\]|\\.)*)")?\)` + "|`([^`]*)`")
+
+// markdownUnescape removes the backslashes that escape characters in link text and titles
+var markdownUnescape = strings.NewReplacer(`\[`, "[", `\]`, "]", `\"`, `"`)
+
+// convertCellMarkdown rewrites a table cell's links, images and code spans with link and code, passing the
+// text between them through escape. Link text and titles are unescaped first.
+func convertCellMarkdown(cell string, escape func(string) string, link func(image bool, text, src, title string) string, code func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range rCellMarkdown.FindAllStringSubmatchIndex(cell, -1) {
+		b.WriteString(escape(cell[last:m[0]]))
+		last = m[1]
+		if m[10] >= 0 {
+			b.WriteString(code(cell[m[10]:m[11]]))
+			continue
+		}
+		title := ""
+		if m[8] >= 0 {
+			title = markdownUnescape.Replace(cell[m[8]:m[9]])
+		}
+		b.WriteString(link(m[3] > m[2], markdownUnescape.Replace(cell[m[4]:m[5]]), cell[m[6]:m[7]], title))
+	}
+	b.WriteString(escape(cell[last:]))
+	return b.String()
+}
+
+// htmlCell turns a table cell's links, images and code spans into HTML and escapes the rest, keeping line
+// breaks as <br> as MarkdownTableCellEscape does. Link titles are also given as aria-labels for screen readers.
+func htmlCell(cell string) string {
+	converted := convertCellMarkdown(cell, html.EscapeString, func(image bool, text, src, title string) string {
+		text, src, title = html.EscapeString(text), html.EscapeString(src), html.EscapeString(title)
+		switch {
+		case image:
+			return fmt.Sprintf(`<img src="%s" alt="%s">`, src, text)
+		case title != "":
+			return fmt.Sprintf(`<a href="%s" title="%s" aria-label="%s">%s</a>`, src, title, title, text)
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, src, text)
+	}, func(c string) string {
+		return fmt.Sprintf("<code>%s</code>", html.EscapeString(c))
+	})
+	return rCellNewline.ReplaceAllString(converted, "<br>")
+}
+
+// HtmlTable renders a table as HTML, for cells that markdown tables can't hold
+func HtmlTable(headings []string, data [][]string) string {
+	heading := "<tr>"
+	for _, h := range headings {
+		heading += fmt.Sprintf("<th>%s</th>", html.EscapeString(h))
+	}
+	lines := []string{"<table>", "<thead>", heading + "</tr>", "</thead>", "<tbody>"}
+	for _, d := range data {
+		row := "<tr>"
+		for _, val := range d {
+			row += fmt.Sprintf("<td>%s</td>", htmlCell(val))
+		}
+		lines = append(lines, row+"</tr>")
+	}
+	lines = append(lines, "</tbody>", "</table>")
+	return strings.Join(lines, "\n")
+}
+
+// JsonTable renders a table as a JSON array with an object for each row, keyed by heading
+func JsonTable(headings []string, data [][]string) (string, error) {
+	rows := []map[string]string{}
+	for _, d := range data {
+		row := make(map[string]string)
+		for i, val := range d {
+			row[headings[i]] = val
+		}
+		rows = append(rows, row)
+	}
+	b, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// YamlTable renders a table as a YAML sequence with a mapping for each row, keyed by heading
+func YamlTable(headings []string, data [][]string) (string, error) {
+	rows := []map[string]string{}
+	for _, d := range data {
+		row := make(map[string]string)
+		for i, val := range d {
+			row[headings[i]] = val
+		}
+		rows = append(rows, row)
+	}
+	b, err := yaml.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// renderTable renders a table in opts.Format, one of ValidFormats. The tables of TableObjects are written
+// as JSON by collectTable instead.
+func renderTable(opts Options, headings, lengths []string, data [][]string) (string, error) {
+	switch opts.Format {
+	case "html":
+		return HtmlTable(headings, data), nil
+	case "json":
+		return JsonTable(headings, data)
+	case "yaml":
+		return YamlTable(headings, data)
+	case "asciidoc":
+		return AsciiDocTable(headings, data), nil
+	case "rst":
+		return RstTable(headings, data), nil
+	case "csv":
+		return CsvTable(headings, data)
+	case "confluence":
+		return ConfluenceTable(headings, data), nil
+	case "mediawiki":
+		return MediaWikiTable(headings, data), nil
+	case "org":
+		return OrgTable(headings, data), nil
+	case "jira":
+		return JiraTable(headings, data), nil
+	case "text":
+		return TextTable(headings, data, opts.TextWidth), nil
+	}
+	return MarkdownTable(headings, lengths, data), nil
+}
@@ -0,0 +1,122 @@
+package tfdoc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYamlFormatMatchesJson(t *testing.T) {
+	module := loadTestModule(t, "inferred")
+	var fromJson, fromYaml []TableRow
+	jsonTable, err := VarsTable(module, "", "", Options{Sort: "name", Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlTable, err := VarsTable(module, "", "", Options{Sort: "name", Format: "yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(jsonTable), &fromJson); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(yamlTable), &fromYaml); err != nil {
+		t.Fatal(err)
+	}
+	// Numbers in defaults are float64s from JSON and ints from YAML, so they're compared as JSON
+	for _, rows := range [][]TableRow{fromJson, fromYaml} {
+		for i := range rows {
+			b, err := json.Marshal(rows[i].Default)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows[i].Default = string(b)
+		}
+	}
+	if len(fromYaml) == 0 || !reflect.DeepEqual(fromYaml, fromJson) {
+		t.Errorf("got %+v from yaml, want %+v as from json", fromYaml, fromJson)
+	}
+}
+
+func TestYamlTable(t *testing.T) {
+	got, err := YamlTable([]string{"Type", "Count"}, [][]string{{"aws_s3_bucket", "2"}, {"aws_iam_role: x", "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- Count: \"2\"\n  Type: aws_s3_bucket\n- Count: \"1\"\n  Type: 'aws_iam_role: x'"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRstTable(t *testing.T) {
+	got := RstTable([]string{"Name", "Description"}, [][]string{
+		{"[bucket](https://example.com/main.tf#L1)", "Uses `var.name` for *the* bucket"},
+		{"![badge](https://example.com/b.svg \"title\")", "line one\n\nline two"},
+	})
+	want := ".. list-table::\n" +
+		"   :header-rows: 1\n" +
+		"\n" +
+		"   * - Name\n" +
+		"     - Description\n" +
+		"   * - `bucket <https://example.com/main.tf#L1>`__\n" +
+		"     - Uses ``var.name`` for \\*the\\* bucket\n" +
+		"   * - `badge <https://example.com/b.svg>`__\n" +
+		"     - line one\n" +
+		"\n" +
+		"       line two"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCsvTable(t *testing.T) {
+	got, err := CsvTable([]string{"Name", "Description"}, [][]string{
+		{"[bucket](https://example.com/main.tf#L1)", "Uses `var.name`, \"quoted\""},
+		{"![badge](https://example.com/b.svg)", "line one\nline two"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Name,Description\n" +
+		"bucket,\"Uses var.name, \"\"quoted\"\"\"\n" +
+		"badge,\"line one\nline two\""
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCsvVarsTablePositions(t *testing.T) {
+	module := loadTestModule(t, "declaration")
+	table, err := VarsTable(module, "", "", Options{Sort: "declaration", Format: "csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, record := range records[1:] {
+		got = append(got, record[len(record)-1])
+	}
+	want := []string{"1_core.tf:1", "1_core.tf:5", "2_network.tf:1", "2_network.tf:5", "10_tags.tf:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got positions %v, want %v", got, want)
+	}
+}
+
+func TestHtmlCellLineBreaks(t *testing.T) {
+	for _, cell := range []string{"one\ntwo", "one\r\ntwo", "one\rtwo"} {
+		if got := htmlCell(cell); got != "one<br>two" {
+			t.Errorf("got %q for %q, want %q", got, cell, "one<br>two")
+		}
+		if got, want := htmlCell(cell), MarkdownTableCellEscape(cell); got != want {
+			t.Errorf("got %q for %q, want the markdown cell's %q", got, cell, want)
+		}
+	}
+}
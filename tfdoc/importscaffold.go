@@ -0,0 +1,120 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+var ValidImportStyles = []string{
+	"cli",
+	"block",
+}
+
+// unimportableTypes are resource types that exist only in state, so there is nothing to import
+var unimportableTypes = []string{
+	"null_resource",
+	"terraform_data",
+	"time_sleep",
+	"local_file",
+	"local_sensitive_file",
+}
+
+// FindRepetition returns whether each managed resource of the module's .tf files sets count or for_each,
+// keyed by type.name. Resources that set neither are left out. Files that don't parse are skipped, as
+// tfconfig has already reported them.
+func FindRepetition(modulePath string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]string)
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "resource" || len(block.Labels) != 2 {
+				continue
+			}
+			for _, meta := range []string{"count", "for_each"} {
+				if _, ok := block.Body.Attributes[meta]; ok {
+					found[block.Labels[0]+"."+block.Labels[1]] = meta
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// ImportScaffold lists an import for each importable managed resource, sorted by address, as
+// terraform import commands or import blocks. The IDs are placeholders, as are the keys of resources
+// using for_each, which get a comment saying so.
+func ImportScaffold(module *tfconfig.Module, repetition map[string]string, style string) string {
+	addresses := []string{}
+	for _, r := range module.ManagedResources {
+		if !StringInSlice(r.Type, unimportableTypes) {
+			addresses = append(addresses, r.Type+"."+r.Name)
+		}
+	}
+	sort.Strings(addresses)
+
+	imports := []string{}
+	for _, address := range addresses {
+		lines := []string{}
+		to := address
+		switch repetition[address] {
+		case "count":
+			to += "[0]"
+			lines = append(lines, "# count: one import per index")
+		case "for_each":
+			to += `["KEY"]`
+			lines = append(lines, "# for_each: one import per key, e.g. the KEY of each.key")
+		}
+		if style == "block" {
+			block := []string{"import {"}
+			for _, comment := range lines {
+				block = append(block, "  "+comment)
+			}
+			block = append(block, fmt.Sprintf("  to = %s", to), `  id = "<ID>"`, "}")
+			imports = append(imports, strings.Join(block, "\n"))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("terraform import '%s' <ID>", to))
+		imports = append(imports, strings.Join(lines, "\n"))
+	}
+
+	separator := "\n"
+	if style == "block" {
+		separator = "\n\n"
+	}
+	return strings.Join(imports, separator)
+}
+
+// ImportScaffoldFence wraps the scaffold in a code fence for a document, or is empty when there is nothing to import
+func ImportScaffoldFence(scaffold, style string) string {
+	if scaffold == "" {
+		return ""
+	}
+	lang := "sh"
+	if style == "block" {
+		lang = "hcl"
+	}
+	return fmt.Sprintf("```%s\n%s\n```", lang, scaffold)
+}
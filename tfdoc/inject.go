@@ -0,0 +1,277 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultInjectMarker starts the comments that mark regions, e.g. <!-- tf2doc:inputs:begin -->
+const DefaultInjectMarker = "tf2doc"
+
+// InjectableActions are the actions whose output a region can hold
+var InjectableActions = []string{
+	"VarsTable",
+	"OutputsTable",
+	"ManagedResourcesTable",
+	"DataSourcesTable",
+	"RequirementsTable",
+	"RequiredProvidersTable",
+	"ProvidersTable",
+	"RemovedTable",
+}
+
+// InjectRegion fills the region called Name with the output of Action
+type InjectRegion struct {
+	Name, Action string
+}
+
+// ParseInjectRegions parses -inject-region values like "inputs=VarsTable,outputs=OutputsTable"
+func ParseInjectRegions(values []string) ([]InjectRegion, error) {
+	regions := []InjectRegion{}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		for _, pair := range strings.Split(v, ",") {
+			parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, fmt.Errorf("inject region %q: expected name=action", pair)
+			}
+			if !StringInSlice(parts[1], InjectableActions) {
+				return nil, fmt.Errorf("inject region %q: action %s is not one of: %s", pair, parts[1], InjectableActions)
+			}
+			if seen[parts[0]] {
+				return nil, fmt.Errorf("inject region %q: region %s is given twice", pair, parts[0])
+			}
+			seen[parts[0]] = true
+			regions = append(regions, InjectRegion{parts[0], parts[1]})
+		}
+	}
+	return regions, nil
+}
+
+// markedRegion is where a region's begin and end markers are, as 0 based line numbers
+type markedRegion struct {
+	Name       string
+	Begin, End int
+}
+
+// markerRegexp matches a region's begin or end marker, capturing its name and which it is
+func markerRegexp(marker string) *regexp.Regexp {
+	return regexp.MustCompile(`^\s*<!--\s*` + regexp.QuoteMeta(marker) + `:([\w-]+):(begin|end)\s*-->\s*$`)
+}
+
+// FindMarkedRegions finds the regions marked in lines, outside code fences. Regions must not nest or
+// overlap, and each name may be used once.
+func FindMarkedRegions(lines []string, marker string) ([]markedRegion, error) {
+	rMarker := markerRegexp(marker)
+	regions := []markedRegion{}
+	seen := make(map[string]int)
+	var open *markedRegion
+	fence := codeFence{}
+	for i, line := range lines {
+		if fence.skip(line) {
+			continue
+		}
+		m := rMarker.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name, kind := m[1], m[2]
+		switch {
+		case kind == "begin" && open != nil:
+			return nil, fmt.Errorf("line %d: region %s begins inside region %s, which begins on line %d", i+1, name, open.Name, open.Begin+1)
+		case kind == "begin":
+			if first, ok := seen[name]; ok {
+				return nil, fmt.Errorf("line %d: region %s is already marked on line %d", i+1, name, first+1)
+			}
+			seen[name] = i
+			open = &markedRegion{Name: name, Begin: i}
+		case open == nil:
+			return nil, fmt.Errorf("line %d: region %s ends without beginning", i+1, name)
+		case open.Name != name:
+			return nil, fmt.Errorf("line %d: region %s ends inside region %s, which begins on line %d", i+1, name, open.Name, open.Begin+1)
+		default:
+			open.End = i
+			regions = append(regions, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		return nil, fmt.Errorf("line %d: region %s never ends", open.Begin+1, open.Name)
+	}
+	return regions, nil
+}
+
+// regionBlock is the lines that go between a region's markers for content, blank lines around it, ending
+// in "\r" when crlf is set
+func regionBlock(content string, crlf bool) []string {
+	block := append(append([]string{""}, strings.Split(strings.TrimRight(strings.Replace(content, "\r\n", "\n", -1), "\n"), "\n")...), "")
+	if crlf {
+		for i := range block {
+			block[i] += "\r"
+		}
+	}
+	return block
+}
+
+// InjectRegions replaces what is between each region's markers in file with its entry in contents.
+// Regions of the file without contents are left alone, and contents without a region, with a warning.
+// The file stays locked from being read to being written, so runs injecting into it at the same time
+// don't undo each other's changes.
+func InjectRegions(w *FileWriter, file, marker string, contents map[string]string) error {
+	return w.UpdateFile(file, func(d []byte, exists bool) ([]byte, error) {
+		if !exists {
+			return nil, &os.PathError{Op: "open", Path: file, Err: os.ErrNotExist}
+		}
+		return injectRegions(file, string(d), marker, contents)
+	})
+}
+
+// injectRegions is the content d of file with its regions filled from contents
+func injectRegions(file, d, marker string, contents map[string]string) ([]byte, error) {
+	lines := strings.Split(d, "\n")
+	regions, err := FindMarkedRegions(lines, marker)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	injected := make(map[string]bool)
+	result := []string{}
+	previous := 0
+	for _, r := range regions {
+		content, ok := contents[r.Name]
+		if !ok {
+			stderr.Printf("%s: leaving region %s on line %d alone, as no -inject-region fills it", file, r.Name, r.Begin+1)
+			continue
+		}
+		injected[r.Name] = true
+		result = append(result, lines[previous:r.Begin+1]...)
+		// The region's lines end as its begin marker's does, so the file keeps its line endings
+		result = append(result, regionBlock(content, strings.HasSuffix(lines[r.Begin], "\r"))...)
+		previous = r.End
+	}
+	result = append(result, lines[previous:]...)
+
+	missing := []string{}
+	for name := range contents {
+		if !injected[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		stderr.Printf("%s: there is no region %s to inject into", file, name)
+	}
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// rReadmeMarker matches the markers whose contents -action InjectReadme replaces, <!-- BEGIN TF2DOC --> and
+// <!-- END TF2DOC -->
+var rReadmeMarker = regexp.MustCompile(`^\s*<!--\s*(BEGIN|END) TF2DOC\s*-->\s*\r?$`)
+
+// findReadmeBlocks finds the BEGIN TF2DOC and END TF2DOC marker pairs in lines, outside code fences. Pairs
+// must not nest, and every BEGIN needs an END.
+func findReadmeBlocks(lines []string) ([]markedRegion, error) {
+	blocks := []markedRegion{}
+	var open *markedRegion
+	fence := codeFence{}
+	for i, line := range lines {
+		if fence.skip(line) {
+			continue
+		}
+		m := rReadmeMarker.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch {
+		case m[1] == "BEGIN" && open != nil:
+			return nil, fmt.Errorf("line %d: BEGIN TF2DOC inside the block that begins on line %d", i+1, open.Begin+1)
+		case m[1] == "BEGIN":
+			open = &markedRegion{Begin: i}
+		case open == nil:
+			return nil, fmt.Errorf("line %d: END TF2DOC without a BEGIN TF2DOC", i+1)
+		default:
+			open.End = i
+			blocks = append(blocks, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		return nil, fmt.Errorf("line %d: BEGIN TF2DOC without an END TF2DOC", open.Begin+1)
+	}
+	return blocks, nil
+}
+
+// injectReadme is the lines of d, the README in file, before and after what is between each pair of
+// <!-- BEGIN TF2DOC --> and <!-- END TF2DOC --> markers is replaced by content
+func injectReadme(file, d, content string) ([]string, []string, error) {
+	lines := strings.Split(d, "\n")
+	blocks, err := findReadmeBlocks(lines)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if len(blocks) == 0 {
+		return nil, nil, fmt.Errorf("%s has no <!-- BEGIN TF2DOC --> and <!-- END TF2DOC --> markers to inject between", file)
+	}
+
+	result := []string{}
+	previous := 0
+	for _, b := range blocks {
+		result = append(result, lines[previous:b.Begin+1]...)
+		result = append(result, regionBlock(content, strings.HasSuffix(lines[b.Begin], "\r"))...)
+		previous = b.End
+	}
+	result = append(result, lines[previous:]...)
+	return lines, result, nil
+}
+
+// InjectReadme replaces what is between each pair of <!-- BEGIN TF2DOC --> and <!-- END TF2DOC --> markers
+// in the README in file with content. Everything outside the markers is kept as it is. The README is read and
+// written back through w, holding its lock, so that a concurrent run's change isn't lost.
+func InjectReadme(w *FileWriter, file, content string) error {
+	return w.UpdateFile(file, func(d []byte, exists bool) ([]byte, error) {
+		if !exists {
+			return nil, &os.PathError{Op: "open", Path: file, Err: os.ErrNotExist}
+		}
+		_, injected, err := injectReadme(file, string(d), content)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.Join(injected, "\n")), nil
+	})
+}
+
+// DiffReadme is the change InjectReadme would make to file as a unified diff, empty when the README is
+// up to date
+func DiffReadme(file, content string) (string, error) {
+	d, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	lines, injected, err := injectReadme(file, string(d), content)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(file, lines, injected), nil
+}
+
+// CheckNoMarkers fails if a document rendered in the same run as -inject-into contains region markers.
+// Were it written into the file being injected into, the next run would find nested regions and fail,
+// or replace the wrong text.
+func CheckNoMarkers(name string, rendered []byte, marker string) error {
+	rMarker := markerRegexp(marker)
+	fence := codeFence{}
+	for i, line := range strings.Split(string(rendered), "\n") {
+		if fence.skip(line) {
+			continue
+		}
+		if m := rMarker.FindStringSubmatch(line); m != nil {
+			return fmt.Errorf("%s line %d has the %s marker of region %s. Regions are marked in the -inject-into file, not the template, "+
+				"or the markers nest when the document is injected; remove it from the template or change -inject-marker", name, i+1, m[2], m[1])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package tfdoc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const injectReadmeFixture = `# Module
+
+<!-- tf2doc:inputs:begin -->
+old inputs
+<!-- tf2doc:inputs:end -->
+
+<!-- tf2doc:outputs:begin -->
+old outputs
+<!-- tf2doc:outputs:end -->
+`
+
+func TestInjectRegions(t *testing.T) {
+	defer func(l *log.Logger) { stderr = l }(stderr)
+	tests := []struct {
+		name     string
+		contents map[string]string
+		want     string
+		warnings []string
+	}{
+		{
+			"both regions",
+			map[string]string{"inputs": "new inputs", "outputs": "new outputs\n"},
+			"# Module\n\n<!-- tf2doc:inputs:begin -->\n\nnew inputs\n\n<!-- tf2doc:inputs:end -->\n\n<!-- tf2doc:outputs:begin -->\n\nnew outputs\n\n<!-- tf2doc:outputs:end -->\n",
+			nil,
+		},
+		{
+			"one region",
+			map[string]string{"outputs": "new outputs"},
+			"# Module\n\n<!-- tf2doc:inputs:begin -->\nold inputs\n<!-- tf2doc:inputs:end -->\n\n<!-- tf2doc:outputs:begin -->\n\nnew outputs\n\n<!-- tf2doc:outputs:end -->\n",
+			[]string{"README.md: leaving region inputs on line 3 alone, as no -inject-region fills it"},
+		},
+		{
+			"regions the file doesn't have",
+			map[string]string{"resources": "resources", "requirements": "requirements"},
+			injectReadmeFixture,
+			[]string{"README.md: there is no region requirements to inject into", "README.md: there is no region resources to inject into"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var warnings bytes.Buffer
+			stderr = log.New(&warnings, "", 0)
+			file := filepath.Join(t.TempDir(), "README.md")
+			if err := ioutil.WriteFile(file, []byte(injectReadmeFixture), 0644); err != nil {
+				t.Fatal(err)
+			}
+			w := &FileWriter{Allowed: []string{file}}
+			if err := InjectRegions(w, file, DefaultInjectMarker, test.contents); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+			for _, want := range test.warnings {
+				if !strings.Contains(warnings.String(), want) {
+					t.Errorf("the warnings are %q, want them to contain %q", warnings.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInjectRegions(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    []InjectRegion
+		wantErr string
+	}{
+		{nil, []InjectRegion{}, ""},
+		{[]string{"inputs=VarsTable,outputs=OutputsTable"}, []InjectRegion{{"inputs", "VarsTable"}, {"outputs", "OutputsTable"}}, ""},
+		{[]string{"inputs=VarsTable", " requirements=RequirementsTable"}, []InjectRegion{{"inputs", "VarsTable"}, {"requirements", "RequirementsTable"}}, ""},
+		{[]string{"inputs"}, nil, `inject region "inputs": expected name=action`},
+		{[]string{"=VarsTable"}, nil, `inject region "=VarsTable": expected name=action`},
+		{[]string{"inputs=Vars"}, nil, `inject region "inputs=Vars": action Vars is not one of`},
+		{[]string{"inputs=VarsTable", "inputs=OutputsTable"}, nil, `inject region "inputs=OutputsTable": region inputs is given twice`},
+	}
+	for _, test := range tests {
+		got, err := ParseInjectRegions(test.values)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("ParseInjectRegions(%q) gave error %v, want %q", test.values, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseInjectRegions(%q) = %v, %v, want %v", test.values, got, err, test.want)
+		}
+	}
+}
+
+func TestFindMarkedRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		marker  string
+		readme  string
+		want    []markedRegion
+		wantErr string
+	}{
+		{
+			"regions between prose",
+			DefaultInjectMarker,
+			"# Module\n<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:inputs:end -->\nProse\n  <!--tf2doc:outputs:begin-->\nold\n<!-- tf2doc:outputs:end -->\n",
+			[]markedRegion{{"inputs", 1, 2}, {"outputs", 4, 6}},
+			"",
+		},
+		{
+			"a custom marker",
+			"docs",
+			"<!-- tf2doc:inputs:begin -->\n<!-- docs:inputs:begin -->\n<!-- docs:inputs:end -->\n",
+			[]markedRegion{{"inputs", 1, 2}},
+			"",
+		},
+		{
+			"markers in a code fence",
+			DefaultInjectMarker,
+			"```\n<!-- tf2doc:inputs:begin -->\n```\n<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:inputs:end -->\n",
+			[]markedRegion{{"inputs", 3, 4}},
+			"",
+		},
+		{
+			"a marker with text around it",
+			DefaultInjectMarker,
+			"See <!-- tf2doc:inputs:begin --> here\n",
+			[]markedRegion{},
+			"",
+		},
+		{
+			"overlapping regions",
+			DefaultInjectMarker,
+			"<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:outputs:begin -->\n<!-- tf2doc:inputs:end -->\n<!-- tf2doc:outputs:end -->\n",
+			nil,
+			"line 2: region outputs begins inside region inputs, which begins on line 1",
+		},
+		{
+			"an end inside another region",
+			DefaultInjectMarker,
+			"<!-- tf2doc:inputs:begin -->\n\n<!-- tf2doc:outputs:end -->\n",
+			nil,
+			"line 3: region outputs ends inside region inputs, which begins on line 1",
+		},
+		{
+			"an end without a begin",
+			DefaultInjectMarker,
+			"# Module\n<!-- tf2doc:inputs:end -->\n",
+			nil,
+			"line 2: region inputs ends without beginning",
+		},
+		{
+			"a region that never ends",
+			DefaultInjectMarker,
+			"# Module\n\n<!-- tf2doc:inputs:begin -->\n",
+			nil,
+			"line 3: region inputs never ends",
+		},
+		{
+			"a region marked twice",
+			DefaultInjectMarker,
+			"<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:inputs:end -->\n<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:inputs:end -->\n",
+			nil,
+			"line 3: region inputs is already marked on line 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FindMarkedRegions(strings.Split(test.readme, "\n"), test.marker)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got regions %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckNoMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		marker   string
+		rendered string
+		wantErr  string
+	}{
+		{"no markers", DefaultInjectMarker, "# Module\n\nInputs\n", ""},
+		{"a begin marker", DefaultInjectMarker, "# Module\n\n<!-- tf2doc:inputs:begin -->\n", "README.template line 3 has the begin marker of region inputs"},
+		{"an end marker", DefaultInjectMarker, "<!-- tf2doc:outputs:end -->", "README.template line 1 has the end marker of region outputs"},
+		{"a custom marker", "docs", "<!-- docs:inputs:begin -->\n", "README.template line 1 has the begin marker of region inputs"},
+		{"another marker's regions", "docs", "<!-- tf2doc:inputs:begin -->\n", ""},
+		{"markers in a code fence", DefaultInjectMarker, "~~~markdown\n<!-- tf2doc:inputs:begin -->\n<!-- tf2doc:inputs:end -->\n~~~\n", ""},
+	}
+	for _, test := range tests {
+		err := CheckNoMarkers("README.template", []byte(test.rendered), test.marker)
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %v, want it to start %q", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestInjectRegionsKeepsLineEndings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "README.md")
+	if err := ioutil.WriteFile(file, []byte("# Module\r\n<!-- tf2doc:inputs:begin -->\r\nold\r\n<!-- tf2doc:inputs:end -->\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := &FileWriter{Allowed: []string{file}}
+	if err := InjectRegions(w, file, DefaultInjectMarker, map[string]string{"inputs": "new\ninputs\n"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Module\r\n<!-- tf2doc:inputs:begin -->\r\n\r\nnew\r\ninputs\r\n\r\n<!-- tf2doc:inputs:end -->\r\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestInjectReadme(t *testing.T) {
+	tests := []struct {
+		name    string
+		readme  string
+		want    string
+		wantErr string
+	}{
+		{
+			"two blocks",
+			"# Module\n<!-- BEGIN TF2DOC -->\nold\n<!-- END TF2DOC -->\ntext\n<!-- BEGIN TF2DOC -->\n<!-- END TF2DOC -->\n",
+			"# Module\n<!-- BEGIN TF2DOC -->\n\ntables\n\n<!-- END TF2DOC -->\ntext\n<!-- BEGIN TF2DOC -->\n\ntables\n\n<!-- END TF2DOC -->\n",
+			"",
+		},
+		{
+			"windows line endings",
+			"# Module\r\n<!-- BEGIN TF2DOC -->\r\nold\r\n<!-- END TF2DOC -->\r\n",
+			"# Module\r\n<!-- BEGIN TF2DOC -->\r\n\r\ntables\r\n\r\n<!-- END TF2DOC -->\r\n",
+			"",
+		},
+		{
+			"markers in a code fence",
+			"```\n<!-- BEGIN TF2DOC -->\n```\n<!-- BEGIN TF2DOC -->\n<!-- END TF2DOC -->\n",
+			"```\n<!-- BEGIN TF2DOC -->\n```\n<!-- BEGIN TF2DOC -->\n\ntables\n\n<!-- END TF2DOC -->\n",
+			"",
+		},
+		{"no markers", "# Module\n", "", "has no <!-- BEGIN TF2DOC --> and <!-- END TF2DOC --> markers"},
+		{"nested", "<!-- BEGIN TF2DOC -->\n<!-- BEGIN TF2DOC -->\n<!-- END TF2DOC -->\n", "", "line 2: BEGIN TF2DOC inside the block that begins on line 1"},
+		{"unclosed", "<!-- BEGIN TF2DOC -->\n", "", "line 1: BEGIN TF2DOC without an END TF2DOC"},
+		{"unopened", "<!-- END TF2DOC -->\n", "", "line 1: END TF2DOC without a BEGIN TF2DOC"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "README.md")
+			if err := ioutil.WriteFile(file, []byte(test.readme), 0644); err != nil {
+				t.Fatal(err)
+			}
+			w := &FileWriter{Allowed: []string{file}}
+			err := InjectReadme(w, file, "tables\n")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got error %v, want it to contain %q", err, test.wantErr)
+				}
+				if got, _ := ioutil.ReadFile(file); string(got) != test.readme {
+					t.Errorf("a failed injection changed the README to %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := ioutil.ReadFile(file); string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffReadme(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "README.md")
+	if err := ioutil.WriteFile(file, []byte("# Module\n<!-- BEGIN TF2DOC -->\nold\n<!-- END TF2DOC -->\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff, err := DiffReadme(file, "tables\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-old\n", "+tables\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("got:\n%s\nwant it to contain %q", diff, want)
+		}
+	}
+
+	if err := InjectReadme(&FileWriter{Allowed: []string{file}}, file, "tables\n"); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := DiffReadme(file, "tables\n"); err != nil || diff != "" {
+		t.Errorf("an up to date README gave %q, %v", diff, err)
+	}
+}
+
+func TestInjectRegionsMissingFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "README.md")
+	w := &FileWriter{Allowed: []string{file}}
+	if err := InjectRegions(w, file, DefaultInjectMarker, map[string]string{"inputs": "x"}); !os.IsNotExist(err) {
+		t.Errorf("injecting into a missing file gave %v, want it not to exist", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("injecting into a missing file created it")
+	}
+}
+
+func TestConcurrentInjectionsKeepEachOthersRegions(t *testing.T) {
+	defer func(l *log.Logger) { stderr = l }(stderr)
+	stderr = log.New(ioutil.Discard, "", 0)
+	file := filepath.Join(t.TempDir(), "README.md")
+	if err := ioutil.WriteFile(file, []byte(injectReadmeFixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another run holds the README while two more start, each filling a different region of it
+	unlock, err := lock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for n, region := range []string{"inputs", "outputs"} {
+		wg.Add(1)
+		go func(n int, region string) {
+			defer wg.Done()
+			w := &FileWriter{Allowed: []string{file}}
+			errs[n] = InjectRegions(w, file, DefaultInjectMarker, map[string]string{region: "new " + region})
+		}(n, region)
+	}
+	time.Sleep(200 * time.Millisecond)
+	unlock()
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "new inputs") || !strings.Contains(string(got), "new outputs") {
+		t.Errorf("one injection undid the other:\n%s", got)
+	}
+}
@@ -0,0 +1,25 @@
+package tfdoc
+
+import "fmt"
+
+// jiraCell turns a table cell's markdown links, images and code spans into Jira wiki markup and escapes the
+// rest as Confluence's markup is. Jira links have no tooltip, so link titles are dropped, and images, such as
+// badges, lose their alt text.
+func jiraCell(cell string) string {
+	return convertCellMarkdown(cell, ConfluenceTableCellEscape, func(image bool, text, src, title string) string {
+		if image {
+			return fmt.Sprintf("!%s!", src)
+		}
+		if text == "" {
+			return fmt.Sprintf("[%s]", src)
+		}
+		return fmt.Sprintf("[%s|%s]", ConfluenceTableCellEscape(text), src)
+	}, func(c string) string {
+		return fmt.Sprintf("{{%s}}", ConfluenceTableCellEscape(c))
+	})
+}
+
+// JiraTable renders a table as Jira wiki markup, with a ||heading|| row, e.g. for pasting into a ticket
+func JiraTable(headings []string, data [][]string) string {
+	return wikiTable(headings, data, jiraCell)
+}
@@ -0,0 +1,123 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// tfconfig marshals resource modes and diagnostic severities as strings but can't unmarshal them,
+// so the JSON is decoded into these mirror types first.
+
+type jsonResource struct {
+	Mode     string               `json:"mode"`
+	Type     string               `json:"type"`
+	Name     string               `json:"name"`
+	Provider tfconfig.ProviderRef `json:"provider"`
+	Pos      tfconfig.SourcePos   `json:"pos"`
+}
+
+type jsonDiagnostic struct {
+	Severity string              `json:"severity"`
+	Summary  string              `json:"summary"`
+	Detail   string              `json:"detail"`
+	Pos      *tfconfig.SourcePos `json:"pos"`
+}
+
+type jsonModule struct {
+	Path              string                          `json:"path"`
+	Variables         map[string]*tfconfig.Variable   `json:"variables"`
+	Outputs           map[string]*tfconfig.Output     `json:"outputs"`
+	RequiredCore      []string                        `json:"required_core"`
+	RequiredProviders map[string]json.RawMessage      `json:"required_providers"`
+	ManagedResources  map[string]*jsonResource        `json:"managed_resources"`
+	DataResources     map[string]*jsonResource        `json:"data_resources"`
+	ModuleCalls       map[string]*tfconfig.ModuleCall `json:"module_calls"`
+	Diagnostics       []jsonDiagnostic                `json:"diagnostics"`
+}
+
+// decodeProviderRequirement accepts both the current object form and the older list of version constraints
+func decodeProviderRequirement(raw json.RawMessage) (*tfconfig.ProviderRequirement, error) {
+	req := &tfconfig.ProviderRequirement{}
+	if err := json.Unmarshal(raw, req); err == nil {
+		return req, nil
+	}
+	if err := json.Unmarshal(raw, &req.VersionConstraints); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (r *jsonResource) resource(mode tfconfig.ResourceMode) *tfconfig.Resource {
+	return &tfconfig.Resource{Mode: mode, Type: r.Type, Name: r.Name, Provider: r.Provider, Pos: r.Pos}
+}
+
+// LoadModuleJSON reads a module from the output of terraform-config-inspect --json. A filePath of "-"
+// reads from stdin. Sections missing from the JSON are left empty.
+func LoadModuleJSON(filePath string) (*tfconfig.Module, tfconfig.Diagnostics, error) {
+	var b []byte
+	var err error
+	if filePath == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(filePath)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var jm jsonModule
+	if err := json.Unmarshal(b, &jm); err != nil {
+		return nil, nil, fmt.Errorf("%s is not terraform-config-inspect JSON: %v", filePath, err)
+	}
+
+	module := &tfconfig.Module{
+		Path:              jm.Path,
+		Variables:         jm.Variables,
+		Outputs:           jm.Outputs,
+		RequiredCore:      jm.RequiredCore,
+		RequiredProviders: make(map[string]*tfconfig.ProviderRequirement),
+		ManagedResources:  make(map[string]*tfconfig.Resource),
+		DataResources:     make(map[string]*tfconfig.Resource),
+		ModuleCalls:       jm.ModuleCalls,
+	}
+	if module.Variables == nil {
+		module.Variables = make(map[string]*tfconfig.Variable)
+	}
+	if module.Outputs == nil {
+		module.Outputs = make(map[string]*tfconfig.Output)
+	}
+	if module.ModuleCalls == nil {
+		module.ModuleCalls = make(map[string]*tfconfig.ModuleCall)
+	}
+	for name, raw := range jm.RequiredProviders {
+		req, err := decodeProviderRequirement(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: bad required_providers entry %q: %v", filePath, name, err)
+		}
+		module.RequiredProviders[name] = req
+	}
+	for key, r := range jm.ManagedResources {
+		module.ManagedResources[key] = r.resource(tfconfig.ManagedResourceMode)
+	}
+	for key, r := range jm.DataResources {
+		module.DataResources[key] = r.resource(tfconfig.DataResourceMode)
+	}
+
+	for _, d := range jm.Diagnostics {
+		severity := tfconfig.DiagWarning
+		if d.Severity == "error" {
+			severity = tfconfig.DiagError
+		}
+		module.Diagnostics = append(module.Diagnostics, tfconfig.Diagnostic{
+			Severity: severity,
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+			Pos:      d.Pos,
+		})
+	}
+	return module, module.Diagnostics, nil
+}
@@ -0,0 +1,69 @@
+package tfdoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// DefaultLargeThreshold is the number of managed resources above which templates get a summary instead of the full table
+const DefaultLargeThreshold = 1000
+
+const resourceListingPage = "resources.md"
+
+// ResourceTypeSummary counts the module's managed resources by type, linking to the full listing at listingUrl
+func ResourceTypeSummary(module *tfconfig.Module, listingUrl string, opts Options) string {
+	counts := make(map[string]int)
+	for _, r := range module.ManagedResources {
+		counts[r.Type]++
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	data := [][]string{}
+	for _, t := range types {
+		data = append(data, []string{t, fmt.Sprintf("%d", counts[t])})
+	}
+	table := MarkdownTable([]string{"Resource Type", "Count"}, []string{"--------", "----"}, data)
+	return fmt.Sprintf("This module manages %d resources of %d types. See the %s.\n\n%s",
+		len(module.ManagedResources), len(types), opts.link("full listing", listingUrl, "Every managed resource"), table)
+}
+
+// WriteResourceListing writes the full managed resources table to its own page, in -out-dir or else
+// beside the module, and returns the page's path relative to the module. When no repoUrl is set the
+// source links are relative to the page.
+func WriteResourceListing(w *FileWriter, module *tfconfig.Module, pageOpts PageOptions, opts Options) (string, error) {
+	pageDir := pageOpts.TfPath
+	if pageOpts.OutDir != "" {
+		pageDir = pageOpts.OutDir
+	}
+	baseUrl, modulePath := pageOpts.RepoUrl, pageOpts.ModulePath
+	if baseUrl == "" {
+		var err error
+		if baseUrl, err = RelativeBaseUrl(pageDir, pageOpts.TfPath); err != nil {
+			return "", err
+		}
+		modulePath = ""
+	}
+
+	page := filepath.Join(pageDir, resourceListingPage)
+	table, err := ManagedResourcesTable(module, baseUrl, modulePath, opts)
+	if err != nil {
+		return "", err
+	}
+	content := fmt.Sprintf("# Terraform Managed resources\n\n%s\n", table)
+	if pageOpts.HeadingIds == "explicit" {
+		content = string(AddHeadingIDs([]byte(content)))
+	}
+	if err := w.WriteFile(page, []byte(content)); err != nil {
+		return "", err
+	}
+	return tfpath.Rel(pageOpts.TfPath, page)
+}
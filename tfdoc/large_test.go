@@ -0,0 +1,69 @@
+package tfdoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/fixture"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// loadLargeModule generates a module with the given number of resources and loads it
+func loadLargeModule(tb testing.TB, resources int) *tfconfig.Module {
+	tb.Helper()
+	dir := tb.TempDir()
+	if err := fixture.WriteModule(dir, resources, 500); err != nil {
+		tb.Fatal(err)
+	}
+	module, diags := tfconfig.LoadModule(dir)
+	if diags.HasErrors() {
+		tb.Fatal(diags.Err())
+	}
+	return module
+}
+
+func TestResourceTypeSummary(t *testing.T) {
+	module := loadLargeModule(t, 10)
+	got := ResourceTypeSummary(module, "resources.md", Options{})
+	want := "This module manages 10 resources of 8 types. See the [full listing](resources.md).\n\n" +
+		"| Resource Type | Count |\n| -------- | ---- |\n" +
+		"| aws_cloudwatch_metric_alarm | 1 |\n| aws_iam_policy | 2 |\n| aws_iam_role | 2 |\n" +
+		"| aws_lambda_function | 1 |\n| aws_route53_record | 1 |\n| aws_s3_bucket | 1 |\n" +
+		"| aws_security_group | 1 |\n| aws_sqs_queue | 1 |"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// BenchmarkLargeModule times the paths a module the size of the largest roots goes through
+func BenchmarkLargeModule(b *testing.B) {
+	module := loadLargeModule(b, 6000)
+	opts := Options{Sort: "name", Description: "full"}
+	table, err := ManagedResourcesTable(module, "", "", opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	document := []byte("# Module\n\n## Resources\n\n" + table + "\n\n## Inputs\n")
+
+	b.Run("ManagedResourcesTable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ManagedResourcesTable(module, "", "", opts)
+		}
+	})
+	b.Run("ResourceTypeSummary", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ResourceTypeSummary(module, "resources.md", Options{})
+		}
+	})
+	b.Run("MarkdownToc", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			toc, err := BuildMarkdownToc(document, 3, 0, Options{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(toc) != 5 || !strings.Contains(toc[3], "Resources") {
+				b.Fatalf("got TOC %q", toc)
+			}
+		}
+	})
+}
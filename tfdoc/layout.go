@@ -0,0 +1,54 @@
+package tfdoc
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+var ValidOutLayouts = []string{
+	"mirror",
+}
+
+// mirrorIndexPage is the document written for a module at the root of its repository
+const mirrorIndexPage = "index.md"
+
+// RelativeBaseUrl is the link prefix that reaches the module's files from a page written in pageDir,
+// for use when no repoUrl is set
+func RelativeBaseUrl(pageDir, tfPath string) (string, error) {
+	rel, err := tfpath.Rel(pageDir, tfPath)
+	if err != nil || rel == "." {
+		return "", err
+	}
+	return rel, nil
+}
+
+// MirrorDocPath returns where the mirror layout puts the module's document: <out-dir>/<module path>.md,
+// with the module's path relative to its repository taken from -modulePath or else found from git. Paths
+// that would leave outDir are refused.
+func MirrorDocPath(outDir, tfPath, modulePath string) (string, error) {
+	rel := strings.Trim(tfpath.Host.ToSlash(modulePath), "/")
+	if rel == "" {
+		root, ok := FindRepoRoot(tfPath)
+		if !ok {
+			return "", fmt.Errorf("can't tell where %s is in its repository, set -modulePath", tfPath)
+		}
+		var err error
+		if rel, err = tfpath.Rel(root, tfPath); err != nil {
+			return "", err
+		}
+	}
+
+	rel = path.Clean(rel)
+	if rel == "." {
+		return filepath.Join(outDir, mirrorIndexPage), nil
+	}
+	page, err := tfpath.Host.Join(outDir, rel+".md")
+	if err != nil {
+		return "", fmt.Errorf("module path %q would be written outside %s", modulePath, outDir)
+	}
+	return page, nil
+}
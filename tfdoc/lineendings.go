@@ -0,0 +1,202 @@
+package tfdoc
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// detectEOL is the line ending content uses throughout, or empty when it has none or mixes them
+func detectEOL(content []byte) string {
+	crlf := bytes.Count(content, []byte("\r\n"))
+	lf := bytes.Count(content, []byte("\n")) - crlf
+	switch {
+	case crlf > 0 && lf == 0:
+		return "\r\n"
+	case lf > 0 && crlf == 0:
+		return "\n"
+	}
+	return ""
+}
+
+// editorconfigSettings are the .editorconfig properties that apply to a file tf2doc writes. Empty fields
+// weren't set.
+type editorconfigSettings struct {
+	EOL          string // "\n" or "\r\n"
+	FinalNewline string // "true" or "false"
+}
+
+// editorconfigGlob translates a section name of the .editorconfig in dir into a regexp matching the
+// slash separated absolute paths it applies to
+func editorconfigGlob(dir, glob string) (*regexp.Regexp, error) {
+	var re strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				re.WriteString(".*")
+				i++
+			} else {
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		case '{':
+			re.WriteString("(?:")
+		case '}':
+			re.WriteString(")")
+		case ',':
+			re.WriteString("|")
+		case '[', ']':
+			re.WriteByte(c)
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	prefix := regexp.QuoteMeta(tfpath.Host.ToSlash(dir)) + "/"
+	if !strings.Contains(glob, "/") {
+		// Names without a slash match in any directory below
+		prefix += "(?:.*/)?"
+	}
+	return regexp.Compile("^" + prefix + strings.TrimPrefix(re.String(), "/") + "$")
+}
+
+// applyEditorconfig updates settings with the sections of the .editorconfig file that match absPath, and
+// reports whether the file is marked root
+func applyEditorconfig(file, absPath string, settings *editorconfigSettings) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	root := false
+	matches := false
+	inPreamble := true
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inPreamble = false
+			re, err := editorconfigGlob(filepath.Dir(file), line[1:len(line)-1])
+			if err != nil {
+				// A section tf2doc can't read can't be applied
+				matches = false
+				continue
+			}
+			matches = re.MatchString(tfpath.Host.ToSlash(absPath))
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.ToLower(strings.TrimSpace(parts[1]))
+		switch {
+		case inPreamble && key == "root":
+			root = value == "true"
+		case !matches:
+			// The property is for other files
+		case key == "end_of_line" && value == "lf":
+			settings.EOL = "\n"
+		case key == "end_of_line" && value == "crlf":
+			settings.EOL = "\r\n"
+		case key == "insert_final_newline" && (value == "true" || value == "false"):
+			settings.FinalNewline = value
+		}
+	}
+	return root, scanner.Err()
+}
+
+// EditorconfigPaths are the .editorconfig files that may apply to filePath, nearest first, whether or not
+// they exist: one in its directory and each directory above it
+func EditorconfigPaths(filePath string) ([]string, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		paths = append(paths, filepath.Join(dir, ".editorconfig"))
+		if dir == filepath.Dir(dir) {
+			return paths, nil
+		}
+	}
+}
+
+// editorconfigFor reads the .editorconfig files from filePath's directory up to the one marked root,
+// with nearer files taking precedence
+func editorconfigFor(filePath string) (editorconfigSettings, error) {
+	settings := editorconfigSettings{}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return settings, err
+	}
+	paths, err := EditorconfigPaths(abs)
+	if err != nil {
+		return settings, err
+	}
+	files := []string{}
+	for _, file := range paths {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+
+	// Settings nearer the file win, so apply from the root down, starting at the nearest file marked root
+	start := len(files) - 1
+	for i, file := range files {
+		root, err := applyEditorconfig(file, abs, &editorconfigSettings{})
+		if err != nil {
+			return settings, err
+		}
+		if root {
+			start = i
+			break
+		}
+	}
+	for i := start; i >= 0; i-- {
+		if _, err := applyEditorconfig(files[i], abs, &settings); err != nil {
+			return settings, err
+		}
+	}
+	return settings, nil
+}
+
+// fitLineEndings gives content the line endings of the file it replaces, old, or for a new file those of
+// its .editorconfig, then adds or removes the final newline as the .editorconfig says. Files that mix
+// line endings keep them as they are.
+func fitLineEndings(filePath string, content, old []byte, exists bool) ([]byte, error) {
+	settings, err := editorconfigFor(filePath)
+	if err != nil {
+		return nil, err
+	}
+	eol := settings.EOL
+	if exists && bytes.Contains(old, []byte("\n")) {
+		eol = detectEOL(old)
+	}
+	if eol != "" {
+		content = bytes.Replace(bytes.Replace(content, []byte("\r\n"), []byte("\n"), -1), []byte("\n"), []byte(eol), -1)
+	}
+
+	switch settings.FinalNewline {
+	case "true":
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+			if eol == "" {
+				eol = "\n"
+			}
+			content = append(content, eol...)
+		}
+	case "false":
+		content = bytes.TrimRight(content, "\r\n")
+	}
+	return content, nil
+}
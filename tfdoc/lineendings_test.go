@@ -0,0 +1,45 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFitLineEndings(t *testing.T) {
+	tests := []struct {
+		name         string
+		editorconfig string
+		old          string
+		exists       bool
+		content      string
+		want         string
+	}{
+		{"a new file without an .editorconfig", "", "", false, "a\nb\n", "a\nb\n"},
+		{"replacing a CRLF file", "", "old\r\n", true, "a\nb\n", "a\r\nb\r\n"},
+		{"replacing an LF file with CRLF content", "", "old\n", true, "a\r\nb\r\n", "a\nb\n"},
+		{"replacing a file mixing line endings", "", "old\r\nold\n", true, "a\r\nb\n", "a\r\nb\n"},
+		{"a new file the .editorconfig wants CRLF", "root = true\n[*.md]\nend_of_line = crlf\n", "", false, "a\nb\n", "a\r\nb\r\n"},
+		{"the existing file wins over the .editorconfig", "root = true\n[*]\nend_of_line = crlf\n", "old\n", true, "a\nb\n", "a\nb\n"},
+		{"a final newline inserted", "root = true\n[*]\ninsert_final_newline = true\n", "", false, "a\nb", "a\nb\n"},
+		{"a final newline removed", "root = true\n[*]\ninsert_final_newline = false\n", "", false, "a\nb\n\n", "a\nb"},
+		{"a section for other files", "root = true\n[*.go]\nend_of_line = crlf\n", "", false, "a\n", "a\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if test.editorconfig != "" {
+				if err := ioutil.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(test.editorconfig), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := fitLineEndings(filepath.Join(dir, "README.md"), []byte(test.content), []byte(test.old), test.exists)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package tfdoc
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// LintRuleNames are the rules the Lint action checks, each set with -lint-rule name=value and
+// turned off with name=off
+var LintRuleNames = []string{
+	"var-pattern",            // A regexp every variable name must match
+	"output-pattern",         // A regexp every output name must match
+	"bool-prefix",            // Comma separated prefixes, one of which each bool variable's name must start with
+	"deny-words",             // Comma separated words no variable or output name may contain
+	"no-module-name",         // on for outputs whose names mustn't repeat the module's name
+	"no-experiments",         // on for modules that mustn't enable language experiments
+	"no-undocumented-blocks", // on for modules whose top-level blocks must all be documented
+}
+
+// lintPresets are sets of rules to start from with -lint-preset
+var lintPresets = map[string]map[string]string{
+	"none": {},
+	"hashicorp-style": {
+		"var-pattern":    "^[a-z][a-z0-9_]*$",
+		"output-pattern": "^[a-z][a-z0-9_]*$",
+		"bool-prefix":    "enable_,is_",
+		"no-module-name": "on",
+	},
+}
+
+// ValidLintPresets are the names of lintPresets
+var ValidLintPresets = []string{
+	"none",
+	"hashicorp-style",
+}
+
+// LintRules are the rules the Lint action applies. Rules that are off are empty.
+type LintRules struct {
+	VarPattern           *regexp.Regexp
+	OutputPattern        *regexp.Regexp
+	BoolPrefixes         []string
+	DenyWords            []string
+	NoModuleName         bool
+	NoExperiments        bool
+	NoUndocumentedBlocks bool
+	Ignore               []string // path.Match patterns of variable and output names that aren't checked at all
+}
+
+// ignored reports whether name matches one of the -lint-ignore patterns
+func (r LintRules) ignored(name string) bool {
+	for _, pattern := range r.Ignore {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLintIgnore splits a comma separated -lint-ignore list of name patterns, checking each is valid
+func ParseLintIgnore(list string) ([]string, error) {
+	patterns := []string{}
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("lint-ignore pattern %q: %v", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// ParseLintRules starts from the rules of preset, then applies -lint-rule values like
+// 'var-pattern=^[a-z_]+$' or 'bool-prefix=off'
+func ParseLintRules(preset string, values []string) (LintRules, error) {
+	rules := LintRules{}
+	base, ok := lintPresets[preset]
+	if !ok {
+		return rules, fmt.Errorf("lint-preset %s is not one of: %s", preset, ValidLintPresets)
+	}
+	settings := make(map[string]string)
+	for name, value := range base {
+		settings[name] = value
+	}
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return rules, fmt.Errorf("lint rule %q: expected name=value", v)
+		}
+		if !StringInSlice(parts[0], LintRuleNames) {
+			return rules, fmt.Errorf("lint rule %q: %s is not one of: %s", v, parts[0], LintRuleNames)
+		}
+		if parts[1] == "off" {
+			delete(settings, parts[0])
+			continue
+		}
+		settings[parts[0]] = parts[1]
+	}
+
+	var err error
+	if p, ok := settings["var-pattern"]; ok {
+		if rules.VarPattern, err = regexp.Compile(p); err != nil {
+			return rules, fmt.Errorf("lint rule var-pattern: %v", err)
+		}
+	}
+	if p, ok := settings["output-pattern"]; ok {
+		if rules.OutputPattern, err = regexp.Compile(p); err != nil {
+			return rules, fmt.Errorf("lint rule output-pattern: %v", err)
+		}
+	}
+	if p, ok := settings["bool-prefix"]; ok {
+		rules.BoolPrefixes = strings.Split(p, ",")
+	}
+	if w, ok := settings["deny-words"]; ok {
+		rules.DenyWords = strings.Split(strings.ToLower(w), ",")
+	}
+	if m, ok := settings["no-module-name"]; ok {
+		if m != "on" {
+			return rules, fmt.Errorf("lint rule no-module-name: expected on or off, got %s", m)
+		}
+		rules.NoModuleName = true
+	}
+	if m, ok := settings["no-experiments"]; ok {
+		if m != "on" {
+			return rules, fmt.Errorf("lint rule no-experiments: expected on or off, got %s", m)
+		}
+		rules.NoExperiments = true
+	}
+	if m, ok := settings["no-undocumented-blocks"]; ok {
+		if m != "on" {
+			return rules, fmt.Errorf("lint rule no-undocumented-blocks: expected on or off, got %s", m)
+		}
+		rules.NoUndocumentedBlocks = true
+	}
+	return rules, nil
+}
+
+// LintFinding is a variable or output that breaks a naming rule or has no description, or a language
+// experiment or block the no-experiments and no-undocumented-blocks rules forbid, at its declaration
+type LintFinding struct {
+	File    string
+	Line    int
+	Kind    string // variable, output, experiment or block
+	Name    string
+	Rule    string
+	Message string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s %q in %s:%d %s", f.Kind, f.Name, f.File, f.Line, f.Message)
+}
+
+// nameWords splits a name into its lower cased words, at underscores and dashes
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return r == '_' || r == '-' })
+}
+
+// moduleNameWords is the module's name as words, from its directory, less a registry style
+// terraform-<provider>- prefix
+func moduleNameWords(modulePath string) []string {
+	abs, err := filepath.Abs(modulePath)
+	if err != nil {
+		abs = modulePath
+	}
+	words := nameWords(tfpath.Base(abs))
+	if len(words) > 2 && words[0] == "terraform" {
+		words = words[2:]
+	}
+	return words
+}
+
+// containsWords reports whether words holds sub as a consecutive run
+func containsWords(words, sub []string) bool {
+	for i := 0; len(sub) > 0 && i+len(sub) <= len(words); i++ {
+		if strings.Join(words[i:i+len(sub)], "_") == strings.Join(sub, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks the module's variable and output names against rules, that each has a description and,
+// with no-experiments and no-undocumented-blocks, that it enables no language experiments and has no
+// top-level blocks the docs don't cover, in file and line order. Names matching
+// rules.Ignore are skipped, and so are missing descriptions in a deprecated module, which isn't worth
+// documenting further.
+func Lint(module *tfconfig.Module, rules LintRules) []LintFinding {
+	findings := []LintFinding{}
+	add := func(pos tfconfig.SourcePos, kind, name, rule, message string) {
+		findings = append(findings, LintFinding{File: pos.Filename, Line: pos.Line, Kind: kind, Name: name, Rule: rule, Message: message})
+	}
+	denied := func(pos tfconfig.SourcePos, kind, name string) {
+		words := nameWords(name)
+		for _, w := range rules.DenyWords {
+			if w != "" && StringInSlice(w, words) {
+				add(pos, kind, name, "deny-words", fmt.Sprintf("contains the word %s", w))
+			}
+		}
+	}
+
+	deprecation, err := FindDeprecation(module.Path)
+	if err != nil {
+		stderr.Printf("not checking whether the module is deprecated: %v", err)
+	}
+	undescribed := func(pos tfconfig.SourcePos, kind, name, description string) {
+		if !deprecation.Deprecated && strings.TrimSpace(description) == "" {
+			add(pos, kind, name, "description", "has no description")
+		}
+	}
+
+	for name, v := range module.Variables {
+		if rules.ignored(name) {
+			continue
+		}
+		if rules.VarPattern != nil && !rules.VarPattern.MatchString(name) {
+			add(v.Pos, "variable", name, "var-pattern", fmt.Sprintf("doesn't match %s", rules.VarPattern))
+		}
+		if len(rules.BoolPrefixes) > 0 && v.Type == "bool" {
+			prefixed := false
+			for _, p := range rules.BoolPrefixes {
+				prefixed = prefixed || strings.HasPrefix(name, p)
+			}
+			if !prefixed {
+				add(v.Pos, "variable", name, "bool-prefix", fmt.Sprintf("is a bool, so should start with one of %s", strings.Join(rules.BoolPrefixes, ", ")))
+			}
+		}
+		denied(v.Pos, "variable", name)
+		undescribed(v.Pos, "variable", name, v.Description)
+	}
+	moduleName := moduleNameWords(module.Path)
+	for name, o := range module.Outputs {
+		if rules.ignored(name) {
+			continue
+		}
+		if rules.OutputPattern != nil && !rules.OutputPattern.MatchString(name) {
+			add(o.Pos, "output", name, "output-pattern", fmt.Sprintf("doesn't match %s", rules.OutputPattern))
+		}
+		if rules.NoModuleName && containsWords(nameWords(name), moduleName) {
+			add(o.Pos, "output", name, "no-module-name", fmt.Sprintf("repeats the module name %s", strings.Join(moduleName, "_")))
+		}
+		denied(o.Pos, "output", name)
+		undescribed(o.Pos, "output", name, o.Description)
+	}
+	if rules.NoExperiments {
+		experiments, err := findExperimentPositions(module.Path)
+		if err != nil {
+			stderr.Printf("not checking for language experiments: %v", err)
+		}
+		for name, pos := range experiments {
+			add(pos, "experiment", name, "no-experiments", "is enabled, and experiments may change or be removed in any Terraform release")
+		}
+	}
+	if rules.NoUndocumentedBlocks {
+		blocks, err := findUndocumentedBlocks(module.Path)
+		if err != nil {
+			stderr.Printf("not checking for undocumented blocks: %v", err)
+		}
+		for _, b := range blocks {
+			add(b.Pos, "block", b.Type, "no-undocumented-blocks", "is a block type the docs don't cover")
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return naturalLess(a.File, b.File)
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Rule < b.Rule
+	})
+	return findings
+}
+
+// DocScore is how well documented the module's interface is, out of 100: the share of its variables and
+// outputs that have descriptions. A module with neither scores 100.
+func DocScore(module *tfconfig.Module) int {
+	total, described := 0, 0
+	for _, v := range module.Variables {
+		total++
+		if strings.TrimSpace(v.Description) != "" {
+			described++
+		}
+	}
+	for _, o := range module.Outputs {
+		total++
+		if strings.TrimSpace(o.Description) != "" {
+			described++
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return described * 100 / total
+}
+
+// LintSummary counts findings for a template, e.g. "3 warnings"
+func LintSummary(findings []LintFinding) string {
+	switch len(findings) {
+	case 0:
+		return "no warnings"
+	case 1:
+		return "1 warning"
+	}
+	return fmt.Sprintf("%d warnings", len(findings))
+}
+
+// TemplateLintFindings are findings as templates get them, naming files without the path the module was
+// loaded from, so the rendered document doesn't change with where it's run from
+func TemplateLintFindings(findings []LintFinding) []LintFinding {
+	named := make([]LintFinding, len(findings))
+	for i, f := range findings {
+		f.File = tfpath.Base(f.File)
+		named[i] = f
+	}
+	return named
+}
@@ -0,0 +1,196 @@
+package tfdoc
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// findingRules are the rule and name of each finding, e.g. "var-pattern variable BucketName"
+func findingRules(findings []LintFinding) []string {
+	rules := []string{}
+	for _, f := range findings {
+		rules = append(rules, f.Rule+" "+f.Kind+" "+f.Name)
+	}
+	return rules
+}
+
+func TestParseLintRules(t *testing.T) {
+	tests := []struct {
+		preset  string
+		values  []string
+		want    LintRules
+		wantErr bool
+	}{
+		{"none", nil, LintRules{}, false},
+		{"none", []string{"no-experiments=on"}, LintRules{NoExperiments: true}, false},
+		{"none", []string{"no-experiments=yes"}, LintRules{}, true},
+		{"none", []string{"no-module-name=on", "deny-words=Foo,bar"}, LintRules{NoModuleName: true, DenyWords: []string{"foo", "bar"}}, false},
+		{"none", []string{"no-module-name=yes"}, LintRules{}, true},
+		{"hashicorp-style", []string{"var-pattern=off", "output-pattern=off", "bool-prefix=off"}, LintRules{NoModuleName: true}, false},
+		{"none", []string{"no-such-rule=on"}, LintRules{}, true},
+		{"none", []string{"var-pattern"}, LintRules{}, true},
+		{"none", []string{"var-pattern=("}, LintRules{}, true},
+		{"strict", nil, LintRules{}, true},
+	}
+	for _, test := range tests {
+		got, err := ParseLintRules(test.preset, test.values)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseLintRules(%s, %q) gave error %v", test.preset, test.values, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseLintRules(%s, %q) = %+v, want %+v", test.preset, test.values, got, test.want)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		preset string
+		values []string
+		ignore []string
+		want   []string
+	}{
+		{"none", nil, nil, []string{"description variable temp_prefix", "description output id"}},
+		{"hashicorp-style", nil, nil, []string{"var-pattern variable BucketName", "bool-prefix variable versioning", "description variable temp_prefix", "no-module-name output bucket_arn", "description output id"}},
+		{"hashicorp-style", []string{"bool-prefix=off", "deny-words=temp"}, nil, []string{"var-pattern variable BucketName", "deny-words variable temp_prefix", "description variable temp_prefix", "no-module-name output bucket_arn", "description output id"}},
+		{"hashicorp-style", nil, []string{"temp_*", "Bucket*"}, []string{"bool-prefix variable versioning", "no-module-name output bucket_arn", "description output id"}},
+	}
+	for _, test := range tests {
+		rules, err := ParseLintRules(test.preset, test.values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules.Ignore = test.ignore
+		got := findingRules(Lint(loadTestModule(t, "lint/bucket"), rules))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s with %q ignoring %q: got findings %q, want %q", test.preset, test.values, test.ignore, got, test.want)
+		}
+	}
+}
+
+func TestDocScore(t *testing.T) {
+	tests := []struct {
+		module *tfconfig.Module
+		want   int
+	}{
+		{&tfconfig.Module{}, 100},
+		{loadTestModule(t, "lint/bucket"), 66},
+		{
+			&tfconfig.Module{
+				Variables: map[string]*tfconfig.Variable{"name": {Description: "The name"}, "tags": {Description: " "}},
+				Outputs:   map[string]*tfconfig.Output{"arn": {Description: "The ARN"}},
+			},
+			66,
+		},
+	}
+	for _, test := range tests {
+		if got := DocScore(test.module); got != test.want {
+			t.Errorf("got %d, want %d", got, test.want)
+		}
+	}
+}
+
+func TestLintSummary(t *testing.T) {
+	for n, want := range map[int]string{0: "no warnings", 1: "1 warning", 3: "3 warnings"} {
+		if got := LintSummary(make([]LintFinding, n)); got != want {
+			t.Errorf("got %q for %d findings, want %q", got, n, want)
+		}
+	}
+}
+
+func TestTemplateLintFindings(t *testing.T) {
+	rules, err := ParseLintRules("hashicorp-style", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range TemplateLintFindings(Lint(loadTestModule(t, "lint/bucket"), rules)) {
+		if f.File != "main.tf" {
+			t.Errorf("got file %q for %s, want main.tf", f.File, f.Name)
+		}
+	}
+}
+
+func TestParseLintIgnore(t *testing.T) {
+	got, err := ParseLintIgnore(" legacy_*, ,old ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"legacy_*", "old"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := ParseLintIgnore("legacy_["); err == nil {
+		t.Error("got no error for a bad pattern")
+	}
+}
+
+func TestLintFindingString(t *testing.T) {
+	f := LintFinding{File: "variables.tf", Line: 12, Kind: "variable", Name: "foo", Rule: "description", Message: "has no description"}
+	if got, want := f.String(), `variable "foo" in variables.tf:12 has no description`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLintExemptsDeprecatedModulesFromDescriptions(t *testing.T) {
+	rules := LintRules{VarPattern: regexp.MustCompile("^[a-z_]+$")}
+	tests := []struct {
+		module string
+		want   []string
+	}{
+		{"lint/plain", []string{"description variable Region", "var-pattern variable Region", "description output id"}},
+		{"lint/deprecated-file", []string{"var-pattern variable Region"}},
+		{"lint/deprecated-directive", []string{"var-pattern variable Region"}},
+	}
+	for _, test := range tests {
+		got := findingRules(Lint(loadTestModule(t, test.module), rules))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got findings %q, want %q", test.module, got, test.want)
+		}
+	}
+}
+
+func TestLintNoExperiments(t *testing.T) {
+	tests := []struct {
+		module string
+		rule   string
+		want   []string
+	}{
+		{"experiments", "off", []string{}},
+		{"experiments", "on", []string{"no-experiments experiment module_variable_optional_attrs", "no-experiments experiment config_driven_move"}},
+		{"lint/plain", "on", []string{"description variable Region", "description output id"}},
+	}
+	for _, test := range tests {
+		rules, err := ParseLintRules("none", []string{"no-experiments=" + test.rule})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := findingRules(Lint(loadTestModule(t, test.module), rules))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s with no-experiments=%s: got findings %q, want %q", test.module, test.rule, got, test.want)
+		}
+	}
+}
+
+func TestLintNoUndocumentedBlocks(t *testing.T) {
+	rules, err := ParseLintRules("none", []string{"no-undocumented-blocks=on"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Lint(loadTestModule(t, "coverage"), rules)
+	want := []LintFinding{
+		{File: "testdata/coverage/main.tf", Line: 9, Kind: "block", Name: "moved", Rule: "no-undocumented-blocks", Message: "is a block type the docs don't cover"},
+		{File: "testdata/coverage/main.tf", Line: 18, Kind: "block", Name: "check", Rule: "no-undocumented-blocks", Message: "is a block type the docs don't cover"},
+		{File: "testdata/coverage/moved.tf", Line: 1, Kind: "block", Name: "moved", Rule: "no-undocumented-blocks", Message: "is a block type the docs don't cover"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got findings %+v, want %+v", got, want)
+	}
+
+	rules.NoUndocumentedBlocks = false
+	if got := Lint(loadTestModule(t, "coverage"), rules); len(got) != 0 {
+		t.Errorf("without the rule, got findings %+v", got)
+	}
+}
@@ -0,0 +1,129 @@
+package tfdoc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLang is the language table headings are written in, which needs no catalog
+const DefaultLang = "en"
+
+// HeadingCatalogs translates table headings, keyed by language then English heading. Headings a catalog
+// lacks are left in English.
+var HeadingCatalogs = map[string]map[string]string{
+	DefaultLang: {},
+	"ja": {
+		"Variable":        "変数",
+		"Type":            "型",
+		"Default":         "デフォルト",
+		"Required":        "必須",
+		"Sensitive":       "機密",
+		"Description":     "説明",
+		"Code Position":   "コードの位置",
+		"Output name":     "出力名",
+		"Resource Name":   "リソース名",
+		"Resource Type":   "リソースタイプ",
+		"Dynamic blocks":  "dynamic ブロック",
+		"Provider":        "プロバイダー",
+		"Lookup":          "検索条件",
+		"Aliased":         "エイリアス",
+		"Alias":           "エイリアス",
+		"Declared by":     "宣言元",
+		"Module Name":     "モジュール名",
+		"Module Source":   "モジュールのソース",
+		"Module Version":  "モジュールのバージョン",
+		"Module Location": "モジュールの場所",
+		"Name":            "名前",
+		"Source":          "ソース",
+		"Version":         "バージョン",
+		"Module":          "モジュール",
+		"Inputs":          "入力",
+		"Outputs":         "出力",
+		"Resources":       "リソース",
+		"Requirements":    "要件",
+		"Variables":       "変数",
+		"Data Sources":    "データソース",
+		"Modules":         "モジュール",
+	},
+}
+
+// thousandsSeparators group the digits of the numbers templates humanize, by language. Languages without
+// one use DefaultLang's.
+var thousandsSeparators = map[string]string{
+	DefaultLang: ",",
+	"ja":        ",",
+}
+
+// humanizeInt writes n with its digits grouped in threes as lang does, e.g. 1234 as 1,234
+func humanizeInt(lang string, n int) string {
+	sep, ok := thousandsSeparators[lang]
+	if !ok {
+		sep = thousandsSeparators[DefaultLang]
+	}
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	groups := []string{}
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	return sign + strings.Join(append([]string{digits}, groups...), sep)
+}
+
+func ValidLangs() []string {
+	langs := []string{}
+	for lang := range HeadingCatalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// LocalHeadings translates headings with lang's catalog
+func LocalHeadings(lang string, headings []string) []string {
+	local := make([]string, len(headings))
+	for i, h := range headings {
+		local[i] = h
+		if t, ok := HeadingCatalogs[lang][h]; ok {
+			local[i] = t
+		}
+	}
+	return local
+}
+
+// LocaleOutput renders Template with Lang's headings into Out
+type LocaleOutput struct {
+	Lang, Template, Out string
+}
+
+// ParseLocaleOutputs parses -locale-outputs values like "en=README.tmpl:README.md,ja=README.ja.tmpl:README.ja.md"
+func ParseLocaleOutputs(values []string) ([]LocaleOutput, error) {
+	outputs := []LocaleOutput{}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		for _, entry := range strings.Split(v, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("locale output %q: expected lang=template:out", entry)
+			}
+			if _, ok := HeadingCatalogs[parts[0]]; !ok {
+				return nil, fmt.Errorf("locale output %q: lang %s is not one of: %s", entry, parts[0], ValidLangs())
+			}
+			files := strings.SplitN(parts[1], ":", 2)
+			if len(files) != 2 || files[0] == "" || files[1] == "" {
+				return nil, fmt.Errorf("locale output %q: expected lang=template:out", entry)
+			}
+			if seen[files[1]] {
+				return nil, fmt.Errorf("locale output %q: %s is written twice", entry, files[1])
+			}
+			seen[files[1]] = true
+			outputs = append(outputs, LocaleOutput{parts[0], files[0], files[1]})
+		}
+	}
+	return outputs, nil
+}
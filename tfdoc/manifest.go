@@ -0,0 +1,71 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// manifestActions names each FileChange action in the manifest
+var manifestActions = map[string]string{
+	"create":    "created",
+	"update":    "updated",
+	"unchanged": "unchanged",
+	"prune":     "pruned",
+}
+
+// ManifestEntry describes one file a run wrote or pruned
+type ManifestEntry struct {
+	Path   string `json:"path"`
+	Sha256 string `json:"sha256,omitempty"`
+	Bytes  int    `json:"bytes"`
+	Action string `json:"action"` // created, updated, unchanged or pruned
+}
+
+// WriteManifest records the files w wrote in the JSON manifest at filePath, sorted by path. Entries
+// already in the manifest for other paths are kept, so runs for several modules can share one manifest.
+// The manifest is locked while it's merged.
+func WriteManifest(w *FileWriter, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	unlock, err := lock(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries := make(map[string]ManifestEntry)
+	if b, err := ioutil.ReadFile(filePath); err == nil {
+		var existing []ManifestEntry
+		if err := json.Unmarshal(b, &existing); err != nil {
+			stderr.Printf("replacing %s as it isn't a manifest: %v", filePath, err)
+		}
+		for _, e := range existing {
+			entries[tfpath.Host.Key(e.Path)] = e
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, c := range w.Changes {
+		path := tfpath.Host.Clean(c.Path)
+		entries[tfpath.Host.Key(path)] = ManifestEntry{Path: path, Sha256: c.Sha256, Bytes: c.Bytes, Action: manifestActions[c.Action]}
+	}
+
+	manifest := make([]ManifestEntry, 0, len(entries))
+	for _, e := range entries {
+		manifest = append(manifest, e)
+	}
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Path < manifest[j].Path })
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ReplaceFile(filePath, append(b, '\n'))
+}
@@ -0,0 +1,129 @@
+package tfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var rCellNewline = regexp.MustCompile(`\r\n|\r|\n`)
+
+func MarkdownTableCellEscape(cellText string) string {
+	// Replace Newlines with <br/>
+	cellText = rCellNewline.ReplaceAllString(cellText, "<br>")
+
+	// Escape pipes, unless they already are. Bytes rather than runes are copied, so text that isn't valid
+	// UTF-8 is kept as it is.
+	var escaped strings.Builder
+	backslashes := 0
+	for i := 0; i < len(cellText); i++ {
+		c := cellText[i]
+		if c == '|' && backslashes%2 == 0 {
+			escaped.WriteByte('\\')
+		}
+		if c == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+		escaped.WriteByte(c)
+	}
+	return escaped.String()
+}
+
+func MarkdownTable(headings []string, lengths []string, data [][]string) string {
+	// TODO - input/parameter validation
+	// A builder rather than += keeps tables of thousands of rows linear
+	var table strings.Builder
+	table.WriteString("|")
+	for _, h := range headings {
+		fmt.Fprintf(&table, " %s |", h)
+	}
+	table.WriteString("\n|")
+	for _, l := range lengths {
+		fmt.Fprintf(&table, " %s |", l)
+	}
+
+	for _, d := range data {
+		table.WriteString("\n|")
+		for _, val := range d {
+			fmt.Fprintf(&table, " %s |", MarkdownTableCellEscape(val))
+		}
+	}
+
+	return table.String()
+
+}
+
+var rNumberChunk = regexp.MustCompile(`\d+|\D+`)
+
+// naturalLess compares strings treating runs of digits as numbers, so 2_b.tf sorts before 10_a.tf
+func naturalLess(a, b string) bool {
+	ac := rNumberChunk.FindAllString(a, -1)
+	bc := rNumberChunk.FindAllString(b, -1)
+	for i := 0; i < len(ac) && i < len(bc); i++ {
+		if ac[i] == bc[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(ac[i])
+		bn, bErr := strconv.Atoi(bc[i])
+		if aErr == nil && bErr == nil && an != bn {
+			return an < bn
+		}
+		return ac[i] < bc[i]
+	}
+	return len(ac) < len(bc)
+}
+
+// getSortedKeys orders objects by displayed name, by file then line (position), or by naturally
+// sorted file then line (declaration)
+func getSortedKeys(objs map[string]TableObject, sortBy string) []string {
+	keys := make([]string, 0, len(objs))
+	for k := range objs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if objs[keys[i]].Name != objs[keys[j]].Name {
+			return objs[keys[i]].Name < objs[keys[j]].Name
+		}
+		return keys[i] < keys[j]
+	})
+	if sortBy == "position" || sortBy == "declaration" {
+		sort.SliceStable(keys, func(i, j int) bool {
+			a, b := objs[keys[i]], objs[keys[j]]
+			if a.Filename != b.Filename {
+				if sortBy == "declaration" {
+					return naturalLess(a.Filename, b.Filename)
+				}
+				return a.Filename < b.Filename
+			}
+			return a.Line < b.Line
+		})
+	}
+	return keys
+}
+
+// sourceUrl links to a line of a file in the module, skipping empty parts of the path
+func (o Options) sourceUrl(baseUrl, modulePath, file string, line int) string {
+	return rewriteUrl(o.URLRewrites, rawSourceUrl(baseUrl, modulePath, file, line))
+}
+
+// rawSourceUrl is sourceUrl before -repo-url-rewrite is applied
+func rawSourceUrl(baseUrl, modulePath, file string, line int) string {
+	parts := []string{}
+	for _, p := range []string{strings.TrimSuffix(baseUrl, "/"), strings.Trim(modulePath, "/"), file} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return fmt.Sprintf("%s#L%d", strings.Join(parts, "/"), line)
+}
+
+func codeSpan(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("`%s`", s)
+}
@@ -0,0 +1,102 @@
+package tfdoc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2_network.tf", "10_tags.tf", true},
+		{"10_tags.tf", "2_network.tf", false},
+		{"00_core.tf", "10_network.tf", true},
+		{"1_core.tf", "1_network.tf", true},
+		{"variables.tf", "10_tags.tf", false},
+		{"main.tf", "main.tf", false},
+		{"vars2.tf", "vars10.tf", true},
+	}
+	for _, test := range tests {
+		if got := naturalLess(test.a, test.b); got != test.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSortDeclarationOrdersNumberedFiles(t *testing.T) {
+	module := loadTestModule(t, "declaration")
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"declaration", []string{"name", "environment", "vpc_cidr", "azs", "tags"}},
+		{"position", []string{"tags", "name", "environment", "vpc_cidr", "azs"}},
+		{"name", []string{"azs", "environment", "name", "tags", "vpc_cidr"}},
+	}
+	for _, test := range tests {
+		table, err := VarsTable(module, "", "", Options{Sort: test.sort, Description: "full"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := firstColumn(table); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("-sort %s: got %v, want %v", test.sort, got, test.want)
+		}
+	}
+}
+
+// unescapedPipe reports whether s has a | that isn't escaped by an odd number of backslashes
+func unescapedPipe(s string) bool {
+	backslashes := 0
+	for _, r := range s {
+		if r == '|' && backslashes%2 == 0 {
+			return true
+		}
+		if r == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+	}
+	return false
+}
+
+func FuzzMarkdownTableCellEscape(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"plain text",
+		"a | b",
+		`already \| escaped`,
+		`\\| an escaped backslash`,
+		"line one\nline two\r\nline three\rline four",
+		"kept <br> as it is",
+		"<br\n>",
+		"```hcl\nvariable \"x\" {}\n```",
+		"emoji 🚀 | tables",
+		"<<EOT\nheredoc | text\nEOT",
+		"a lone \r carriage return",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, cell string) {
+		escaped := MarkdownTableCellEscape(cell)
+		if strings.ContainsAny(escaped, "\r\n") {
+			t.Errorf("%q escaped to %q, which has a line break", cell, escaped)
+		}
+		if unescapedPipe(escaped) {
+			t.Errorf("%q escaped to %q, which has an unescaped |", cell, escaped)
+		}
+		// Each line break becomes one <br>, and the <br>s already there are kept
+		breaks := len(rCellNewline.FindAllStringIndex(cell, -1))
+		if got, want := strings.Count(escaped, "<br>"), strings.Count(cell, "<br>")+breaks; got != want {
+			t.Errorf("%q escaped to %q, with %d <br>s, want %d", cell, escaped, got, want)
+		}
+		// Nothing but the line breaks and backslashes changes
+		strip := strings.NewReplacer(`\`, "")
+		if got, want := strip.Replace(escaped), strip.Replace(rCellNewline.ReplaceAllString(cell, "<br>")); got != want {
+			t.Errorf("%q escaped to %q, which changes more than the line breaks and escapes", cell, escaped)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package tfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	rUnescapedPipe = regexp.MustCompile(`(^|[^\\])\|`)
+	rFenceMarker   = regexp.MustCompile("```|~~~")
+)
+
+// MarkdownWarnings counts the table cells checkTableCell reports, for CheckStrictMarkdown
+type MarkdownWarnings struct {
+	Cells int
+}
+
+// markdownCellProblems describes what in an escaped cell would still break a markdown table
+func markdownCellProblems(cell string) []string {
+	problems := []string{}
+	if rUnescapedPipe.MatchString(cell) {
+		problems = append(problems, "an unescaped | would split the cell")
+	}
+	if strings.ContainsAny(cell, "\r\n") {
+		problem := "a line break would end the row"
+		for _, line := range strings.FieldsFunc(cell, func(r rune) bool { return r == '\r' || r == '\n' })[1:] {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				problem += " and start a heading"
+				break
+			}
+		}
+		problems = append(problems, problem)
+	}
+	if rFenceMarker.MatchString(cell) {
+		problems = append(problems, "a code fence marker could swallow the rest of the document")
+	}
+	return problems
+}
+
+// checkTableCell warns about a cell of o's row that would break the table once rendered, counting it in
+// opts.MarkdownWarnings
+func checkTableCell(table, column string, o TableObject, cell string, opts Options) {
+	for _, problem := range markdownCellProblems(MarkdownTableCellEscape(cell)) {
+		if opts.MarkdownWarnings != nil {
+			opts.MarkdownWarnings.Cells++
+		}
+		stderr.Printf("%s %s (%s:%d): %s column: %s", table, o.Name, o.Filename, o.Line, column, problem)
+	}
+}
+
+// CheckStrictMarkdown fails when -strict-markdown is set and any cell was reported
+func CheckStrictMarkdown(warnings *MarkdownWarnings, strict bool) error {
+	if strict && warnings != nil && warnings.Cells > 0 {
+		return fmt.Errorf("%d table cells would break the markdown, see the warnings above", warnings.Cells)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mediaWikiEscape writes the characters MediaWiki would read as links, templates or formatting as entities
+var mediaWikiEscape = strings.NewReplacer("[", "&#91;", "]", "&#93;", "{", "&#123;", "}", "&#125;", "'", "&#39;", "<", "&lt;", "|", "{{!}}")
+
+// MediaWikiTableCellEscape escapes a cell for a MediaWiki table, with | as {{!}}, keeping line breaks as <br>
+func MediaWikiTableCellEscape(cellText string) string {
+	return rCellNewline.ReplaceAllString(mediaWikiEscape.Replace(cellText), "<br>")
+}
+
+// mediaWikiCell turns a table cell's markdown links, images and code spans into MediaWiki markup and escapes
+// the rest. Links use the [url label] external link syntax, which has no titles, and images are linked to.
+func mediaWikiCell(cell string) string {
+	return convertCellMarkdown(cell, MediaWikiTableCellEscape, func(image bool, text, src, title string) string {
+		return fmt.Sprintf("[%s %s]", strings.Replace(src, " ", "%20", -1), MediaWikiTableCellEscape(text))
+	}, func(c string) string {
+		return fmt.Sprintf("<code>%s</code>", MediaWikiTableCellEscape(c))
+	})
+}
+
+// MediaWikiTable renders a table as a MediaWiki {| class="wikitable" table
+func MediaWikiTable(headings []string, data [][]string) string {
+	escaped := []string{}
+	for _, h := range headings {
+		escaped = append(escaped, MediaWikiTableCellEscape(h))
+	}
+	lines := []string{`{| class="wikitable"`, "! " + strings.Join(escaped, " !! ")}
+	for _, d := range data {
+		row := []string{}
+		for _, val := range d {
+			row = append(row, mediaWikiCell(val))
+		}
+		lines = append(lines, "|-", strings.TrimRight("| "+strings.Join(row, " || "), " "))
+	}
+	lines = append(lines, "|}")
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,20 @@
+package tfdoc
+
+import "testing"
+
+func TestMediaWikiTable(t *testing.T) {
+	got := MediaWikiTable([]string{"Name", "Description"}, [][]string{
+		{"[name](https://example.com/main.tf#L1 \"title\")", "Takes `a|b`, [[not a link]]\nor {{a template}}"},
+		{"![badge](https://example.com/b.svg)", ""},
+	})
+	want := "{| class=\"wikitable\"\n" +
+		"! Name !! Description\n" +
+		"|-\n" +
+		"| [https://example.com/main.tf#L1 name] || Takes <code>a{{!}}b</code>, &#91;&#91;not a link&#93;&#93;<br>or &#123;&#123;a template&#125;&#125;\n" +
+		"|-\n" +
+		"| [https://example.com/b.svg badge] ||\n" +
+		"|}"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMetadataFile is the sidecar file read for .Meta unless -metadata-file says otherwise
+const DefaultMetadataFile = "metadata.yaml"
+
+// LoadMetadata reads the module's sidecar metadata file, relative to the module unless absolute. Any keys
+// are kept as they are. A missing file gives no metadata, unless required keys are given, which must all
+// be present.
+func LoadMetadata(modulePath, file string, required []string) (map[string]interface{}, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(modulePath, file)
+	}
+	meta := map[string]interface{}{}
+	b, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(b, &meta); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if meta == nil {
+			meta = map[string]interface{}{}
+		}
+	}
+
+	missing := []string{}
+	for _, key := range required {
+		if _, ok := meta[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s is missing required keys: %s", file, strings.Join(missing, ", "))
+	}
+	return meta, nil
+}
+
+// metadataValue shows a metadata value in a table cell. Lists are joined with commas and anything more
+// complicated is shown as JSON.
+func metadataValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, metadataValue(item))
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return codeSpan(string(b))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// MetadataTable lists the metadata keys and values, or is empty when there is no metadata
+func MetadataTable(meta map[string]interface{}) string {
+	if len(meta) == 0 {
+		return ""
+	}
+	keys := []string{}
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data := [][]string{}
+	for _, key := range keys {
+		data = append(data, []string{key, metadataValue(meta[key])})
+	}
+	return MarkdownTable([]string{"Key", "Value"}, []string{"----", "--------"}, data)
+}
@@ -0,0 +1,139 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"gopkg.in/yaml.v3"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// ModuleDocSchemaVersion is bumped whenever a field of ModuleDoc is removed or changes meaning
+const ModuleDocSchemaVersion = 1
+
+// DocPosition is where an item is declared, by file name within the module and line
+type DocPosition struct {
+	Filename string `json:"filename" yaml:"filename"`
+	Line     int    `json:"line" yaml:"line"`
+}
+
+type DocVariable struct {
+	Name        string      `json:"name" yaml:"name"`
+	Type        string      `json:"type" yaml:"type"` // As declared, empty when it has none
+	Description string      `json:"description" yaml:"description"`
+	Default     interface{} `json:"default" yaml:"default"`
+	Required    bool        `json:"required" yaml:"required"`
+	Position    DocPosition `json:"position" yaml:"position"`
+}
+
+type DocOutput struct {
+	Name        string      `json:"name" yaml:"name"`
+	Description string      `json:"description" yaml:"description"`
+	Position    DocPosition `json:"position" yaml:"position"`
+}
+
+type DocResource struct {
+	Address  string      `json:"address" yaml:"address"`
+	Type     string      `json:"type" yaml:"type"`
+	Name     string      `json:"name" yaml:"name"`
+	Provider string      `json:"provider" yaml:"provider"` // With its alias, like aws.west, if it has one
+	Position DocPosition `json:"position" yaml:"position"`
+}
+
+type DocModuleCall struct {
+	Name     string      `json:"name" yaml:"name"`
+	Source   string      `json:"source" yaml:"source"`
+	Version  string      `json:"version" yaml:"version"`
+	Position DocPosition `json:"position" yaml:"position"`
+}
+
+// DocTotals counts the items of each list of a ModuleDoc
+type DocTotals struct {
+	Variables        int `json:"variables" yaml:"variables"`
+	Outputs          int `json:"outputs" yaml:"outputs"`
+	ManagedResources int `json:"managed_resources" yaml:"managed_resources"`
+	DataResources    int `json:"data_resources" yaml:"data_resources"`
+	ModuleCalls      int `json:"module_calls" yaml:"module_calls"`
+}
+
+// ModuleDoc is everything the tables show about a module, as data for the Json and Yaml actions. Every
+// list is sorted by name or address, so the same module always gives the same document.
+type ModuleDoc struct {
+	SchemaVersion    int             `json:"schema_version" yaml:"schema_version"`
+	Variables        []DocVariable   `json:"variables" yaml:"variables"`
+	Outputs          []DocOutput     `json:"outputs" yaml:"outputs"`
+	ManagedResources []DocResource   `json:"managed_resources" yaml:"managed_resources"`
+	DataResources    []DocResource   `json:"data_resources" yaml:"data_resources"`
+	ModuleCalls      []DocModuleCall `json:"module_calls" yaml:"module_calls"`
+	SkippedFiles     []SkippedFile   `json:"skipped_files,omitempty" yaml:"skipped_files,omitempty"` // Left out by -allow-errors
+	Totals           DocTotals       `json:"totals" yaml:"totals"`                                   // Of the whole module, however -max-items cut the lists
+	Truncated        bool            `json:"truncated,omitempty" yaml:"truncated,omitempty"`         // Whether -max-items left items out of the lists
+	Omitted          *DocTotals      `json:"omitted,omitempty" yaml:"omitted,omitempty"`             // How many items of each list -max-items left out
+	Parts            []string        `json:"parts,omitempty" yaml:"parts,omitempty"`                 // The files -overflow-mode split split the lists into, which are empty here
+	Part             int             `json:"part,omitempty" yaml:"part,omitempty"`                   // Which of those files this is, counting from 1
+}
+
+func docPosition(pos tfconfig.SourcePos) DocPosition {
+	return DocPosition{Filename: tfpath.Base(pos.Filename), Line: pos.Line}
+}
+
+func docResources(resources map[string]*tfconfig.Resource) []DocResource {
+	docs := []DocResource{}
+	for _, r := range resources {
+		provider := r.Provider.Name
+		if r.Provider.Alias != "" {
+			provider += "." + r.Provider.Alias
+		}
+		docs = append(docs, DocResource{Address: r.Type + "." + r.Name, Type: r.Type, Name: r.Name, Provider: provider, Position: docPosition(r.Pos)})
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Address < docs[j].Address })
+	return docs
+}
+
+// BuildModuleDoc collects the module's variables, outputs, resources and module calls
+func BuildModuleDoc(module *tfconfig.Module, skipped []SkippedFile) ModuleDoc {
+	doc := ModuleDoc{
+		SchemaVersion:    ModuleDocSchemaVersion,
+		Variables:        []DocVariable{},
+		Outputs:          []DocOutput{},
+		ManagedResources: docResources(module.ManagedResources),
+		DataResources:    docResources(module.DataResources),
+		ModuleCalls:      []DocModuleCall{},
+		SkippedFiles:     skipped,
+	}
+	for _, v := range module.Variables {
+		doc.Variables = append(doc.Variables, DocVariable{Name: v.Name, Type: v.Type, Description: v.Description, Default: v.Default, Required: v.Required, Position: docPosition(v.Pos)})
+	}
+	sort.Slice(doc.Variables, func(i, j int) bool { return doc.Variables[i].Name < doc.Variables[j].Name })
+	for _, o := range module.Outputs {
+		doc.Outputs = append(doc.Outputs, DocOutput{Name: o.Name, Description: o.Description, Position: docPosition(o.Pos)})
+	}
+	sort.Slice(doc.Outputs, func(i, j int) bool { return doc.Outputs[i].Name < doc.Outputs[j].Name })
+	for _, c := range module.ModuleCalls {
+		doc.ModuleCalls = append(doc.ModuleCalls, DocModuleCall{Name: c.Name, Source: c.Source, Version: c.Version, Position: docPosition(c.Pos)})
+	}
+	sort.Slice(doc.ModuleCalls, func(i, j int) bool { return doc.ModuleCalls[i].Name < doc.ModuleCalls[j].Name })
+	doc.Totals = DocTotals{
+		Variables:        len(doc.Variables),
+		Outputs:          len(doc.Outputs),
+		ManagedResources: len(doc.ManagedResources),
+		DataResources:    len(doc.DataResources),
+		ModuleCalls:      len(doc.ModuleCalls),
+	}
+	return doc
+}
+
+// ModuleJson is a ModuleDoc as indented JSON. Objects in defaults have their keys sorted by
+// encoding/json, so the output is deterministic.
+func ModuleJson(doc ModuleDoc) (string, error) {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	return string(b), err
+}
+
+// ModuleYaml is a ModuleDoc as YAML, with the same fields as ModuleJson
+func ModuleYaml(doc ModuleDoc) (string, error) {
+	b, err := yaml.Marshal(doc)
+	return string(b), err
+}
@@ -0,0 +1,88 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+var ValidNameKinds = []string{
+	"vars",
+	"outputs",
+	"resources",
+	"data",
+	"modules",
+}
+
+// nameSeparators are what -separator may be, and what each puts between names
+var nameSeparators = map[string]string{
+	"newline": "\n",
+	"null":    "\x00",
+	"comma":   ",",
+}
+
+var ValidSeparators = []string{
+	"newline",
+	"null",
+	"comma",
+}
+
+// namePrefixes turn the keys of the tables' rows into the addresses scripts would use for them
+var namePrefixes = map[string]string{
+	"data":    "data.",
+	"modules": "module.",
+}
+
+// nameItems lists the rows of kind's table, from the same items the table is built from
+func nameItems(module *tfconfig.Module, kind string, requiredOnly bool) []tableItem {
+	switch kind {
+	case "vars":
+		items := []tableItem{}
+		for _, item := range variableItems(module, nil) {
+			if requiredOnly && item.Object.Required != "yes" {
+				continue
+			}
+			items = append(items, item)
+		}
+		return items
+	case "outputs":
+		return outputItems(module)
+	case "resources":
+		return resourceItems(module.ManagedResources, nil)
+	case "data":
+		return dataSourceItems(module, nil)
+	case "modules":
+		return moduleCallItems(module)
+	}
+	return nil
+}
+
+// Names lists the names of kind in the module in -sort order, for scripts. Each name is followed by
+// the separator, so the output of -separator null suits xargs -0, except for comma, which only goes
+// between names. Resources, data sources and module calls are named by their full addresses. The names are
+// those of the rows of kind's table, in the same order.
+func Names(module *tfconfig.Module, kind string, requiredOnly bool, separator string, opts Options) string {
+	objs := make(map[string]TableObject)
+	for _, item := range nameItems(module, kind, requiredOnly) {
+		o := item.Object
+		o.Filename = tfpath.Base(item.Pos.Filename)
+		o.Line = item.Pos.Line
+		objs[tableKey(kind, o)] = o
+	}
+	names := []string{}
+	for _, key := range getSortedKeys(objs, opts.Sort) {
+		names = append(names, namePrefixes[kind]+key)
+	}
+	sep := nameSeparators[separator]
+	if separator == "comma" {
+		return strings.Join(names, sep)
+	}
+	var out strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&out, "%s%s", name, sep)
+	}
+	return out.String()
+}
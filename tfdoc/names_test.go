@@ -0,0 +1,80 @@
+package tfdoc
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// tableAddresses are the addresses of the rows of a table rendered as CSV, in order, built from its
+// name and, for resources and data sources, type columns
+func tableAddresses(t *testing.T, kind, table string) []string {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the %s table: %v", kind, err)
+	}
+	addresses := []string{}
+	for _, record := range records[1:] {
+		address := record[0]
+		if kind == "resources" || kind == "data" {
+			address = record[1] + "." + address
+		}
+		addresses = append(addresses, namePrefixes[kind]+address)
+	}
+	return addresses
+}
+
+func TestNamesMatchTableRows(t *testing.T) {
+	module := loadTestModule(t, "names")
+	tables := map[string]func(*tfconfig.Module, string, string, Options) (string, error){
+		"vars":      VarsTable,
+		"outputs":   OutputsTable,
+		"resources": ManagedResourcesTable,
+		"data":      DataSourcesTable,
+		"modules":   ModulesTable,
+	}
+	for _, kind := range ValidNameKinds {
+		for _, sort := range ValidSorts {
+			t.Run(kind+"/"+sort, func(t *testing.T) {
+				opts := Options{Sort: sort, Description: "full", Format: "csv"}
+				names := strings.Split(Names(module, kind, false, "comma", opts), ",")
+				table, err := tables[kind](module, "", "", opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rows := tableAddresses(t, kind, table)
+				if !reflect.DeepEqual(names, rows) {
+					t.Errorf("Names gave %v, the table's rows are %v", names, rows)
+				}
+			})
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	module := loadTestModule(t, "names")
+	tests := []struct {
+		kind         string
+		requiredOnly bool
+		separator    string
+		want         string
+	}{
+		{"vars", false, "newline", "count_per_zone\nname\nzone\n"},
+		{"vars", true, "newline", "count_per_zone\nzone\n"},
+		{"vars", true, "null", "count_per_zone\x00zone\x00"},
+		{"outputs", false, "comma", "bucket,role_arn"},
+		{"resources", false, "newline", "aws_iam_role.admin\naws_s3_bucket.archive\naws_iam_role.this\naws_s3_bucket.this\n"},
+		{"data", false, "comma", "data.aws_caller_identity.current,data.aws_region.current"},
+		{"modules", false, "comma", "module.app,module.network"},
+	}
+	for _, test := range tests {
+		got := Names(module, test.kind, test.requiredOnly, test.separator, Options{Sort: "name"})
+		if got != test.want {
+			t.Errorf("Names(%s, required only %v, %s) = %q, want %q", test.kind, test.requiredOnly, test.separator, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrgTableCellEscape escapes what would end an org-mode table cell. Org tables can't break lines in a
+// cell, so line breaks become spaces.
+func OrgTableCellEscape(cellText string) string {
+	cellText = rCellNewline.ReplaceAllString(cellText, " ")
+	return strings.Replace(cellText, "|", `\vert{}`, -1)
+}
+
+// orgLinkEscape escapes the brackets that would end an org-mode link's target or label
+var orgLinkEscape = strings.NewReplacer("[", "{", "]", "}")
+
+// orgCell turns a table cell's markdown links, images and code spans into org-mode markup and escapes the
+// rest. Org links have no titles, and images are linked to.
+func orgCell(cell string) string {
+	return convertCellMarkdown(cell, OrgTableCellEscape, func(image bool, text, src, title string) string {
+		return fmt.Sprintf("[[%s][%s]]", orgLinkEscape.Replace(src), orgLinkEscape.Replace(OrgTableCellEscape(text)))
+	}, func(c string) string {
+		return fmt.Sprintf("~%s~", OrgTableCellEscape(c))
+	})
+}
+
+// OrgTable renders a table as an org-mode table, with a |---+---| line under the headings
+func OrgTable(headings []string, data [][]string) string {
+	escaped, rules := []string{}, []string{}
+	for _, h := range headings {
+		escaped = append(escaped, OrgTableCellEscape(h))
+		rules = append(rules, strings.Repeat("-", len(h)+2))
+	}
+	lines := []string{"| " + strings.Join(escaped, " | ") + " |", "|" + strings.Join(rules, "+") + "|"}
+	for _, d := range data {
+		row := []string{}
+		for _, val := range d {
+			row = append(row, orgCell(val))
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+	}
+	return strings.Join(lines, "\n")
+}
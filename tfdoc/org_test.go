@@ -0,0 +1,17 @@
+package tfdoc
+
+import "testing"
+
+func TestOrgTable(t *testing.T) {
+	got := OrgTable([]string{"Name", "Description"}, [][]string{
+		{"[name](https://example.com/main.tf#L1 \"title\")", "Takes `a|b`\nor [x]"},
+		{"![badge](https://example.com/b.svg)", ""},
+	})
+	want := "| Name | Description |\n" +
+		"|------+-------------|\n" +
+		"| [[https://example.com/main.tf#L1][name]] | Takes ~a\\vert{}b~ or [x] |\n" +
+		"| [[https://example.com/b.svg][badge]] |  |"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
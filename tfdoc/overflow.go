@@ -0,0 +1,211 @@
+package tfdoc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// ValidOverflowModes are what -overflow-mode does with lists longer than -max-items
+var ValidOverflowModes = []string{
+	"truncate",
+	"split",
+}
+
+// OverflowTables are the table actions -max-items limits with -format csv, and the names their parts are
+// written under
+var OverflowTables = map[string]string{
+	"VarsTable":              "vars",
+	"OutputsTable":           "outputs",
+	"ManagedResourcesTable":  "resources",
+	"DataSourcesTable":       "data",
+	"ModulesTable":           "modules",
+	"RequirementsTable":      "requirements",
+	"RequiredProvidersTable": "required_providers",
+	"ProvidersTable":         "providers",
+}
+
+// partName is the file the nth part of a split list, counting from 1, is written to, e.g. MODULE_2.json
+func partName(name string, n int, ext string) string {
+	return fmt.Sprintf("%s_%d%s", strings.ToUpper(name), n, ext)
+}
+
+// partCount is how many parts of at most max items total items need
+func partCount(total, max int) int {
+	return (total + max - 1) / max
+}
+
+// partBounds are where the nth part of a list, counting from 1, starts and ends, both empty when the list
+// has fewer parts
+func partBounds(n, max, total int) (int, int) {
+	start, end := (n-1)*max, n*max
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// moduleDocPart is the nth part of doc, counting from 1, holding the nth max items of each list. Its totals
+// are still the whole module's.
+func moduleDocPart(doc ModuleDoc, n, max int) ModuleDoc {
+	part := doc
+	s, e := partBounds(n, max, len(doc.Variables))
+	part.Variables = doc.Variables[s:e]
+	s, e = partBounds(n, max, len(doc.Outputs))
+	part.Outputs = doc.Outputs[s:e]
+	s, e = partBounds(n, max, len(doc.ManagedResources))
+	part.ManagedResources = doc.ManagedResources[s:e]
+	s, e = partBounds(n, max, len(doc.DataResources))
+	part.DataResources = doc.DataResources[s:e]
+	s, e = partBounds(n, max, len(doc.ModuleCalls))
+	part.ModuleCalls = doc.ModuleCalls[s:e]
+	return part
+}
+
+// moduleDocParts is how many parts of at most max items each of doc's lists need
+func moduleDocParts(doc ModuleDoc, max int) int {
+	parts := 0
+	for _, total := range []int{doc.Totals.Variables, doc.Totals.Outputs, doc.Totals.ManagedResources, doc.Totals.DataResources, doc.Totals.ModuleCalls} {
+		if n := partCount(total, max); n > parts {
+			parts = n
+		}
+	}
+	return parts
+}
+
+// TruncateModuleDoc keeps the first max items of each of doc's lists. When any are left out the document is
+// marked truncated, with how many of each, and its totals still count them.
+func TruncateModuleDoc(doc ModuleDoc, max int) ModuleDoc {
+	if moduleDocParts(doc, max) <= 1 {
+		return doc
+	}
+	truncated := moduleDocPart(doc, 1, max)
+	truncated.Truncated = true
+	truncated.Omitted = &DocTotals{
+		Variables:        doc.Totals.Variables - len(truncated.Variables),
+		Outputs:          doc.Totals.Outputs - len(truncated.Outputs),
+		ManagedResources: doc.Totals.ManagedResources - len(truncated.ManagedResources),
+		DataResources:    doc.Totals.DataResources - len(truncated.DataResources),
+		ModuleCalls:      doc.Totals.ModuleCalls - len(truncated.ModuleCalls),
+	}
+	return truncated
+}
+
+// WriteModuleDocParts splits doc into parts with at most max items in each list, marshalled into dir as
+// MODULE_1.json and so on, and returns the manifest to print in its place: doc with empty lists, its totals,
+// and the parts' paths relative to linkDir. A doc that fits in one part is returned as it is. Parts it no
+// longer needs are removed with prune.
+func WriteModuleDocParts(w *FileWriter, doc ModuleDoc, max int, dir, linkDir, ext string, prune bool, marshal func(ModuleDoc) (string, error)) (ModuleDoc, error) {
+	parts := moduleDocParts(doc, max)
+	written := []string{}
+	manifest := doc
+	if parts > 1 {
+		manifest = moduleDocPart(doc, parts+1, max)
+		for n := 1; n <= parts; n++ {
+			part := moduleDocPart(doc, n, max)
+			part.Part = n
+			content, err := marshal(part)
+			if err != nil {
+				return doc, err
+			}
+			file := filepath.Join(dir, partName("module", n, ext))
+			if err := w.WriteFile(file, []byte(content+"\n")); err != nil {
+				return doc, err
+			}
+			written = append(written, file)
+			link, err := tfpath.Rel(linkDir, file)
+			if err != nil {
+				return doc, err
+			}
+			manifest.Parts = append(manifest.Parts, link)
+		}
+	}
+	if prune {
+		if err := pruneStaleTablePages(w, dir, "module", ext, written); err != nil {
+			return doc, err
+		}
+	}
+	return manifest, nil
+}
+
+// csvRecords reads a table rendered by CsvTable back into its header and rows
+func csvRecords(table string) ([]string, [][]string, error) {
+	records, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, nil, err
+	}
+	return records[0], records[1:], nil
+}
+
+// csvTable writes records already reduced to text as a CSV table, as CsvTable would
+func csvTable(header []string, rows [][]string) (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(append([][]string{header}, rows...)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// TruncateCsvTable keeps the header and first max rows of a table rendered by CsvTable, and how many rows it
+// had, so the caller can say how many were left out
+func TruncateCsvTable(table string, max int) (string, int, error) {
+	header, rows, err := csvRecords(table)
+	if err != nil || len(rows) <= max {
+		return table, len(rows), err
+	}
+	truncated, err := csvTable(header, rows[:max])
+	return truncated, len(rows), err
+}
+
+// WriteCsvTableParts splits a table rendered by CsvTable into parts of at most max rows, each with the
+// header, written into dir as VARS_1.csv and so on, and returns a manifest table of the parts' paths relative
+// to linkDir and their rows, with the total last. A table that fits in one part is returned as it is. Parts
+// it no longer needs are removed with prune.
+func WriteCsvTableParts(w *FileWriter, table, name string, max int, dir, linkDir string, prune bool) (string, error) {
+	header, rows, err := csvRecords(table)
+	if err != nil {
+		return "", err
+	}
+	parts := partCount(len(rows), max)
+	written := []string{}
+	manifest := table
+	if parts > 1 {
+		data := [][]string{}
+		for n := 1; n <= parts; n++ {
+			s, e := partBounds(n, max, len(rows))
+			file := filepath.Join(dir, partName(name, n, ".csv"))
+			part, err := csvTable(header, rows[s:e])
+			if err != nil {
+				return "", err
+			}
+			if err := w.WriteFile(file, []byte(part+"\n")); err != nil {
+				return "", err
+			}
+			written = append(written, file)
+			link, err := tfpath.Rel(linkDir, file)
+			if err != nil {
+				return "", err
+			}
+			data = append(data, []string{link, strconv.Itoa(e - s)})
+		}
+		data = append(data, []string{"Total", strconv.Itoa(len(rows))})
+		if manifest, err = csvTable([]string{"File", "Rows"}, data); err != nil {
+			return "", err
+		}
+	}
+	if prune {
+		if err := pruneStaleTablePages(w, dir, name, ".csv", written); err != nil {
+			return "", err
+		}
+	}
+	return manifest, nil
+}
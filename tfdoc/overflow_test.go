@@ -0,0 +1,100 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func overflowDoc() ModuleDoc {
+	doc := ModuleDoc{
+		Variables: []DocVariable{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+		Outputs:   []DocOutput{{Name: "id"}},
+	}
+	doc.Totals = DocTotals{Variables: 3, Outputs: 1}
+	return doc
+}
+
+func TestTruncateModuleDoc(t *testing.T) {
+	doc := overflowDoc()
+	if got := TruncateModuleDoc(doc, 3); !reflect.DeepEqual(got, doc) {
+		t.Errorf("a document that fits was changed to %+v", got)
+	}
+
+	got := TruncateModuleDoc(doc, 2)
+	if !got.Truncated || len(got.Variables) != 2 || len(got.Outputs) != 1 {
+		t.Errorf("got %+v", got)
+	}
+	if want := (DocTotals{Variables: 1}); got.Omitted == nil || *got.Omitted != want {
+		t.Errorf("got omitted %+v, want %+v", got.Omitted, want)
+	}
+	if got.Totals != doc.Totals {
+		t.Errorf("got totals %+v, want %+v", got.Totals, doc.Totals)
+	}
+}
+
+func TestWriteModuleDocParts(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "MODULE_3.json")
+	if err := ioutil.WriteFile(stale, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := &FileWriter{Allowed: []string{dir}}
+	manifest, err := WriteModuleDocParts(w, overflowDoc(), 2, dir, dir, ".json", true, ModuleJson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"MODULE_1.json", "MODULE_2.json"}; !reflect.DeepEqual(manifest.Parts, want) {
+		t.Errorf("got parts %v, want %v", manifest.Parts, want)
+	}
+	if len(manifest.Variables) != 0 || len(manifest.Outputs) != 0 || manifest.Totals.Variables != 3 {
+		t.Errorf("got manifest %+v", manifest)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "MODULE_2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ModuleJson(ModuleDoc{Variables: []DocVariable{{Name: "c"}}, Outputs: []DocOutput{}, Totals: DocTotals{Variables: 3, Outputs: 1}, Part: 2})
+	if string(b) != want+"\n" {
+		t.Errorf("got part:\n%s\nwant:\n%s", b, want)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("the stale part wasn't pruned")
+	}
+}
+
+func TestTruncateCsvTable(t *testing.T) {
+	table := "Name,Type\na,string\n\"b, c\",number"
+	got, total, err := TruncateCsvTable(table, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Name,Type\na,string"; got != want || total != 2 {
+		t.Errorf("got %q and %d rows, want %q and 2", got, total, want)
+	}
+	if got, _, _ := TruncateCsvTable(table, 2); got != table {
+		t.Errorf("a table that fits was changed to %q", got)
+	}
+}
+
+func TestWriteCsvTableParts(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{Allowed: []string{dir}}
+	got, err := WriteCsvTableParts(w, "Name\na\nb\nc", "vars", 2, dir, filepath.Dir(dir), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Base(dir)
+	if want := "File,Rows\n" + base + "/VARS_1.csv,2\n" + base + "/VARS_2.csv,1\nTotal,3"; got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "VARS_2.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Name\nc\n"; string(b) != want {
+		t.Errorf("got part %q, want %q", b, want)
+	}
+}
@@ -0,0 +1,199 @@
+package tfdoc
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// CodeownersPaths are where GitHub and GitLab look for CODEOWNERS, relative to the repository root
+var CodeownersPaths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// Owner is a user, team or email address from CODEOWNERS
+type Owner struct {
+	Handle string
+	Url    string
+	title  string // The link's title, with Options.LinkTitles
+}
+
+// String renders the owner as a markdown link when there is a Url
+func (o Owner) String() string {
+	if o.Url == "" {
+		return o.Handle
+	}
+	return markdownLink(o.Handle, o.Url, o.title)
+}
+
+type codeownersRule struct {
+	Pattern *regexp.Regexp
+	Owners  []string
+}
+
+// FindRepoRoot returns the nearest directory at or above dir containing .git
+func FindRepoRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// codeownersPattern converts a CODEOWNERS pattern to a regexp over slash separated paths relative to
+// the repository root. As in .gitignore, a pattern containing a slash is anchored to the root, ** matches
+// any number of directories and a pattern matching a directory matches everything in it.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var re strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			re.WriteString("[^/]*")
+		case pattern[i] == '?':
+			re.WriteString("[^/]")
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	prefix := "^"
+	if !anchored {
+		prefix = "^(?:.*/)?"
+	}
+	return regexp.Compile(prefix + re.String() + "(?:/.*)?$")
+}
+
+// parseCodeowners reads the rules of a CODEOWNERS file in order. GitLab section headings are skipped.
+func parseCodeowners(filePath string) ([]codeownersRule, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := []codeownersRule{}
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "[") || strings.HasPrefix(text, "^[") {
+			continue
+		}
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		re, err := codeownersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad pattern %q: %v", filePath, line, fields[0], err)
+		}
+		rules = append(rules, codeownersRule{Pattern: re, Owners: fields[1:]})
+	}
+	return rules, s.Err()
+}
+
+// ownerUrl links a handle using the conventions of the forge hosting repoUrl. GitLab is assumed when
+// its host mentions gitlab, otherwise GitHub. The link is rewritten by rewrites.
+func ownerUrl(handle, repoUrl string, rewrites []URLRewrite) string {
+	if strings.Contains(handle, "@") && !strings.HasPrefix(handle, "@") {
+		return "mailto:" + handle
+	}
+	u, err := url.Parse(repoUrl)
+	if repoUrl == "" || err != nil || u.Host == "" {
+		return ""
+	}
+	base := u.Scheme + "://" + u.Host
+	name := strings.TrimPrefix(handle, "@")
+	if strings.Contains(u.Host, "gitlab") {
+		return rewriteUrl(rewrites, base+"/"+name)
+	}
+	if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+		return rewriteUrl(rewrites, fmt.Sprintf("%s/orgs/%s/teams/%s", base, parts[0], parts[1]))
+	}
+	return rewriteUrl(rewrites, base+"/"+name)
+}
+
+// FindOwners returns the owners CODEOWNERS gives the module at modulePath: those of the last rule
+// matching the module directory or one of its .tf files. It returns no owners when the module is not in
+// a git repository or the repository has no CODEOWNERS. Their links follow opts.
+func FindOwners(modulePath, repoUrl string, opts Options) ([]Owner, error) {
+	root, ok := FindRepoRoot(modulePath)
+	if !ok {
+		return []Owner{}, nil
+	}
+	var rules []codeownersRule
+	for _, p := range CodeownersPaths {
+		var err error
+		rules, err = parseCodeowners(filepath.Join(root, p))
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	dir, err := tfpath.Rel(root, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{dir}
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		paths = append(paths, path.Join(dir, tfpath.Base(f)))
+	}
+
+	var handles []string
+	for _, rule := range rules {
+		for _, p := range paths {
+			if rule.Pattern.MatchString(p) {
+				handles = rule.Owners
+				break
+			}
+		}
+	}
+
+	owners := []Owner{}
+	for _, h := range handles {
+		owner := Owner{Handle: h, Url: ownerUrl(h, repoUrl, opts.URLRewrites)}
+		if opts.LinkTitles {
+			owner.title = "Owner " + h
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
@@ -0,0 +1,118 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const codeownersFixture = `# Everything else
+* @acme/platform
+
+modules/** @acme/infra
+/modules/network/ @acme/network
+**/iam/ @acme/security
+modules/network/legacy/*.tf @alice ops@example.com # Until it's removed
+
+[Docs]
+docs/ @acme/writers
+`
+
+// writeRepo creates a git repository under a temporary directory with each of files, and a .tf file in
+// each of modules
+func writeRepo(t *testing.T, files map[string]string, modules []string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string]string{}
+	for name, content := range files {
+		paths[name] = content
+	}
+	for _, m := range modules {
+		paths[filepath.Join(m, "main.tf")] = ""
+	}
+	for name, content := range paths {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFindOwners(t *testing.T) {
+	modules := []string{".", "modules/storage", "modules/network", "modules/network/legacy", "modules/network/iam", "modules/apps/iam", "examples/basic"}
+	tests := []struct {
+		name       string
+		codeowners map[string]string
+		module     string
+		want       []string
+	}{
+		{"the repository root", map[string]string{".github/CODEOWNERS": codeownersFixture}, ".", []string{"@acme/platform"}},
+		{"a globstar under a directory", map[string]string{".github/CODEOWNERS": codeownersFixture}, "modules/storage", []string{"@acme/infra"}},
+		{"a later anchored directory", map[string]string{".github/CODEOWNERS": codeownersFixture}, "modules/network", []string{"@acme/network"}},
+		{"a file pattern in a nested module", map[string]string{".github/CODEOWNERS": codeownersFixture}, "modules/network/legacy", []string{"@alice", "ops@example.com"}},
+		{"a leading globstar", map[string]string{".github/CODEOWNERS": codeownersFixture}, "modules/network/iam", []string{"@acme/security"}},
+		{"a leading globstar deeper down", map[string]string{".github/CODEOWNERS": codeownersFixture}, "modules/apps/iam", []string{"@acme/security"}},
+		{"only the catch all", map[string]string{".github/CODEOWNERS": codeownersFixture}, "examples/basic", []string{"@acme/platform"}},
+		{"no rule matches", map[string]string{"CODEOWNERS": "modules/network/ @acme/network\n"}, "modules/storage", []string{}},
+		{"a rule without owners", map[string]string{"CODEOWNERS": "modules/ @acme/infra\nmodules/storage/\n"}, "modules/storage", []string{}},
+		{"the first CODEOWNERS found", map[string]string{".github/CODEOWNERS": "* @github\n", "CODEOWNERS": "* @root\n"}, ".", []string{"@github"}},
+		{"no CODEOWNERS", map[string]string{}, "modules/storage", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := writeRepo(t, test.codeowners, modules)
+			owners, err := FindOwners(filepath.Join(root, test.module), "", Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, o := range owners {
+				got = append(got, o.Handle)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got owners %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindOwnersOutsideARepository(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @acme/platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	owners, err := FindOwners(dir, "", Options{})
+	if err != nil || len(owners) != 0 {
+		t.Errorf("got owners %v and error %v, want none", owners, err)
+	}
+}
+
+func TestOwnerUrl(t *testing.T) {
+	tests := []struct {
+		handle  string
+		repoUrl string
+		want    string
+	}{
+		{"@alice", "https://github.com/acme/modules", "https://github.com/alice"},
+		{"@acme/platform", "https://github.com/acme/modules", "https://github.com/orgs/acme/teams/platform"},
+		{"@acme/platform", "https://gitlab.com/acme/modules", "https://gitlab.com/acme/platform"},
+		{"@alice", "https://gitlab.example.com/acme/modules", "https://gitlab.example.com/alice"},
+		{"ops@example.com", "https://github.com/acme/modules", "mailto:ops@example.com"},
+		{"ops@example.com", "", "mailto:ops@example.com"},
+		{"@alice", "", ""},
+	}
+	for _, test := range tests {
+		if got := ownerUrl(test.handle, test.repoUrl, nil); got != test.want {
+			t.Errorf("ownerUrl(%s, %s) = %q, want %q", test.handle, test.repoUrl, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package tfdoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// paginatedTable is a table -paginate can split into pages
+type paginatedTable struct {
+	Title string
+	Items func(module *tfconfig.Module) []tableItem
+}
+
+var paginatedTables = map[string]paginatedTable{
+	"vars": {"Terraform Variables", func(module *tfconfig.Module) []tableItem {
+		sensitive, err := FindSensitiveVariables(module.Path)
+		if err != nil {
+			stderr.Printf("not checking for sensitive variables: %v", err)
+		}
+		return variableItems(module, sensitive)
+	}},
+	"outputs": {"Terraform Outputs", outputItems},
+	"resources": {"Terraform Managed resources", func(module *tfconfig.Module) []tableItem {
+		return resourceItems(module.ManagedResources, nil)
+	}},
+	"data": {"Terraform Data sources", func(module *tfconfig.Module) []tableItem {
+		return resourceItems(module.DataResources, nil)
+	}},
+	"modules": {"Terraform Modules", moduleCallItems},
+}
+
+// ParsePaginate parses -paginate values like "resources=500" into the most rows a page of each table may have
+func ParsePaginate(values []string) (map[string]int, error) {
+	sizes := make(map[string]int)
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("paginate %q: expected table=rows", v)
+		}
+		if _, ok := paginatedTables[parts[0]]; !ok {
+			return nil, fmt.Errorf("paginate %q: unknown table %q, expected one of %s", v, parts[0], ColumnTableNames())
+		}
+		rows, err := strconv.Atoi(parts[1])
+		if err != nil || rows < 1 {
+			return nil, fmt.Errorf("paginate %q: %q is not a positive number", v, parts[1])
+		}
+		sizes[parts[0]] = rows
+	}
+	return sizes, nil
+}
+
+// tablePageName is the file the table's nth page, counting from 1, is written to, e.g. RESOURCES_2.md
+func tablePageName(table string, n int) string {
+	return fmt.Sprintf("%s_%d.md", strings.ToUpper(table), n)
+}
+
+// WriteTablePages splits table into pages of at most pageSize rows, in -out-dir or else beside the module,
+// and returns a summary linking to them for the main document. Tables that fit on one page are left
+// alone and "" is returned. Pages the table no longer needs are removed with -prune. When no repoUrl is
+// set the source links are relative to the pages.
+func WriteTablePages(w *FileWriter, module *tfconfig.Module, table string, pageSize int, pageOpts PageOptions, opts Options) (string, error) {
+	pageDir := pageOpts.TfPath
+	if pageOpts.OutDir != "" {
+		pageDir = pageOpts.OutDir
+	}
+	baseUrl, modulePath := pageOpts.RepoUrl, pageOpts.ModulePath
+	if baseUrl == "" {
+		var err error
+		if baseUrl, err = RelativeBaseUrl(pageDir, pageOpts.TfPath); err != nil {
+			return "", err
+		}
+		modulePath = ""
+	}
+
+	objs := collectObjects(table, paginatedTables[table].Items(module), baseUrl, modulePath, opts)
+	keys := getSortedKeys(objs, opts.Sort)
+	written := []string{}
+	summary := ""
+	if len(keys) > pageSize {
+		pages := (len(keys) + pageSize - 1) / pageSize
+		links := []string{}
+		for n := 1; n <= pages; n++ {
+			end := n * pageSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			chunk := make(map[string]TableObject)
+			for _, k := range keys[(n-1)*pageSize : end] {
+				chunk[k] = objs[k]
+			}
+
+			nav := []string{}
+			if n > 1 {
+				nav = append(nav, opts.link("Previous", tablePageName(table, n-1), fmt.Sprintf("Page %d of the %s table", n-1, table)))
+			}
+			if n < pages {
+				nav = append(nav, opts.link("Next", tablePageName(table, n+1), fmt.Sprintf("Page %d of the %s table", n+1, table)))
+			}
+			// Each page's heading names its page, so anchors stay unique when pages are read together
+			rendered, err := renderColumns(table, chunk, opts)
+			if err != nil {
+				return "", err
+			}
+			content := fmt.Sprintf("# %s (page %d of %d)\n\n%s\n\n%s\n", paginatedTables[table].Title, n, pages, strings.Join(nav, " | "), rendered)
+			if pageOpts.HeadingIds == "explicit" {
+				content = string(AddHeadingIDs([]byte(content)))
+			}
+			page := filepath.Join(pageDir, tablePageName(table, n))
+			if err := w.WriteFile(page, []byte(content)); err != nil {
+				return "", err
+			}
+			written = append(written, page)
+
+			link, err := tfpath.Rel(pageOpts.TfPath, page)
+			if err != nil {
+				return "", err
+			}
+			links = append(links, opts.link(fmt.Sprint(n), link, fmt.Sprintf("Page %d of the %s table", n, table)))
+		}
+		summary = fmt.Sprintf("This table has %d rows, split into %d pages: %s.", len(keys), pages, strings.Join(links, ", "))
+	}
+
+	if pageOpts.Prune {
+		if err := pruneStaleTablePages(w, pageDir, table, ".md", written); err != nil {
+			return "", err
+		}
+	}
+	return summary, nil
+}
+
+// pruneStaleTablePages removes the table's pages in dir, those with the extension ext, that are not in keep
+func pruneStaleTablePages(w *FileWriter, dir, table, ext string, keep []string) error {
+	existing, err := filepath.Glob(filepath.Join(dir, strings.ToUpper(table)+"_*"+ext))
+	if err != nil {
+		return err
+	}
+	for _, f := range existing {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(tfpath.Base(f), strings.ToUpper(table)+"_"), ext)
+		if _, err := strconv.Atoi(suffix); err != nil || StringInSlice(f, keep) {
+			continue
+		}
+		if err := w.Remove(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
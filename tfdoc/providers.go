@@ -0,0 +1,165 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// Where a provider configuration comes from, for the ProvidersTable's Declared by column
+const (
+	providerFromBlock     = "provider block"
+	providerFromAliases   = "configuration_aliases"
+	providerFromResources = "resources" // Used by resources without a provider block, so inherited from the caller
+)
+
+// ProviderConfig is a provider configuration the module references
+type ProviderConfig struct {
+	Name     string // The provider's local name, e.g. aws
+	Alias    string // Empty for the default configuration
+	From     string // providerFromBlock, providerFromAliases or providerFromResources
+	Filename string
+	Line     int
+}
+
+// Address is the configuration as resources name it, e.g. aws.replica
+func (p ProviderConfig) Address() string {
+	if p.Alias == "" {
+		return p.Name
+	}
+	return p.Name + "." + p.Alias
+}
+
+// configurationAliases are the aliases a required_providers block expects callers to pass in, from its
+// configuration_aliases lists
+func configurationAliases(file string, block *hclsyntax.Block) []ProviderConfig {
+	configs := []ProviderConfig{}
+	for _, attr := range block.Body.Attributes {
+		obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			continue
+		}
+		for _, item := range obj.Items {
+			tuple, ok := item.ValueExpr.(*hclsyntax.TupleConsExpr)
+			if hcl.ExprAsKeyword(item.KeyExpr) != "configuration_aliases" || !ok {
+				continue
+			}
+			for _, expr := range tuple.Exprs {
+				traversal, diags := hcl.AbsTraversalForExpr(expr)
+				if diags.HasErrors() || len(traversal) != 2 {
+					continue
+				}
+				alias, ok := traversal[1].(hcl.TraverseAttr)
+				if !ok {
+					continue
+				}
+				configs = append(configs, ProviderConfig{Name: traversal.RootName(), Alias: alias.Name, From: providerFromAliases, Filename: tfpath.Base(file), Line: expr.Range().Start.Line})
+			}
+		}
+	}
+	return configs
+}
+
+// FindProviderConfigs returns the provider configurations the module references, sorted by name with each
+// default configuration before its aliases: those its provider blocks declare, those configuration_aliases
+// expects callers to pass in, and those its resources use without either. tfconfig doesn't know provider
+// blocks' aliases or configuration_aliases, so the files are parsed here. Files that don't parse are
+// skipped, as tfconfig has already reported them.
+func FindProviderConfigs(module *tfconfig.Module, modulePath string) ([]ProviderConfig, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	// A configuration named in several places is listed where it's declared, a provider block before
+	// configuration_aliases, and otherwise where it's first named
+	rank := map[string]int{providerFromBlock: 0, providerFromAliases: 1, providerFromResources: 2}
+	found := make(map[string]ProviderConfig)
+	add := func(p ProviderConfig) {
+		existing, ok := found[p.Address()]
+		switch {
+		case !ok, rank[p.From] < rank[existing.From]:
+		case rank[p.From] > rank[existing.From]:
+			return
+		case p.Filename > existing.Filename, p.Filename == existing.Filename && p.Line >= existing.Line:
+			return
+		}
+		found[p.Address()] = p
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "provider" && len(block.Labels) == 1:
+				p := ProviderConfig{Name: block.Labels[0], From: providerFromBlock, Filename: tfpath.Base(file), Line: block.DefRange().Start.Line}
+				if alias, ok := block.Body.Attributes["alias"]; ok {
+					p.Alias = strings.Trim(exprSource(src, alias.Expr), `"`)
+				}
+				add(p)
+			case block.Type == "terraform":
+				for _, nested := range block.Body.Blocks {
+					if nested.Type == "required_providers" {
+						for _, p := range configurationAliases(file, nested) {
+							add(p)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, resources := range []map[string]*tfconfig.Resource{module.ManagedResources, module.DataResources} {
+		for _, r := range resources {
+			add(ProviderConfig{Name: r.Provider.Name, Alias: r.Provider.Alias, From: providerFromResources, Filename: tfpath.Base(r.Pos.Filename), Line: r.Pos.Line})
+		}
+	}
+
+	configs := []ProviderConfig{}
+	for _, p := range found {
+		configs = append(configs, p)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		if configs[i].Name != configs[j].Name {
+			return configs[i].Name < configs[j].Name
+		}
+		return configs[i].Alias < configs[j].Alias
+	})
+	return configs, nil
+}
+
+// ProvidersTable lists the module's provider configurations: the default configuration or alias of each,
+// what declares it, and where. Aliases from configuration_aliases are the ones callers must pass in with
+// the module call's providers argument.
+func ProvidersTable(configs []ProviderConfig, baseUrl, modulePath string, opts Options) (string, error) {
+	headings := []string{"Provider", "Alias", "Declared by", "Code Position"}
+	lengths := []string{"--------", "-----", "-----------", "-------------"}
+	data := [][]string{}
+	for _, p := range configs {
+		alias := "default"
+		if p.Alias != "" {
+			alias = codeSpan(p.Alias)
+		}
+		url := opts.sourceUrl(baseUrl, modulePath, p.Filename, p.Line)
+		data = append(data, []string{codeSpan(p.Address()), alias, p.From, opts.link(fmt.Sprintf("%s: %d", p.Filename, p.Line), url, "Declaration of provider "+p.Address())})
+	}
+	return renderTable(opts, LocalHeadings(opts.Lang, headings), lengths, data)
+}
@@ -0,0 +1,70 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func TestFindProviderConfigs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"versions.tf": `terraform {
+  required_providers {
+    aws = {
+      source                = "hashicorp/aws"
+      configuration_aliases = [aws.replica]
+    }
+  }
+}
+`,
+		"main.tf": `provider "aws" {
+  region = "eu-west-1"
+}
+
+resource "aws_s3_bucket" "this" {}
+
+resource "aws_s3_bucket" "replica" {
+  provider = aws.replica
+}
+
+resource "random_id" "suffix" {}
+`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// This tfconfig reports configuration_aliases as an error, but loads the rest of the module
+	module, _ := tfconfig.LoadModule(dir)
+
+	got, err := FindProviderConfigs(module, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ProviderConfig{
+		{Name: "aws", From: providerFromBlock, Filename: "main.tf", Line: 1},
+		{Name: "aws", Alias: "replica", From: providerFromAliases, Filename: "versions.tf", Line: 5},
+		{Name: "random", From: providerFromResources, Filename: "main.tf", Line: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	table, err := ProvidersTable(got, "https://example.com", "", Options{Format: "markdown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTable := "| Provider | Alias | Declared by | Code Position |\n" +
+		"| -------- | ----- | ----------- | ------------- |\n" +
+		"| `aws` | default | provider block | [main.tf: 1](https://example.com/main.tf#L1) |\n" +
+		"| `aws.replica` | `replica` | configuration_aliases | [versions.tf: 5](https://example.com/versions.tf#L5) |\n" +
+		"| `random` | default | resources | [main.tf: 11](https://example.com/main.tf#L11) |"
+	if table != wantTable {
+		t.Errorf("got:\n%s\nwant:\n%s", table, wantTable)
+	}
+}
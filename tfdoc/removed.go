@@ -0,0 +1,88 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// RemovedBlock is a removed {} block, which takes a resource out of the configuration on purpose
+type RemovedBlock struct {
+	Address  string
+	Destroy  bool // False when lifecycle { destroy = false } keeps the real object and only forgets it
+	Filename string
+	Line     int
+}
+
+// exprSource returns the source text of an expression
+func exprSource(src []byte, expr hclsyntax.Expression) string {
+	r := expr.Range()
+	return strings.TrimSpace(string(r.SliceBytes(src)))
+}
+
+// FindRemovedBlocks returns the module's removed blocks, sorted by address. tfconfig doesn't know them,
+// so the files are parsed here. Files that don't parse are skipped, as tfconfig has already reported them.
+func FindRemovedBlocks(modulePath string) ([]RemovedBlock, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []RemovedBlock{}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "removed" {
+				continue
+			}
+			r := RemovedBlock{Destroy: true, Filename: tfpath.Base(file), Line: block.DefRange().Start.Line}
+			if from, ok := block.Body.Attributes["from"]; ok {
+				r.Address = exprSource(src, from.Expr)
+			}
+			for _, nested := range block.Body.Blocks {
+				if destroy, ok := nested.Body.Attributes["destroy"]; ok && nested.Type == "lifecycle" {
+					r.Destroy = exprSource(src, destroy.Expr) != "false"
+				}
+			}
+			removed = append(removed, r)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Address < removed[j].Address })
+	return removed, nil
+}
+
+// RemovedTable lists removed blocks and whether Terraform destroys each resource or only forgets it.
+// It is empty when there are none.
+func RemovedTable(removed []RemovedBlock, baseUrl, modulePath string, opts Options) string {
+	if len(removed) == 0 {
+		return ""
+	}
+	data := [][]string{}
+	for _, r := range removed {
+		destroy := "destroyed"
+		if !r.Destroy {
+			destroy = "forgotten, the object is kept"
+		}
+		url := opts.sourceUrl(baseUrl, modulePath, r.Filename, r.Line)
+		data = append(data, []string{codeSpan(r.Address), destroy, opts.link(fmt.Sprintf("%s: %d", r.Filename, r.Line), url, "Removed block for "+r.Address)})
+	}
+	return MarkdownTable([]string{"Address", "On apply", "Code Position"}, []string{"----", "--------", "------"}, data)
+}
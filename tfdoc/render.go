@@ -0,0 +1,86 @@
+package tfdoc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// DefaultMaxOutputSize is the largest document a template may render unless -max-output-size says otherwise
+const DefaultMaxOutputSize = 10 * 1024 * 1024
+
+// DefaultRenderTimeout bounds how long a run may spend rendering unless -render-timeout says otherwise
+const DefaultRenderTimeout = time.Minute
+
+// limitedWriter fails writes once max bytes have been written or ctx is done, which stops template execution
+type limitedWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	written int64
+	max     int64
+	name    string
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if err := l.ctx.Err(); err != nil {
+		return 0, fmt.Errorf("template %s: rendering stopped: %v", l.name, err)
+	}
+	if l.written+int64(len(p)) > l.max {
+		return 0, fmt.Errorf("template %s: output is over the limit of %d bytes (see -max-output-size)", l.name, l.max)
+	}
+	n, err := l.w.Write(p)
+	l.written += int64(n)
+	return n, err
+}
+
+// ExecuteTemplate renders t into w, failing if the output grows past maxSize bytes or ctx is done
+// first, so recursive or runaway templates fail with an error rather than hanging. Nothing is written to w
+// unless the template renders in full.
+func ExecuteTemplate(ctx context.Context, t *template.Template, data interface{}, w io.Writer, maxSize int64) error {
+	// The template renders into a buffer only its goroutine uses, since after a timeout the goroutine runs
+	// on until the template's next write or function call fails, and is abandoned with its buffer
+	var rendered bytes.Buffer
+	lw := &limitedWriter{ctx: ctx, w: &rendered, max: maxSize, name: t.Name()}
+	done := make(chan error, 1)
+	go func() {
+		done <- t.Execute(lw, data)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(rendered.Bytes())
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("template %s: rendering stopped: %v (see -render-timeout)", t.Name(), ctx.Err())
+	}
+}
+
+// RenderOptions are the settings of one Render
+type RenderOptions struct {
+	ExtraData []ExtraDataFunc // Add their values under .Extra, in order
+	Strict    bool            // Fail when an ExtraData function does, instead of warning and leaving out its values
+	MaxSize   int64           // The largest document the template may render, DefaultMaxOutputSize when 0
+}
+
+// Render renders t with data into w as ExecuteTemplate does, adding the values of opts.ExtraData for
+// module under .Extra
+func Render(ctx context.Context, t *template.Template, module *tfconfig.Module, data DocData, w io.Writer, opts RenderOptions) error {
+	extra, err := CollectExtraData(module, opts)
+	if err != nil {
+		return err
+	}
+	data.Extra = extra
+	maxSize := opts.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxOutputSize
+	}
+	return ExecuteTemplate(ctx, t, data, w, maxSize)
+}
@@ -0,0 +1,93 @@
+package tfdoc
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestExecuteTemplateStopsRunawayTemplates(t *testing.T) {
+	const maxSize = 4096
+	tests := []struct {
+		name    string
+		text    string
+		data    interface{}
+		timeout time.Duration
+		wantErr string
+	}{
+		{
+			"recursion",
+			`{{ define "self" }}again {{ template "self" . }}{{ end }}{{ template "self" . }}`,
+			nil, time.Minute, "template recursion.tmpl: output is over the limit of 4096 bytes (see -max-output-size)",
+		},
+		{
+			"a long loop",
+			`{{ range . }}more output {{ end }}`,
+			make([]struct{}, 1000000), time.Minute, "template a long loop.tmpl: output is over the limit of 4096 bytes (see -max-output-size)",
+		},
+		{
+			"a loop that never ends",
+			`{{ range . }}{{ end }}`,
+			make(chan int), 50 * time.Millisecond, "template a loop that never ends.tmpl: rendering stopped: context deadline exceeded (see -render-timeout)",
+		},
+		{
+			"a template within the limits",
+			`{{ range . }}ok {{ end }}`,
+			make([]struct{}, 3), time.Minute, "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl := template.Must(template.New(test.name + ".tmpl").Parse(test.text))
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+			var out bytes.Buffer
+			start := time.Now()
+			err := ExecuteTemplate(ctx, tmpl, test.data, &out, maxSize)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want %q", err, test.wantErr)
+			}
+			if out.Len() > maxSize {
+				t.Errorf("wrote %d bytes, over the limit of %d", out.Len(), maxSize)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("took %v to stop", elapsed)
+			}
+		})
+	}
+}
+
+func TestExecuteTemplateWritesNothingAfterATimeout(t *testing.T) {
+	// The template keeps writing until its next write fails, after ExecuteTemplate has returned
+	values := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case values <- i:
+				time.Sleep(time.Millisecond)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	tmpl := template.Must(template.New("slow.tmpl").Parse(`{{ range . }}{{ . }} {{ end }}`))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	if err := ExecuteTemplate(ctx, tmpl, values, &out, DefaultMaxOutputSize); err == nil {
+		t.Fatal("got no error from a template that outlasted its timeout")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if out.Len() != 0 {
+		t.Errorf("got %q written for a template that didn't finish", out.String())
+	}
+}
@@ -0,0 +1,75 @@
+package tfdoc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// RequirementsTable combines the Terraform core constraint, language experiments, provider requirements
+// and module call versions into one table: Terraform first, then experiments, providers and modules, each
+// alphabetically.
+func RequirementsTable(module *tfconfig.Module, experiments []string, opts Options) (string, error) {
+	headings := []string{"Name", "Type", "Source", "Version"}
+	lengths := []string{"----", "----", "------", "------"}
+	data := [][]string{}
+
+	if len(module.RequiredCore) > 0 {
+		data = append(data, []string{"terraform", "core", "", constraintCell("terraform", strings.Join(module.RequiredCore, ", "), opts.ConstraintBadges)})
+	}
+	for _, name := range experiments {
+		data = append(data, []string{name, "experiment", "", ""})
+	}
+
+	providers := make([]string, 0, len(module.RequiredProviders))
+	for name := range module.RequiredProviders {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	for _, name := range providers {
+		req := module.RequiredProviders[name]
+		data = append(data, []string{name, "provider", req.Source, constraintCell(name, strings.Join(req.VersionConstraints, ", "), opts.ConstraintBadges)})
+	}
+
+	modules := make([]string, 0, len(module.ModuleCalls))
+	for name := range module.ModuleCalls {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+	for _, name := range modules {
+		call := module.ModuleCalls[name]
+		data = append(data, []string{name, "module", call.Source, constraintCell(name, call.Version, opts.ConstraintBadges)})
+	}
+
+	return renderTable(opts, LocalHeadings(opts.Lang, headings), lengths, data)
+}
+
+// RequirementsRows is how many rows RequirementsTable has
+func RequirementsRows(module *tfconfig.Module, experiments []string) int {
+	rows := len(experiments) + len(module.RequiredProviders) + len(module.ModuleCalls)
+	if len(module.RequiredCore) > 0 {
+		rows++
+	}
+	return rows
+}
+
+// RequiredProvidersTable lists the providers of the module's required_providers blocks alphabetically by
+// local name, with their source addresses and version constraints. Cells a provider doesn't declare are empty.
+func RequiredProvidersTable(module *tfconfig.Module, opts Options) (string, error) {
+	headings := []string{"Provider", "Source", "Version"}
+	lengths := []string{"--------", "------", "-------"}
+	data := [][]string{}
+
+	providers := make([]string, 0, len(module.RequiredProviders))
+	for name := range module.RequiredProviders {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	for _, name := range providers {
+		req := module.RequiredProviders[name]
+		data = append(data, []string{name, req.Source, constraintCell(name, strings.Join(req.VersionConstraints, ", "), opts.ConstraintBadges)})
+	}
+
+	return renderTable(opts, LocalHeadings(opts.Lang, headings), lengths, data)
+}
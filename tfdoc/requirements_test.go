@@ -0,0 +1,24 @@
+package tfdoc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func TestRequiredProvidersTable(t *testing.T) {
+	module := &tfconfig.Module{
+		RequiredProviders: map[string]*tfconfig.ProviderRequirement{
+			"random": {},
+			"aws":    {Source: "hashicorp/aws", VersionConstraints: []string{">= 5.0", "< 6.0"}},
+		},
+	}
+	want := "| Provider | Source | Version |\n| -------- | ------ | ------- |\n| aws | hashicorp/aws | `>= 5.0, < 6.0` |\n| random |  |  |"
+	got, err := RequiredProvidersTable(module, Options{Format: "markdown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
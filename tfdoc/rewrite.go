@@ -0,0 +1,65 @@
+package tfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// URLRewrite replaces the part of a link URL matching Pattern with Replacement, which may use $1 etc.
+type URLRewrite struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// ParseURLRewrites parses -repo-url-rewrite values of the form "regex=replacement". The regex ends at the
+// first =, so a replacement may contain = but a regex can't.
+func ParseURLRewrites(values []string) ([]URLRewrite, error) {
+	rewrites := []URLRewrite{}
+	for _, v := range values {
+		i := strings.Index(v, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("repo url rewrite %q: expected regex=replacement", v)
+		}
+		re, err := regexp.Compile(v[:i])
+		if err != nil {
+			return nil, fmt.Errorf("repo url rewrite %q: %v", v, err)
+		}
+		rewrites = append(rewrites, URLRewrite{re, v[i+1:]})
+	}
+	return rewrites, nil
+}
+
+// link links text to url, with title as the link's title when LinkTitles is set
+func (o Options) link(text, url, title string) string {
+	if !o.LinkTitles {
+		title = ""
+	}
+	return markdownLink(text, url, title)
+}
+
+// markdownLink links text to url, with title as the link's title unless it's empty
+func markdownLink(text, url, title string) string {
+	if title == "" {
+		return fmt.Sprintf("[%s](%s)", text, url)
+	}
+	return fmt.Sprintf("[%s](%s \"%s\")", text, url, strings.Replace(title, `"`, `\"`, -1))
+}
+
+// matchingRewrite is the index of the first of rewrites that matches u, or -1 if none does
+func matchingRewrite(rewrites []URLRewrite, u string) int {
+	for i, r := range rewrites {
+		if r.Pattern.MatchString(u) {
+			return i
+		}
+	}
+	return -1
+}
+
+// rewriteUrl applies the first of rewrites that matches u. URLs no rule matches are returned unchanged.
+func rewriteUrl(rewrites []URLRewrite, u string) string {
+	if i := matchingRewrite(rewrites, u); i >= 0 {
+		return rewrites[i].Pattern.ReplaceAllString(u, rewrites[i].Replacement)
+	}
+	return u
+}
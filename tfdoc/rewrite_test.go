@@ -0,0 +1,86 @@
+package tfdoc
+
+import "testing"
+
+func TestParseURLRewrites(t *testing.T) {
+	tests := []struct {
+		value       string
+		pattern     string
+		replacement string
+		wantErr     bool
+	}{
+		{"https://git.corp/(.*)=https://github.com/corp-mirror/$1", "https://git.corp/(.*)", "https://github.com/corp-mirror/$1", false},
+		{"^http://=https://", "^http://", "https://", false},
+		{"(.*)#L(\\d+)=$1?plain=1#L$2", "(.*)#L(\\d+)", "$1?plain=1#L$2", false},
+		{"blob/main=blob/v1.0=final", "blob/main", "blob/v1.0=final", false},
+		{"git.corp=", "git.corp", "", false},
+		{"https://git.corp", "", "", true},
+		{"=https://github.com", "", "", true},
+		{"https://git.corp/(.*=x", "", "", true},
+	}
+	for _, test := range tests {
+		got, err := ParseURLRewrites([]string{test.value})
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseURLRewrites(%q) gave error %v", test.value, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(got) != 1 || got[0].Pattern.String() != test.pattern || got[0].Replacement != test.replacement {
+			t.Errorf("ParseURLRewrites(%q) = %+v, want %q=%q", test.value, got, test.pattern, test.replacement)
+		}
+	}
+}
+
+func TestRewriteUrl(t *testing.T) {
+	rewrites, err := ParseURLRewrites([]string{
+		"^https://git.corp/platform/(.*)=https://github.com/platform-mirror/$1",
+		"^https://git.corp/([^/]+)/([^/]+)/-/blob/(.*)=https://github.com/corp-mirror/$1-$2/blob/$3",
+		"^https://git.corp/(.*)=https://github.com/corp-mirror/$1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://git.corp/platform/vpc/-/blob/main/main.tf#L3", "https://github.com/platform-mirror/vpc/-/blob/main/main.tf#L3"},
+		{"https://git.corp/team/vpc/-/blob/main/main.tf#L3", "https://github.com/corp-mirror/team-vpc/blob/main/main.tf#L3"},
+		{"https://git.corp/team", "https://github.com/corp-mirror/team"},
+		{"https://github.com/acme/vpc/blob/main/main.tf#L3", "https://github.com/acme/vpc/blob/main/main.tf#L3"},
+		{"main.tf#L3", "main.tf#L3"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := rewriteUrl(rewrites, test.url); got != test.want {
+			t.Errorf("rewriteUrl(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+
+	if got, want := (Options{URLRewrites: rewrites}).sourceUrl("https://git.corp/team/stack/-/blob/main", "modules/vpc", "main.tf", 7), "https://github.com/corp-mirror/team-stack/blob/main/modules/vpc/main.tf#L7"; got != want {
+		t.Errorf("sourceUrl = %q, want %q", got, want)
+	}
+	if got, want := ownerUrl("@acme/platform", "https://git.corp/team/stack", rewrites), "https://github.com/corp-mirror/orgs/acme/teams/platform"; got != want {
+		t.Errorf("ownerUrl = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownLink(t *testing.T) {
+	tests := []struct {
+		titles bool
+		title  string
+		want   string
+	}{
+		{false, "Source of variable name", "[main.tf: 3](main.tf#L3)"},
+		{true, "Source of variable name", `[main.tf: 3](main.tf#L3 "Source of variable name")`},
+		{true, `Source of "quoted"`, `[main.tf: 3](main.tf#L3 "Source of \"quoted\"")`},
+		{true, "", "[main.tf: 3](main.tf#L3)"},
+	}
+	for _, test := range tests {
+		if got := (Options{LinkTitles: test.titles}).link("main.tf: 3", "main.tf#L3", test.title); got != test.want {
+			t.Errorf("markdownLink with -link-titles=%v and title %q = %s, want %s", test.titles, test.title, got, test.want)
+		}
+	}
+}
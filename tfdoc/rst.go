@@ -0,0 +1,59 @@
+package tfdoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rstEscape escapes the characters that start inline markup in reStructuredText
+var rstEscape = strings.NewReplacer(`\`, `\\`, "*", `\*`, "`", "\\`", "|", `\|`, "_", `\_`, "<", `\<`)
+
+// rstCell turns a table cell's markdown links and code spans into reStructuredText and escapes the rest.
+// RST has no inline images or link titles, so images become links to their source and titles are dropped.
+func rstCell(cell string) string {
+	return convertCellMarkdown(cell, rstEscape.Replace, func(image bool, text, src, title string) string {
+		if text == "" {
+			text = src
+		}
+		return fmt.Sprintf("`%s <%s>`__", rstEscape.Replace(text), src)
+	}, func(c string) string {
+		if c == "" {
+			return ""
+		}
+		return fmt.Sprintf("``%s``", c)
+	})
+}
+
+// rstListItem writes a list-table cell as a list item, with the cell's further lines indented under it
+func rstListItem(marker, cell string) []string {
+	lines := strings.Split(strings.Replace(cell, "\r\n", "\n", -1), "\n")
+	indent := strings.Repeat(" ", len(marker))
+	item := []string{strings.TrimRight(marker+lines[0], " ")}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			item = append(item, "")
+			continue
+		}
+		item = append(item, indent+line)
+	}
+	return item
+}
+
+// RstTable renders a table as a reStructuredText list-table with a header row, e.g. for Sphinx
+func RstTable(headings []string, data [][]string) string {
+	lines := []string{".. list-table::", "   :header-rows: 1", ""}
+	for r, row := range append([][]string{headings}, data...) {
+		for i, val := range row {
+			marker := "     - "
+			if i == 0 {
+				marker = "   * - "
+			}
+			cell := rstCell(val)
+			if r == 0 {
+				cell = rstEscape.Replace(val)
+			}
+			lines = append(lines, rstListItem(marker, cell)...)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,113 @@
+package tfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var rCodeFence = regexp.MustCompile("^\\s*(`{3,}|~{3,})")
+
+// codeFence follows the fenced code blocks of a document line by line. As in CommonMark, a block is only
+// closed by a fence of the same character at least as long as the one that opened it, with nothing after it,
+// so a ``` line inside a ~~~ block is code.
+type codeFence struct {
+	open string // The fence that opened the block the last line was in, empty outside one
+}
+
+// skip reports whether line is a fence or code inside a fenced block, and so has no markdown structure
+func (f *codeFence) skip(line string) bool {
+	m := rCodeFence.FindStringSubmatch(line)
+	if f.open == "" {
+		if m != nil {
+			f.open = m[1]
+		}
+		return m != nil
+	}
+	if m != nil && m[1][0] == f.open[0] && len(m[1]) >= len(f.open) && strings.TrimSpace(line[len(m[0]):]) == "" {
+		f.open = ""
+	}
+	return true
+}
+
+type markdownHeading struct {
+	Start, End int // Line range covered by the heading (End differs for setext headers)
+	Level      int
+	Title      string
+}
+
+// findMarkdownHeadings returns every heading in lines, ignoring anything inside fenced code blocks.
+func findMarkdownHeadings(lines []string) []markdownHeading {
+	headings := []markdownHeading{}
+	fence := codeFence{}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if fence.skip(line) {
+			continue
+		}
+		if m := rHashHeader.FindStringSubmatch(line); m != nil {
+			title := strings.TrimSpace(strings.TrimRight(m[2], "#"))
+			headings = append(headings, markdownHeading{Start: i, End: i, Level: len(m[1]), Title: title})
+			continue
+		}
+		if i+1 < len(lines) && strings.TrimSpace(line) != "" {
+			next := lines[i+1]
+			if rUnderscoreHeader1.MatchString(next) {
+				headings = append(headings, markdownHeading{Start: i, End: i + 1, Level: 1, Title: strings.TrimSpace(line)})
+				i++
+			} else if rUnderscoreHeader2.MatchString(next) {
+				headings = append(headings, markdownHeading{Start: i, End: i + 1, Level: 2, Title: strings.TrimSpace(line)})
+				i++
+			}
+		}
+	}
+	return headings
+}
+
+// ExtractMarkdownSection returns the section of a markdown document starting at the heading
+// titled heading and ending before the next heading of the same or higher level.
+// All headings in the section are rewritten in hash style and shifted down by shift levels.
+func ExtractMarkdownSection(d []byte, heading string, shift int) (string, error) {
+	lines := strings.Split(strings.Replace(string(d), "\r\n", "\n", -1), "\n")
+	headings := findMarkdownHeadings(lines)
+
+	found := -1
+	for i, h := range headings {
+		if strings.EqualFold(h.Title, strings.TrimSpace(heading)) {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		return "", fmt.Errorf("heading %q not found", heading)
+	}
+
+	end := len(lines)
+	for _, h := range headings[found+1:] {
+		if h.Level <= headings[found].Level {
+			end = h.Start
+			break
+		}
+	}
+
+	section := []string{}
+	next := found
+	for i := headings[found].Start; i < end; i++ {
+		if next < len(headings) && headings[next].Start == i {
+			level := headings[next].Level + shift
+			if level < 1 {
+				level = 1
+			}
+			if level > 6 {
+				level = 6
+			}
+			section = append(section, fmt.Sprintf("%s %s", strings.Repeat("#", level), headings[next].Title))
+			i = headings[next].End
+			next++
+			continue
+		}
+		section = append(section, lines[i])
+	}
+
+	return strings.TrimRight(strings.Join(section, "\n"), "\n \t"), nil
+}
@@ -0,0 +1,81 @@
+package tfdoc
+
+import "testing"
+
+const sectionsFixture = `# Module
+
+Intro
+
+## Inputs
+
+The inputs.
+
+### Required
+
+| Name |
+| ---- |
+
+### Optional
+
+Setext Optional
+---------------
+
+` + "```" + `
+## Not a heading
+` + "```" + `
+
+## Outputs
+
+The outputs.
+
+Setext Top
+==========
+
+Last.
+`
+
+func TestExtractMarkdownSection(t *testing.T) {
+	tests := []struct {
+		heading string
+		shift   int
+		want    string
+		wantErr string
+	}{
+		{"Inputs", 1, "### Inputs\n\nThe inputs.\n\n#### Required\n\n| Name |\n| ---- |\n\n#### Optional", ""},
+		{"inputs", 0, "## Inputs\n\nThe inputs.\n\n### Required\n\n| Name |\n| ---- |\n\n### Optional", ""},
+		{"Required", 1, "#### Required\n\n| Name |\n| ---- |", ""},
+		{"Outputs", -1, "# Outputs\n\nThe outputs.", ""},
+		{"Optional", 0, "### Optional", ""},
+		{"Setext Optional", 0, "## Setext Optional\n\n```\n## Not a heading\n```", ""},
+		{"Setext Top", 1, "## Setext Top\n\nLast.", ""},
+		{"Required", 6, "###### Required\n\n| Name |\n| ---- |", ""},
+		{"Not a heading", 1, "", `heading "Not a heading" not found`},
+		{"Missing", 1, "", `heading "Missing" not found`},
+	}
+	for _, test := range tests {
+		got, err := ExtractMarkdownSection([]byte(sectionsFixture), test.heading, test.shift)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("%s: got error %v, want %q", test.heading, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.heading, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s shifted %d:\ngot:\n%s\nwant:\n%s", test.heading, test.shift, got, test.want)
+		}
+	}
+}
+
+func TestExtractMarkdownSectionCRLF(t *testing.T) {
+	got, err := ExtractMarkdownSection([]byte("# A\r\n\r\ntext\r\n# B\r\n"), "A", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "## A\n\ntext"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
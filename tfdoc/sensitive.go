@@ -0,0 +1,45 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// FindSensitiveVariables returns the variables of the module's .tf files that set sensitive = true, which
+// this version of tfconfig doesn't read. Files that don't parse are skipped, as tfconfig has already
+// reported them.
+func FindSensitiveVariables(modulePath string) (map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "variable" || len(block.Labels) != 1 {
+				continue
+			}
+			if attr, ok := block.Body.Attributes["sensitive"]; ok && strings.TrimSpace(string(attr.Expr.Range().SliceBytes(src))) == "true" {
+				found[block.Labels[0]] = true
+			}
+		}
+	}
+	return found, nil
+}
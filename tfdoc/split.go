@@ -0,0 +1,121 @@
+package tfdoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+const providerIndexPage = "README.md"
+
+// resourcesByProvider groups managed and data resources into a module per provider
+func resourcesByProvider(module *tfconfig.Module) map[string]*tfconfig.Module {
+	groups := make(map[string]*tfconfig.Module)
+	group := func(name string) *tfconfig.Module {
+		if _, ok := groups[name]; !ok {
+			groups[name] = &tfconfig.Module{
+				Path:             module.Path,
+				ManagedResources: make(map[string]*tfconfig.Resource),
+				DataResources:    make(map[string]*tfconfig.Resource),
+			}
+		}
+		return groups[name]
+	}
+	for key, r := range module.ManagedResources {
+		group(r.Provider.Name).ManagedResources[key] = r
+	}
+	for key, r := range module.DataResources {
+		group(r.Provider.Name).DataResources[key] = r
+	}
+	return groups
+}
+
+// WriteProviderPages writes a page per provider listing its resources and data sources into
+// <out-dir>/providers, with an index page linking them. When no repoUrl is set the source links are
+// relative to the pages.
+func WriteProviderPages(w *FileWriter, module *tfconfig.Module, pageOpts PageOptions, opts Options) error {
+	pageDir := filepath.Join(pageOpts.OutDir, "providers")
+
+	baseUrl, modulePath := pageOpts.RepoUrl, pageOpts.ModulePath
+	if baseUrl == "" {
+		var err error
+		if baseUrl, err = RelativeBaseUrl(pageDir, pageOpts.TfPath); err != nil {
+			return err
+		}
+		modulePath = ""
+	}
+
+	groups := resourcesByProvider(module)
+	providers := make([]string, 0, len(groups))
+	for name := range groups {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	written := []string{}
+	write := func(name, content string) error {
+		page := filepath.Join(pageDir, name)
+		written = append(written, page)
+		if pageOpts.HeadingIds == "explicit" {
+			return w.WriteFile(page, AddHeadingIDs([]byte(content)))
+		}
+		return w.WriteFile(page, []byte(content))
+	}
+
+	index := [][]string{}
+	for _, name := range providers {
+		group := groups[name]
+		page := fmt.Sprintf("# %s\n\n%s\n", name, opts.link("All providers", providerIndexPage, "Index of providers"))
+		if len(group.ManagedResources) > 0 {
+			table, err := ManagedResourcesTable(group, baseUrl, modulePath, opts)
+			if err != nil {
+				return err
+			}
+			page += fmt.Sprintf("\n## Managed resources\n\n%s\n", table)
+		}
+		if len(group.DataResources) > 0 {
+			table, err := DataSourcesTable(group, baseUrl, modulePath, opts)
+			if err != nil {
+				return err
+			}
+			page += fmt.Sprintf("\n## Data sources\n\n%s\n", table)
+		}
+		if err := write(name+".md", page); err != nil {
+			return err
+		}
+		index = append(index, []string{
+			opts.link(name, name+".md", "Resources of provider "+name),
+			fmt.Sprintf("%d", len(group.ManagedResources)),
+			fmt.Sprintf("%d", len(group.DataResources)),
+		})
+	}
+
+	table := MarkdownTable([]string{"Provider", "Managed resources", "Data sources"}, []string{"----", "----", "----"}, index)
+	if err := write(providerIndexPage, fmt.Sprintf("# Providers\n\n%s\n", table)); err != nil {
+		return err
+	}
+
+	if pageOpts.Prune {
+		return pruneStalePages(w, pageDir, written)
+	}
+	return nil
+}
+
+// pruneStalePages removes markdown files in dir that are not in keep
+func pruneStalePages(w *FileWriter, dir string, keep []string) error {
+	existing, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return err
+	}
+	for _, f := range existing {
+		if StringInSlice(f, keep) {
+			continue
+		}
+		if err := w.Remove(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package tfdoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseStripPrefixes parses -strip-prefix values. A bare prefix applies to every table and
+// "table=prefix" to one table, e.g. "vars=acme_".
+func ParseStripPrefixes(values []string) (map[string][]string, error) {
+	prefixes := make(map[string][]string)
+	for _, v := range values {
+		table, prefix := "", v
+		if i := strings.Index(v, "="); i >= 0 {
+			table, prefix = v[:i], v[i+1:]
+			if _, ok := tableColumns[table]; !ok {
+				return nil, fmt.Errorf("strip prefix %q: unknown table %q, expected one of %s", v, table, ColumnTableNames())
+			}
+		}
+		if prefix == "" {
+			return nil, fmt.Errorf("strip prefix %q: empty prefix", v)
+		}
+		prefixes[table] = append(prefixes[table], prefix)
+	}
+	return prefixes, nil
+}
+
+// stripNamePrefixes returns objs with the configured prefixes removed from their displayed names, and
+// the prefixes that were actually removed. Names that would collide after stripping are left whole and
+// reported on stderr. Links and map keys still use the real names.
+func stripNamePrefixes(table string, objs map[string]TableObject, prefixes map[string][]string) (map[string]TableObject, []string) {
+	candidates := append(append([]string{}, prefixes[""]...), prefixes[table]...)
+	if len(candidates) == 0 {
+		return objs, nil
+	}
+
+	stripped := make(map[string]string)
+	display := make(map[string]string)
+	byDisplay := make(map[string][]string)
+	for key, o := range objs {
+		name := o.Name
+		for _, p := range candidates {
+			if strings.HasPrefix(name, p) && len(name) > len(p) {
+				name = strings.TrimPrefix(name, p)
+				stripped[key] = p
+				break
+			}
+		}
+		display[key] = name
+		// Resources of different types may be shown with the same name, as their type tells them apart
+		o.Name = name
+		shown := tableKey(table, o)
+		byDisplay[shown] = append(byDisplay[shown], key)
+	}
+
+	result := make(map[string]TableObject, len(objs))
+	used := make(map[string]bool)
+	for shown, keys := range byDisplay {
+		if len(keys) > 1 {
+			sort.Strings(keys)
+			stderr.Printf("%s: not stripping prefixes from %s as they would all be shown as %q", table, strings.Join(keys, ", "), shown)
+		}
+		for _, key := range keys {
+			o := objs[key]
+			if len(keys) == 1 && stripped[key] != "" {
+				o.Name = display[key]
+				used[stripped[key]] = true
+			}
+			result[key] = o
+		}
+	}
+
+	usedPrefixes := []string{}
+	for p := range used {
+		usedPrefixes = append(usedPrefixes, p)
+	}
+	sort.Strings(usedPrefixes)
+	return result, usedPrefixes
+}
+
+// stripFootnote notes under a table which prefixes were removed from its names
+func stripFootnote(prefixes []string) string {
+	spans := []string{}
+	for _, p := range prefixes {
+		spans = append(spans, codeSpan(p))
+	}
+	return fmt.Sprintf("\n\n<sub>Names are shown without the %s prefix.</sub>", strings.Join(spans, ", "))
+}
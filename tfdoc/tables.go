@@ -0,0 +1,363 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"gopkg.in/yaml.v3"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// tableItem is what one table row is built from: the object as it should be shown, less its location,
+// and where it is declared
+type tableItem struct {
+	Object TableObject
+	Pos    tfconfig.SourcePos
+}
+
+// describedTables are the tables whose Description is a description, shortened by -description short
+var describedTables = map[string]bool{
+	"vars":    true,
+	"outputs": true,
+}
+
+// tableItemKinds name what each table lists, for link titles
+var tableItemKinds = map[string]string{
+	"vars":      "variable",
+	"outputs":   "output",
+	"resources": "resource",
+	"data":      "data source",
+	"modules":   "module call",
+}
+
+// collectTable fills in each item's location and link, then renders the table's rows
+func collectTable(table string, items []tableItem, baseUrl, modulePath string, opts Options) (string, error) {
+	switch opts.Format {
+	case "json":
+		opts.Description = "full"
+		return tableJson(table, collectObjects(table, items, baseUrl, modulePath, opts), opts.Sort)
+	case "yaml":
+		opts.Description = "full"
+		return tableYaml(table, collectObjects(table, items, baseUrl, modulePath, opts), opts.Sort)
+	}
+	return renderColumns(table, collectObjects(table, items, baseUrl, modulePath, opts), opts)
+}
+
+// sortedObjects is the table's objects in sortBy order
+func sortedObjects(objs map[string]TableObject, sortBy string) []TableObject {
+	rows := []TableObject{}
+	for _, k := range getSortedKeys(objs, sortBy) {
+		rows = append(rows, objs[k])
+	}
+	return rows
+}
+
+// tableRow is o as a row of table for -format json and yaml, with the cells only tables of the kind have
+func tableRow(table string, o TableObject) (TableRow, error) {
+	row := TableRow{
+		Name:        o.Name,
+		Type:        o.Type,
+		Description: o.Description,
+		Url:         o.Url,
+		Filename:    o.Filename,
+		Line:        o.Line,
+		Provider:    o.Provider,
+		Lookup:      o.Lookup,
+		Change:      o.Change,
+	}
+	if o.Dynamic != "" {
+		row.Dynamic = strings.Split(o.Dynamic, ", ")
+	}
+	switch table {
+	case "vars":
+		required, sensitive := o.Required == "yes", o.Sensitive == "true"
+		row.Required, row.Sensitive = &required, &sensitive
+		if o.Default != "" {
+			if err := json.Unmarshal([]byte(o.Default), &row.Default); err != nil {
+				return row, fmt.Errorf("the default of variable %s: %v", o.Name, err)
+			}
+		}
+	case "data":
+		aliased := o.Aliased == "yes"
+		row.Aliased = &aliased
+	case "modules":
+		row.Type, row.Description = "", ""
+		row.Source, row.Version = o.Type, o.Description
+	}
+	return row, nil
+}
+
+// tableRows is the table's objects as typed rows, in sortBy order
+func tableRows(table string, objs map[string]TableObject, sortBy string) ([]TableRow, error) {
+	rows := []TableRow{}
+	for _, o := range sortedObjects(objs, sortBy) {
+		row, err := tableRow(table, o)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// tableJson is the table's rows as a JSON array, in sortBy order
+func tableJson(table string, objs map[string]TableObject, sortBy string) (string, error) {
+	rows, err := tableRows(table, objs, sortBy)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// tableYaml is the table's rows as a YAML sequence, in sortBy order
+func tableYaml(table string, objs map[string]TableObject, sortBy string) (string, error) {
+	rows, err := tableRows(table, objs, sortBy)
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// tableKey is what o is keyed by in table: its address for resources and data sources, which may share a
+// name between types, and its name otherwise
+func tableKey(table string, o TableObject) string {
+	if table == "resources" || table == "data" {
+		return o.Type + "." + o.Name
+	}
+	return o.Name
+}
+
+// collectObjects fills in each item's location and link, keyed by tableKey
+func collectObjects(table string, items []tableItem, baseUrl, modulePath string, opts Options) map[string]TableObject {
+	var objs = make(map[string]TableObject)
+	for _, item := range items {
+		tffile := tfpath.Base(item.Pos.Filename)
+		url := opts.sourceUrl(baseUrl, modulePath, tffile, item.Pos.Line)
+		o := item.Object
+		address := tableKey(table, o)
+		what := fmt.Sprintf("%s %s", tableItemKinds[table], address)
+		if describedTables[table] {
+			o.Description = TableDescription(o.Description, url, "Full description of "+what, opts)
+			if opts.Explainer != nil && opts.Description == "short" && o.Description != item.Object.Description {
+				o.trace = append(o.trace, "description cut to its first sentence by -description short")
+			}
+		}
+		if opts.Explainer != nil {
+			o.trace = append(o.trace, linkNotes(baseUrl, modulePath, rawSourceUrl(baseUrl, modulePath, tffile, item.Pos.Line), url, opts.URLRewrites)...)
+		}
+		o.Change = opts.Changes[table][o.Name]
+		o.Location = opts.link(fmt.Sprintf("%s: %d", tffile, item.Pos.Line), url, "Definition of "+what)
+		o.Url = url
+		o.Filename = tffile
+		o.Line = item.Pos.Line
+		objs[address] = o
+	}
+	return objs
+}
+
+// variableItems lists variables, with sensitive naming those marked sensitive if known
+func variableItems(module *tfconfig.Module, sensitive map[string]bool) []tableItem {
+	items := []tableItem{}
+	for _, v := range module.Variables {
+		items = append(items, tableItem{TableObject{
+			Name:        v.Name,
+			Type:        variableType(v),
+			Description: v.Description,
+			Default:     variableDefault(v),
+			Required:    yesNo(v.Required),
+			Sensitive:   fmt.Sprint(sensitive[v.Name]),
+		}, v.Pos})
+	}
+	return items
+}
+
+// variableType is the variable's declared type or, when it has none, one inferred from its default.
+// Terraform accepts any value for a variable without a type, so that is what is shown without a default.
+func variableType(v *tfconfig.Variable) string {
+	if v.Type != "" {
+		return v.Type
+	}
+	var inferred string
+	switch v.Default.(type) {
+	case string:
+		inferred = "string"
+	case float64:
+		inferred = "number"
+	case bool:
+		inferred = "bool"
+	case []interface{}:
+		inferred = "list"
+	case map[string]interface{}:
+		inferred = "map"
+	default:
+		return "any"
+	}
+	return inferred + " (inferred)"
+}
+
+// variableDefault is the variable's default as JSON, so lists and objects stay on one line, or empty when
+// it must be set
+func variableDefault(v *tfconfig.Variable) string {
+	if v.Required {
+		return ""
+	}
+	b, err := json.Marshal(v.Default)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// defaultCell shows a default from variableDefault as a code span, or n/a without one
+func defaultCell(def string) string {
+	if def == "" {
+		return "n/a"
+	}
+	return codeSpan(def)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func outputItems(module *tfconfig.Module) []tableItem {
+	items := []tableItem{}
+	for _, o := range module.Outputs {
+		items = append(items, tableItem{TableObject{Name: o.Name, Description: o.Description}, o.Pos})
+	}
+	return items
+}
+
+// resourceItems lists managed or data resources, with the dynamic blocks each uses if known
+func resourceItems(resources map[string]*tfconfig.Resource, dynamic map[string][]string) []tableItem {
+	items := []tableItem{}
+	for _, r := range resources {
+		items = append(items, tableItem{TableObject{
+			Name:    r.Name,
+			Type:    r.Type,
+			Dynamic: strings.Join(dynamic[r.Type+"."+r.Name], ", "),
+		}, r.Pos})
+	}
+	return items
+}
+
+// dataSourceItems lists data sources with their provider and, if known, how each finds what it reads
+func dataSourceItems(module *tfconfig.Module, lookups map[string]string) []tableItem {
+	items := []tableItem{}
+	for _, r := range module.DataResources {
+		provider := r.Provider.Name
+		if r.Provider.Alias != "" {
+			provider += "." + r.Provider.Alias
+		}
+		items = append(items, tableItem{TableObject{
+			Name:     r.Name,
+			Type:     r.Type,
+			Provider: provider,
+			Lookup:   lookups[r.Type+"."+r.Name],
+			Aliased:  yesNo(r.Provider.Alias != ""),
+		}, r.Pos})
+	}
+	return items
+}
+
+// moduleCallItems lists module calls with their source as the Type and version as the Description
+func moduleCallItems(module *tfconfig.Module) []tableItem {
+	items := []tableItem{}
+	for _, c := range module.ModuleCalls {
+		items = append(items, tableItem{TableObject{Name: c.Name, Type: c.Source, Description: c.Version}, c.Pos})
+	}
+	return items
+}
+
+func VarsTable(module *tfconfig.Module, baseUrl, modulePath string, opts Options) (string, error) {
+	sensitive := map[string]bool{}
+	// JSON and YAML rows always say whether a variable is sensitive
+	if showsColumn(opts, "vars", "sensitive") || StringInSlice(opts.Format, []string{"json", "yaml"}) {
+		var err error
+		if sensitive, err = FindSensitiveVariables(module.Path); err != nil {
+			stderr.Printf("not checking for sensitive variables: %v", err)
+		}
+	}
+	return collectTable("vars", variableItems(module, sensitive), baseUrl, modulePath, opts)
+}
+
+func OutputsTable(module *tfconfig.Module, baseUrl, modulePath string, opts Options) (string, error) {
+	return collectTable("outputs", outputItems(module), baseUrl, modulePath, opts)
+}
+
+func ManagedResourcesTable(module *tfconfig.Module, baseUrl, modulePath string, opts Options) (string, error) {
+	dynamic := map[string][]string{}
+	if opts.ShowDynamic || hasColumn(opts, "resources", "dynamic") {
+		var err error
+		if dynamic, err = FindDynamicBlocks(module.Path); err != nil {
+			stderr.Printf("not listing dynamic blocks: %v", err)
+		}
+	}
+
+	table, err := collectTable("resources", resourceItems(module.ManagedResources, dynamic), baseUrl, modulePath, opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.ShowDynamic && !StringInSlice(opts.Format, singleTableFormats) {
+		table += "\n\n" + DynamicBlocksTable(dynamic)
+	}
+	return table, nil
+}
+
+func DataSourcesTable(module *tfconfig.Module, baseUrl, modulePath string, opts Options) (string, error) {
+	lookups := map[string]string{}
+	if hasColumn(opts, "data", "lookup") {
+		var err error
+		if lookups, err = FindDataLookups(module.Path); err != nil {
+			stderr.Printf("not summarizing data source lookups: %v", err)
+		}
+	}
+	return collectTable("data", dataSourceItems(module, lookups), baseUrl, modulePath, opts)
+}
+
+func ModulesTable(module *tfconfig.Module, baseUrl, modulePath string, opts Options) (string, error) {
+	return collectTable("modules", moduleCallItems(module), baseUrl, modulePath, opts)
+}
+
+// AllTables renders every table of the module under a ## heading, in a fixed order, as one markdown
+// document. Tables without rows are left out.
+func AllTables(module *tfconfig.Module, experiments []string, baseUrl, modulePath string, opts Options) (string, error) {
+	sections := []struct {
+		Heading string
+		Rows    int
+		Table   func() (string, error)
+	}{
+		{"Requirements", RequirementsRows(module, experiments), func() (string, error) { return RequirementsTable(module, experiments, opts) }},
+		{"Variables", len(module.Variables), func() (string, error) { return VarsTable(module, baseUrl, modulePath, opts) }},
+		{"Outputs", len(module.Outputs), func() (string, error) { return OutputsTable(module, baseUrl, modulePath, opts) }},
+		{"Resources", len(module.ManagedResources), func() (string, error) { return ManagedResourcesTable(module, baseUrl, modulePath, opts) }},
+		{"Data Sources", len(module.DataResources), func() (string, error) { return DataSourcesTable(module, baseUrl, modulePath, opts) }},
+		{"Modules", len(module.ModuleCalls), func() (string, error) { return ModulesTable(module, baseUrl, modulePath, opts) }},
+	}
+	rendered := []string{}
+	for _, s := range sections {
+		if s.Rows == 0 {
+			continue
+		}
+		table, err := s.Table()
+		if err != nil {
+			return "", err
+		}
+		rendered = append(rendered, fmt.Sprintf("## %s\n\n%s", LocalHeadings(opts.Lang, []string{s.Heading})[0], table))
+	}
+	return strings.Join(rendered, "\n\n"), nil
+}
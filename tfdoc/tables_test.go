@@ -0,0 +1,246 @@
+package tfdoc
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// loadTestModule loads a module under testdata, failing the test if it doesn't load
+func loadTestModule(t *testing.T, name string) *tfconfig.Module {
+	t.Helper()
+	module, diags := tfconfig.LoadModule(filepath.Join("testdata", name))
+	if diags.HasErrors() {
+		t.Fatalf("loading %s: %v", name, diags.Err())
+	}
+	return module
+}
+
+// markdownRows splits a markdown table into the cells of its heading and each row, leaving out the
+// separator line
+func markdownRows(table string) [][]string {
+	rows := [][]string{}
+	for i, line := range strings.Split(table, "\n") {
+		if i == 1 || !strings.HasPrefix(line, "|") {
+			continue
+		}
+		cells := strings.Split(strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|"), "|")
+		for j := range cells {
+			cells[j] = strings.TrimSpace(cells[j])
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// firstColumn returns the first cell of each row of a markdown table
+func firstColumn(table string) []string {
+	cells := []string{}
+	for _, row := range markdownRows(table)[1:] {
+		cells = append(cells, row[0])
+	}
+	return cells
+}
+
+func TestVariableTypeInference(t *testing.T) {
+	module := loadTestModule(t, "inferred")
+	tests := []struct {
+		variable string
+		want     string
+	}{
+		{"declared", "list(string)"},
+		{"declared_any", "any"},
+		{"required", "any"},
+		{"null_default", "any"},
+		{"string_default", "string (inferred)"},
+		{"empty_string", "string (inferred)"},
+		{"integer", "number (inferred)"},
+		{"decimal", "number (inferred)"},
+		{"negative", "number (inferred)"},
+		{"flag", "bool (inferred)"},
+		{"list", "list (inferred)"},
+		{"empty_list", "list (inferred)"},
+		{"mixed_tuple", "list (inferred)"},
+		{"map", "map (inferred)"},
+		{"empty_map", "map (inferred)"},
+		{"nested", "map (inferred)"},
+	}
+	for _, test := range tests {
+		v, ok := module.Variables[test.variable]
+		if !ok {
+			t.Fatalf("the fixture has no variable %s", test.variable)
+		}
+		if got := variableType(v); got != test.want {
+			t.Errorf("variable %s has type %q, want %q", test.variable, got, test.want)
+		}
+	}
+	if len(tests) != len(module.Variables) {
+		t.Errorf("tested %d of the fixture's %d variables", len(tests), len(module.Variables))
+	}
+}
+
+func TestVarsTableRequiredColumn(t *testing.T) {
+	module := loadTestModule(t, "inferred")
+	tests := []struct {
+		variable string
+		want     string
+	}{
+		{"required", "yes"},
+		{"declared_any", "yes"},
+		{"null_default", "no"},
+		{"empty_string", "no"},
+		{"flag", "no"},
+		{"empty_list", "no"},
+	}
+	table, err := VarsTable(module, "", "", Options{Sort: "name", Description: "full"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := markdownRows(table)
+	columns := map[string]int{}
+	for i, h := range rows[0] {
+		columns[h] = i
+	}
+	column, ok := columns["Required"]
+	if !ok || column > columns["Description"] {
+		t.Fatalf("the Required column isn't before the Description column: %q", rows[0])
+	}
+	required := map[string]string{}
+	for _, row := range rows[1:] {
+		required[row[0]] = row[column]
+	}
+	for _, test := range tests {
+		if got := required[test.variable]; got != test.want {
+			t.Errorf("variable %s is required %q, want %q", test.variable, got, test.want)
+		}
+	}
+}
+
+// checkGolden compares got with the golden file, or rewrites it with -update
+func checkGolden(t *testing.T, golden, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", golden)
+	if *update {
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs, got:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+func TestTablesKeepRowsSharingAName(t *testing.T) {
+	module := loadTestModule(t, "collision")
+	tests := []struct {
+		golden string
+		table  func(*tfconfig.Module, string, string, Options) (string, error)
+		sort   string
+	}{
+		{"collision/resources.golden.md", ManagedResourcesTable, "name"},
+		{"collision/resources-position.golden.md", ManagedResourcesTable, "position"},
+		{"collision/data.golden.md", DataSourcesTable, "name"},
+	}
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			got, err := test.table(module, "", "", Options{Sort: test.sort, Description: "full", Format: "markdown"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, test.golden, got)
+		})
+	}
+}
+
+func TestStripPrefixesKeepsRowsSharingAName(t *testing.T) {
+	module := loadTestModule(t, "collision")
+	opts := Options{Sort: "name", Description: "full", Format: "markdown", StripPrefixes: map[string][]string{"resources": {"th"}}}
+	got, err := ManagedResourcesTable(module, "", "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "collision/resources-stripped.golden.md", got)
+}
+
+func TestTablesAsJsonAndYaml(t *testing.T) {
+	module := loadTestModule(t, "typed")
+	tests := []struct {
+		golden string
+		table  func(*tfconfig.Module, string, string, Options) (string, error)
+		format string
+	}{
+		{"typed/vars.golden.json", VarsTable, "json"},
+		{"typed/outputs.golden.json", OutputsTable, "json"},
+		{"typed/resources.golden.json", ManagedResourcesTable, "json"},
+		{"typed/data.golden.json", DataSourcesTable, "json"},
+		{"typed/modules.golden.json", ModulesTable, "json"},
+		{"typed/vars.golden.yaml", VarsTable, "yaml"},
+		{"typed/outputs.golden.yaml", OutputsTable, "yaml"},
+		{"typed/resources.golden.yaml", ManagedResourcesTable, "yaml"},
+		{"typed/data.golden.yaml", DataSourcesTable, "yaml"},
+		{"typed/modules.golden.yaml", ModulesTable, "yaml"},
+	}
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			got, err := test.table(module, "", "", Options{Sort: "name", Description: "short", Format: test.format, ShowDynamic: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, test.golden, got+"\n")
+		})
+	}
+}
+
+func TestVarsTableValuesAreTyped(t *testing.T) {
+	module := loadTestModule(t, "typed")
+	unmarshal := map[string]func([]byte, interface{}) error{"json": json.Unmarshal, "yaml": yaml.Unmarshal}
+	tests := []struct {
+		field string
+		want  []interface{}
+	}{
+		{"required", []interface{}{true, false, false}},
+		{"sensitive", []interface{}{false, false, true}},
+		{"default", []interface{}{nil, 3, []interface{}{"a", "b"}}},
+	}
+	for _, format := range []string{"json", "yaml"} {
+		// Sensitive is given even when the columns leave it out
+		for _, columns := range []map[string][]ColumnSpec{nil, {"vars": {{Name: "name"}}}} {
+			table, err := VarsTable(module, "", "", Options{Sort: "name", Format: format, Columns: columns})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows []map[string]interface{}
+			if err := unmarshal[format]([]byte(table), &rows); err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			for _, test := range tests {
+				got := []interface{}{}
+				for _, row := range rows {
+					value := row[test.field]
+					if n, ok := value.(float64); ok {
+						// JSON numbers are float64s and YAML's ints
+						value = int(n)
+					}
+					got = append(got, value)
+				}
+				if !reflect.DeepEqual(got, test.want) {
+					t.Errorf("%s with columns %v: the %s fields are %#v, want %#v", format, columns, test.field, got, test.want)
+				}
+			}
+		}
+	}
+}
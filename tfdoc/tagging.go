@@ -0,0 +1,173 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// defaultTagAttributes are the arguments that tag a resource, keyed by the prefix of resource types they apply to
+var defaultTagAttributes = map[string][]string{
+	"aws_":     {"tags"},
+	"azurerm_": {"tags"},
+	"google_":  {"labels"},
+}
+
+// DefaultUntaggableTypes are resource types of the providers above that have no tags, used unless
+// -untaggable-types is given
+var DefaultUntaggableTypes = []string{
+	"aws_iam_policy_attachment",
+	"aws_iam_role_policy",
+	"aws_iam_role_policy_attachment",
+	"aws_route",
+	"aws_route_table_association",
+	"aws_s3_bucket_policy",
+	"aws_s3_bucket_public_access_block",
+	"aws_security_group_rule",
+	"azurerm_role_assignment",
+	"azurerm_subnet_network_security_group_association",
+	"google_project_iam_binding",
+	"google_project_iam_member",
+	"google_project_service",
+	"google_storage_bucket_iam_member",
+}
+
+// TagOptions controls which resources are counted as taggable and what tags them
+type TagOptions struct {
+	Attributes map[string][]string // Keyed by resource type prefix
+	Untaggable []string
+}
+
+// ParseTagAttributes parses -tag-attributes values like "aws_=tags,tags_all" over the defaults
+func ParseTagAttributes(values []string) (map[string][]string, error) {
+	attributes := make(map[string][]string)
+	for prefix, attrs := range defaultTagAttributes {
+		attributes[prefix] = attrs
+	}
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("tag attributes %q: expected prefix=attribute[,attribute]", v)
+		}
+		attributes[parts[0]] = strings.Split(parts[1], ",")
+	}
+	return attributes, nil
+}
+
+// tagAttributesFor returns the arguments that tag resourceType, from its longest matching prefix, or nil
+// when it isn't taggable
+func tagAttributesFor(resourceType string, opts TagOptions) []string {
+	if StringInSlice(resourceType, opts.Untaggable) {
+		return nil
+	}
+	best := ""
+	for prefix := range opts.Attributes {
+		if strings.HasPrefix(resourceType, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return opts.Attributes[best]
+}
+
+// TaggedResource is a taggable managed resource and whether it sets one of its tag arguments
+type TaggedResource struct {
+	Address  string
+	Tagged   bool
+	Filename string
+	Line     int
+}
+
+// FindTaggedResources returns the taggable managed resources of the module's .tf files, sorted by address.
+// Files that don't parse are skipped, as tfconfig has already reported them.
+func FindTaggedResources(modulePath string, opts TagOptions) ([]TaggedResource, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []TaggedResource{}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "resource" || len(block.Labels) != 2 {
+				continue
+			}
+			attributes := tagAttributesFor(block.Labels[0], opts)
+			if attributes == nil {
+				continue
+			}
+			r := TaggedResource{Address: block.Labels[0] + "." + block.Labels[1], Filename: tfpath.Base(file), Line: block.DefRange().Start.Line}
+			for _, name := range attributes {
+				if _, ok := block.Body.Attributes[name]; ok {
+					r.Tagged = true
+				}
+			}
+			resources = append(resources, r)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Address < resources[j].Address })
+	return resources, nil
+}
+
+// tagCoverage is the percentage of resources that are tagged, 100 when there are none
+func tagCoverage(resources []TaggedResource) float64 {
+	if len(resources) == 0 {
+		return 100
+	}
+	tagged := 0
+	for _, r := range resources {
+		if r.Tagged {
+			tagged++
+		}
+	}
+	return float64(tagged) * 100 / float64(len(resources))
+}
+
+// TagCoverageTable lists the taggable resources and whether each sets tags, under a line giving the coverage
+func TagCoverageTable(resources []TaggedResource, baseUrl, modulePath string, opts Options) string {
+	if len(resources) == 0 {
+		return "This module has no taggable resources."
+	}
+	tagged := 0
+	data := [][]string{}
+	for _, r := range resources {
+		mark := "no"
+		if r.Tagged {
+			mark = "yes"
+			tagged++
+		}
+		url := opts.sourceUrl(baseUrl, modulePath, r.Filename, r.Line)
+		data = append(data, []string{codeSpan(r.Address), mark, opts.link(fmt.Sprintf("%s: %d", r.Filename, r.Line), url, "Definition of resource "+r.Address)})
+	}
+	table := MarkdownTable([]string{"Resource", "Tagged", "Code Position"}, []string{"----", "----", "------"}, data)
+	return fmt.Sprintf("%d of %d taggable resources set tags (%.1f%%).\n\n%s", tagged, len(resources), tagCoverage(resources), table)
+}
+
+// CheckTagCoverage fails when -min-tag-coverage is set and the coverage is below it
+func CheckTagCoverage(resources []TaggedResource, min float64) error {
+	if coverage := tagCoverage(resources); min > 0 && coverage < min {
+		return fmt.Errorf("%.1f%% of taggable resources set tags, below -min-tag-coverage %.1f%%", coverage, min)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// DefaultMaxIncludeSize is the largest file template functions will include unless -max-include-size says otherwise
+const DefaultMaxIncludeSize = 1024 * 1024
+
+// Matches CSI sequences (colours, cursor movement) and OSC sequences (titles, hyperlinks)
+var rAnsiEscape = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(\x07|\x1b\\\\)")
+
+// IncludedFiles records the files templates include with rawfile and includeSection, for callers that cache
+// what was rendered. A nil IncludedFiles records nothing.
+type IncludedFiles struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// Paths are the files included so far, sorted. Files that couldn't be read are listed too.
+func (f *IncludedFiles) Paths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	paths := []string{}
+	for p := range f.paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (f *IncludedFiles) add(filePath string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.paths == nil {
+		f.paths = make(map[string]bool)
+	}
+	f.paths[filePath] = true
+}
+
+// readIncludeFile reads a file for inclusion in a rendered document, refusing files over maxSize bytes
+// or that are not valid UTF-8, and stripping ANSI escape sequences. The file is recorded in included.
+func readIncludeFile(filePath string, maxSize int64, included *IncludedFiles) (string, error) {
+	included.add(filePath)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxSize {
+		return "", fmt.Errorf("%s is %d bytes, over the include limit of %d bytes (see -max-include-size)", filePath, info.Size(), maxSize)
+	}
+	fileBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(fileBytes) {
+		return "", fmt.Errorf("%s is not valid UTF-8", filePath)
+	}
+	return rAnsiEscape.ReplaceAllString(string(fileBytes), ""), nil
+}
+
+// moduleFacts holds the sets that the module query template functions look things up in
+type moduleFacts struct {
+	providers     map[string]bool // Provider names, and name.alias for aliased configurations
+	variables     map[string]bool
+	resourceTypes map[string]int // Count of managed resources of each type
+}
+
+func newModuleFacts(module *tfconfig.Module) moduleFacts {
+	facts := moduleFacts{
+		providers:     make(map[string]bool),
+		variables:     make(map[string]bool),
+		resourceTypes: make(map[string]int),
+	}
+	for name := range module.RequiredProviders {
+		facts.providers[name] = true
+	}
+	for _, resources := range []map[string]*tfconfig.Resource{module.ManagedResources, module.DataResources} {
+		for _, r := range resources {
+			facts.providers[r.Provider.Name] = true
+			if r.Provider.Alias != "" {
+				facts.providers[r.Provider.Name+"."+r.Provider.Alias] = true
+			}
+		}
+	}
+	for name := range module.Variables {
+		facts.variables[name] = true
+	}
+	for _, r := range module.ManagedResources {
+		facts.resourceTypes[r.Type]++
+	}
+	return facts
+}
+
+// TemplateFuncs returns the functions available to the template at templatePath. Paths are relative to the
+// template file, and files over maxInclude bytes can't be included. toc holds the table of contents once
+// it's built, and the files the template includes are recorded in included.
+func TemplateFuncs(templatePath string, maxInclude int64, lang string, module *tfconfig.Module, toc *[]TocEntry, included *IncludedFiles) template.FuncMap {
+	relPath := func(filepath string) string {
+		return path.Dir(templatePath) + "/" + filepath
+	}
+	facts := newModuleFacts(module)
+
+	return template.FuncMap{
+		"rawfile": func(filepath string) (string, error) {
+			return readIncludeFile(relPath(filepath), maxInclude, included)
+		},
+		"includeSection": func(filepath, heading string, shift ...int) (string, error) {
+			mdFilePath := relPath(filepath)
+			content, err := readIncludeFile(mdFilePath, maxInclude, included)
+			if err != nil {
+				return "", fmt.Errorf("includeSection: cannot read %s: %v", mdFilePath, err)
+			}
+			levels := 1
+			if len(shift) > 0 {
+				levels = shift[0]
+			}
+			section, err := ExtractMarkdownSection([]byte(content), heading, levels)
+			if err != nil {
+				return "", fmt.Errorf("includeSection: %s: %v", mdFilePath, err)
+			}
+			return section, nil
+		},
+		"usesProvider": func(name string) bool {
+			return facts.providers[name]
+		},
+		"resourceCount": func(pattern string) (int, error) {
+			count := 0
+			for resourceType, n := range facts.resourceTypes {
+				matched, err := path.Match(pattern, resourceType)
+				if err != nil {
+					return 0, fmt.Errorf("resourceCount: bad pattern %q: %v", pattern, err)
+				}
+				if matched {
+					count += n
+				}
+			}
+			return count, nil
+		},
+		"providerCount": func() int {
+			count := 0
+			for name := range facts.providers {
+				if !strings.Contains(name, ".") {
+					count++
+				}
+			}
+			return count
+		},
+		"plural": func(n int, singular, plural string) string {
+			if n == 1 {
+				return singular
+			}
+			return plural
+		},
+		"humanize": func(n int) string {
+			return humanizeInt(lang, n)
+		},
+		"pct": func(a, b int) string {
+			if b == 0 {
+				return "0%"
+			}
+			return fmt.Sprintf("%.0f%%", 100*float64(a)/float64(b))
+		},
+		"hasVariable": func(name string) bool {
+			return facts.variables[name]
+		},
+		"tocEntries": func(depth ...int) ([]TocEntry, error) {
+			if len(depth) > 2 {
+				return nil, fmt.Errorf("tocEntries: expected at most a minimum and maximum depth, got %d arguments", len(depth))
+			}
+			min, max := 1, 0
+			if len(depth) == 1 {
+				max = depth[0]
+			} else if len(depth) == 2 {
+				min, max = depth[0], depth[1]
+			}
+			entries := []TocEntry{}
+			for _, e := range *toc {
+				if e.Level >= min && (max == 0 || e.Level <= max) {
+					entries = append(entries, e)
+				}
+			}
+			return entries, nil
+		},
+		"blockquote": func(text string) string {
+			lines := strings.Split(text, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			return strings.Join(lines, "\n")
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package tfdoc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// renderTestTemplate renders text as a template in dir, with the functions of a template there documenting
+// module
+func renderTestTemplate(t *testing.T, dir string, module *tfconfig.Module, text string) (string, error) {
+	t.Helper()
+	toc := []TocEntry{}
+	funcs := TemplateFuncs(filepath.Join(dir, "README.template"), DefaultMaxIncludeSize, "en", module, &toc, nil)
+	tmpl, err := template.New("README.template").Funcs(funcs).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, nil)
+	return out.String(), err
+}
+
+func TestIncludeSection(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "child.md"), []byte("# Child\n\n## Inputs\n\nThe inputs.\n\n## Outputs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		template string
+		want     string
+		wantErr  []string
+	}{
+		{`{{ includeSection "child.md" "Inputs" }}`, "### Inputs\n\nThe inputs.", nil},
+		{`{{ includeSection "child.md" "Inputs" 0 }}`, "## Inputs\n\nThe inputs.", nil},
+		{`{{ includeSection "missing.md" "Inputs" }}`, "", []string{"includeSection: cannot read", "missing.md"}},
+		{`{{ includeSection "child.md" "Usage" }}`, "", []string{"includeSection:", "child.md", `heading "Usage" not found`}},
+	}
+	for _, test := range tests {
+		got, err := renderTestTemplate(t, dir, &tfconfig.Module{}, test.template)
+		if test.wantErr != nil {
+			if err == nil {
+				t.Errorf("%s: rendered %q, want an error", test.template, got)
+				continue
+			}
+			for _, want := range test.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("%s: got error %q, want it to contain %q", test.template, err, want)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.template, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.template, got, test.want)
+		}
+	}
+}
+
+func TestReadIncludeFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"plain.md":  "plain text",
+		"ansi.md":   "\x1b[31mred\x1b[0m and \x1b]0;title\x07plain",
+		"latin1.md": "caf\xe9",
+		"big.md":    strings.Repeat("x", 65),
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{"plain.md", "plain text", ""},
+		{"ansi.md", "red and plain", ""},
+		{"latin1.md", "", "is not valid UTF-8"},
+		{"big.md", "", "is 65 bytes, over the include limit of 64 bytes"},
+	}
+	for _, test := range tests {
+		got, err := readIncludeFile(filepath.Join(dir, test.name), 64, nil)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want it to contain %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestModuleQueryFuncs(t *testing.T) {
+	module := loadTestModule(t, "providers")
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`{{ usesProvider "aws" }}`, "true"},
+		{`{{ usesProvider "tls" }}`, "true"},
+		{`{{ usesProvider "random" }}`, "true"},
+		{`{{ usesProvider "aws.replica" }}`, "true"},
+		{`{{ usesProvider "aws.primary" }}`, "false"},
+		{`{{ usesProvider "google" }}`, "false"},
+		{`{{ usesProvider "replica" }}`, "false"},
+		{`{{ resourceCount "aws_iam_*" }}`, "3"},
+		{`{{ resourceCount "aws_iam_role" }}`, "2"},
+		{`{{ resourceCount "aws_*_role*" }}`, "3"},
+		{`{{ resourceCount "aws_s3_bucket" }}`, "1"},
+		{`{{ resourceCount "aws_iam_policy_document" }}`, "0"},
+		{`{{ resourceCount "*" }}`, "5"},
+		{`{{ resourceCount "aws_?3_bucket" }}`, "1"},
+		{`{{ resourceCount "[ar]*" }}`, "5"},
+		{`{{ resourceCount "google_*" }}`, "0"},
+		{`{{ hasVariable "kms_key_arn" }}`, "true"},
+		{`{{ hasVariable "kms_key" }}`, "false"},
+		{`{{ providerCount }}`, "3"},
+		{`{{ providerCount }} {{ plural providerCount "provider" "providers" }}`, "3 providers"},
+		{`{{ plural 1 "provider" "providers" }}`, "provider"},
+		{`{{ humanize 1234567 }}`, "1,234,567"},
+		{`{{ humanize -1234 }}`, "-1,234"},
+		{`{{ humanize 999 }}`, "999"},
+		{`{{ pct 1 3 }}`, "33%"},
+		{`{{ pct 1 0 }}`, "0%"},
+		{`{{ if usesProvider "aws" }}IAM permissions required{{ end }}`, "IAM permissions required"},
+	}
+	for _, test := range tests {
+		got, err := renderTestTemplate(t, t.TempDir(), module, test.template)
+		if err != nil {
+			t.Errorf("%s: %v", test.template, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s = %q, want %q", test.template, got, test.want)
+		}
+	}
+
+	if _, err := renderTestTemplate(t, t.TempDir(), module, `{{ resourceCount "aws_[" }}`); err == nil || !strings.Contains(err.Error(), `resourceCount: bad pattern "aws_["`) {
+		t.Errorf("a bad pattern gave error %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+variable "cidr" {}
+
+variable "azs" {}
+
+output "vpc_id" {
+  value = aws_vpc.this.id
+}
+
+resource "aws_vpc" "this" {
+  cidr_block = var.cidr
+}
@@ -0,0 +1,21 @@
+resource "aws_s3_bucket" "this" {
+  bucket = "logs"
+}
+
+resource "aws_iam_role" "this" {
+  name               = "logs"
+  assume_role_policy = data.aws_iam_policy_document.this.json
+}
+
+resource "aws_iam_role" "reader" {
+  name               = "reader"
+  assume_role_policy = data.aws_iam_policy_document.this.json
+}
+
+data "aws_iam_policy_document" "this" {
+  statement {
+    actions = ["sts:AssumeRole"]
+  }
+}
+
+data "aws_caller_identity" "this" {}
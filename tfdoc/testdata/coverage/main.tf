@@ -0,0 +1,23 @@
+locals {
+  name = "app"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = local.name
+}
+
+moved {
+  from = aws_s3_bucket.log
+  to   = aws_s3_bucket.logs
+}
+
+removed {
+  from = aws_s3_bucket.old
+}
+
+check "bucket" {
+  assert {
+    condition     = aws_s3_bucket.logs.bucket != ""
+    error_message = "The bucket has no name"
+  }
+}
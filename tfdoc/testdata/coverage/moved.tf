@@ -0,0 +1,4 @@
+moved {
+  from = aws_s3_bucket.archive
+  to   = aws_s3_bucket.logs
+}
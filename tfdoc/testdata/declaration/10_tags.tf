@@ -0,0 +1,3 @@
+variable "tags" {
+  description = "The tags to apply"
+}
@@ -0,0 +1,7 @@
+variable "name" {
+  description = "The name of the stack"
+}
+
+variable "environment" {
+  description = "The environment it runs in"
+}
@@ -0,0 +1,7 @@
+variable "vpc_cidr" {
+  description = "The CIDR block of the VPC"
+}
+
+variable "azs" {
+  description = "The availability zones to use"
+}
@@ -0,0 +1,12 @@
+terraform {
+  required_version = ">= 0.14"
+  experiments      = [module_variable_optional_attrs]
+}
+
+variable "settings" {
+  description = "Optional settings"
+  type = object({
+    name = string
+    size = optional(number)
+  })
+}
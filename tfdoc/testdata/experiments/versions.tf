@@ -0,0 +1,3 @@
+terraform {
+  experiments = [module_variable_optional_attrs, config_driven_move]
+}
@@ -0,0 +1,64 @@
+variable "declared" {
+  type    = list(string)
+  default = "not a list"
+}
+
+variable "declared_any" {
+  type = any
+}
+
+variable "required" {}
+
+variable "null_default" {
+  default = null
+}
+
+variable "string_default" {
+  default = "app"
+}
+
+variable "empty_string" {
+  default = ""
+}
+
+variable "integer" {
+  default = 3
+}
+
+variable "decimal" {
+  default = 0.5
+}
+
+variable "negative" {
+  default = -1
+}
+
+variable "flag" {
+  default = false
+}
+
+variable "list" {
+  default = ["a", "b"]
+}
+
+variable "empty_list" {
+  default = []
+}
+
+variable "mixed_tuple" {
+  default = ["a", 1, true]
+}
+
+variable "map" {
+  default = { Owner = "platform" }
+}
+
+variable "empty_map" {
+  default = {}
+}
+
+variable "nested" {
+  default = {
+    rules = [{ port = 443 }]
+  }
+}
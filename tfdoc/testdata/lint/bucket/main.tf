@@ -0,0 +1,27 @@
+variable "BucketName" {
+  description = "The name of the bucket"
+  type        = string
+}
+
+variable "versioning" {
+  description = "Whether to keep old versions of objects"
+  type        = bool
+}
+
+variable "enable_logging" {
+  description = "Whether to log access to the bucket"
+  type        = bool
+}
+
+variable "temp_prefix" {
+  type = string
+}
+
+output "bucket_arn" {
+  description = "The ARN of the bucket"
+  value       = "arn"
+}
+
+output "id" {
+  value = "id"
+}
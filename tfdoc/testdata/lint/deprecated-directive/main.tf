@@ -0,0 +1,11 @@
+# tf2doc:deprecated replacement=org/network Moved to the network module
+
+variable "Region" {}
+
+variable "name" {
+  description = "The name of everything"
+}
+
+output "id" {
+  value = "x"
+}
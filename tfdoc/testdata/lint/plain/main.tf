@@ -0,0 +1,9 @@
+variable "Region" {}
+
+variable "name" {
+  description = "The name of everything"
+}
+
+output "id" {
+  value = "x"
+}
@@ -0,0 +1,27 @@
+module "network" {
+  source = "./modules/network"
+}
+
+module "app" {
+  source = "./modules/app"
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+
+resource "aws_iam_role" "this" {
+  name = var.name
+}
+
+resource "aws_iam_role" "admin" {
+  name = "${var.name}-admin"
+}
+
+data "aws_region" "current" {}
+
+data "aws_caller_identity" "current" {}
+
+resource "aws_s3_bucket" "archive" {
+  bucket = "${var.name}-archive"
+}
@@ -0,0 +1,7 @@
+output "role_arn" {
+  value = aws_iam_role.this.arn
+}
+
+output "bucket" {
+  value = aws_s3_bucket.this.id
+}
@@ -0,0 +1,12 @@
+variable "zone" {
+  type = string
+}
+
+variable "name" {
+  type    = string
+  default = "app"
+}
+
+variable "count_per_zone" {
+  type = number
+}
@@ -0,0 +1,12 @@
+# Provider-defined functions are newer syntax than the parser knows
+variable "region" {
+  type = string
+}
+
+output "account_id" {
+  value = provider::aws::arn_parse(aws_s3_bucket.this.arn).account_id
+}
+
+resource "aws_s3_bucket_policy" "this" {
+  bucket = aws_s3_bucket.this.id
+}
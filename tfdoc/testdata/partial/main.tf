@@ -0,0 +1,13 @@
+variable "name" {
+  description = "The name of the bucket"
+  type        = string
+}
+
+output "arn" {
+  description = "The bucket's ARN"
+  value       = aws_s3_bucket.this.arn
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
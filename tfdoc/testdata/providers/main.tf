@@ -0,0 +1,35 @@
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+    tls = {
+      source = "hashicorp/tls"
+    }
+  }
+}
+
+variable "kms_key_arn" {
+  default = null
+}
+
+resource "aws_iam_role" "app" {
+  name = "app"
+}
+
+resource "aws_iam_role" "replica" {
+  provider = aws.replica
+  name     = "replica"
+}
+
+resource "aws_iam_role_policy" "app" {
+  role = aws_iam_role.app.id
+}
+
+resource "aws_s3_bucket" "logs" {}
+
+resource "random_id" "suffix" {
+  byte_length = 4
+}
+
+data "aws_iam_policy_document" "assume" {}
@@ -0,0 +1,38 @@
+terraform {
+  required_version = ">= 1.3"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+    }
+  }
+}
+
+variable "name" {
+  description = "The name of the bucket"
+  type        = string
+}
+
+variable "tags" {
+  type = map(string)
+  default = {
+    team = "platform"
+  }
+}
+
+output "arn" {
+  description = "The bucket's ARN"
+  value       = aws_s3_bucket.this.arn
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+  tags   = var.tags
+}
+
+data "aws_region" "current" {}
+
+module "labels" {
+  source = "./modules/labels"
+}
@@ -0,0 +1,40 @@
+variable "name" {
+  description = "The name of the bucket"
+}
+
+variable "zones" {
+  type      = list(string)
+  default   = ["a", "b"]
+  sensitive = true
+}
+
+variable "size" {
+  default = 3
+}
+
+output "arn" {
+  description = "The bucket's ARN"
+  value       = aws_s3_bucket.this.arn
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+
+  dynamic "lifecycle_rule" {
+    for_each = var.zones
+    content {
+      id = lifecycle_rule.value
+    }
+  }
+}
+
+data "aws_region" "current" {}
+
+data "aws_caller_identity" "replica" {
+  provider = aws.replica
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
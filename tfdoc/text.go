@@ -0,0 +1,78 @@
+package tfdoc
+
+import "strings"
+
+// DefaultTextWidth is how wide -format text lets a cell grow before wrapping it
+const DefaultTextWidth = 60
+
+// textCell reduces a table cell's markdown links, images and code spans to their text, as csvCell does, and
+// wraps it to width, keeping its own line breaks. 0 never wraps.
+func textCell(cell string, width int) []string {
+	plain := rCellNewline.ReplaceAllString(csvCell(cell), "\n")
+	lines := []string{}
+	for _, line := range strings.Split(plain, "\n") {
+		if width > 0 && len([]rune(line)) > width {
+			line = wrapLine(line, width)
+		}
+		lines = append(lines, strings.Split(line, "\n")...)
+	}
+	return lines
+}
+
+// TextTable renders a table as plain text for a terminal, in columns padded to their widest cell with a line
+// of dashes under the headings, like column -t. Cells are wrapped at width, unless it's 0, and aren't escaped.
+func TextTable(headings []string, data [][]string, width int) string {
+	rows := [][][]string{}
+	widths := make([]int, len(headings))
+	for i, h := range headings {
+		widths[i] = len([]rune(h))
+	}
+	for _, d := range data {
+		row := [][]string{}
+		for i, val := range d {
+			cell := textCell(val, width)
+			for _, line := range cell {
+				if i < len(widths) && len([]rune(line)) > widths[i] {
+					widths[i] = len([]rune(line))
+				}
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	pad := func(cells []string) string {
+		line := ""
+		for i, c := range cells {
+			if i < len(cells)-1 {
+				c += strings.Repeat(" ", widths[i]-len([]rune(c))+2)
+			}
+			line += c
+		}
+		return strings.TrimRight(line, " ")
+	}
+	separator := []string{}
+	for _, w := range widths {
+		separator = append(separator, strings.Repeat("-", w))
+	}
+	lines := []string{pad(headings), pad(separator)}
+	for _, row := range rows {
+		// A row is as tall as its most wrapped cell
+		height := 1
+		for _, cell := range row {
+			if len(cell) > height {
+				height = len(cell)
+			}
+		}
+		for l := 0; l < height; l++ {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				if l < len(cell) {
+					cells[i] = cell[l]
+				}
+			}
+			lines = append(lines, pad(cells))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,24 @@
+package tfdoc
+
+import "testing"
+
+func TestTextTable(t *testing.T) {
+	got := TextTable([]string{"Name", "Description"}, [][]string{
+		{"[name](https://example.com/main.tf#L1 \"title\")", "The `name` of the bucket, which must be unique"},
+		{"tags", "Tags\nfor everything"},
+	}, 20)
+	want := "Name  Description\n" +
+		"----  ------------------\n" +
+		"name  The name of the\n" +
+		"      bucket, which must\n" +
+		"      be unique\n" +
+		"tags  Tags\n" +
+		"      for everything"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got, want := TextTable([]string{"Name"}, [][]string{{"a rather long name that isn't wrapped"}}, 0), "Name\n-------------------------------------\na rather long name that isn't wrapped"; got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+// Package tfdoc generates documentation for Terraform modules: tables of a module's variables, outputs,
+// resources and other blocks in markdown and other formats, the data templates are rendered with, and the
+// tables of contents, README regions and pages built from them. The tf2doc command is a wrapper around it.
+//
+// Load a module with tfconfig.LoadModule, then render its tables:
+//
+//	module, diags := tfconfig.LoadModule("./modules/vpc")
+//	if diags.HasErrors() {
+//		return diags.Err()
+//	}
+//	table, err := tfdoc.VarsTable(module, "https://github.com/org/repo/blob/main", "modules/vpc", tfdoc.Options{Sort: "name", Description: "full", Format: "markdown"})
+package tfdoc
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// stderr reports problems that don't stop the docs being generated
+var stderr = log.New(os.Stderr, "", 1)
+
+// SetWarningOutput sends the warnings the package prints while rendering, like cells that would break a
+// table, to w instead of stderr
+func SetWarningOutput(w io.Writer) {
+	stderr.SetOutput(w)
+}
+
+// DocData is what templates are rendered with
+type DocData struct {
+	TerraformVarsTable              string
+	TerraformOutputsTable           string
+	TerraformManagedResourcesTable  string
+	TerraformDataSourcesTable       string
+	TerraformModulesTable           string
+	TerraformRequirementsTable      string
+	TerraformRequiredProvidersTable string
+	TerraformProvidersTable         string
+	TerraformRemovedTable           string
+	TerraformTodos                  string
+	MarkdownTOC                     string
+	TOCEntries                      []TocEntry // The headings MarkdownTOC lists
+	RepoBaseUrl                     string
+	Owners                          []Owner
+	Deprecated                      bool
+	DeprecationMessage              string
+	Replacement                     string
+	Meta                            map[string]interface{}
+	Experiments                     []string
+	UndocumentedBlocks              map[string]int         // Counts of top-level block types the docs don't cover
+	Extra                           map[string]interface{} // Values added by RenderOptions.ExtraData functions
+	TerraformMetadataTable          string
+	TerraformImportScaffold         string // Import commands or blocks in a code fence
+	TerraformTagCoverage            string
+	LintFindings                    []LintFinding // With -with-lint, what the Lint action finds, in file and line order
+	LintSummary                     string        // With -with-lint, e.g. "3 warnings"
+	DocScore                        int           // With -with-lint, the percentage of variables and outputs with descriptions
+}
+
+var ValidSorts = []string{
+	"name",
+	"position",
+	"declaration",
+}
+
+var ValidDescriptions = []string{
+	"full",
+	"short",
+}
+
+// TableObject is a row of a table: a variable, output, resource, data source or module call. -format json
+// and yaml write it as a TableRow.
+type TableObject struct {
+	Name        string
+	Type        string
+	Description string
+	Location    string // The markdown link to Url
+	Url         string
+	Filename    string
+	Line        int
+	Dynamic     string   // Dynamic blocks in a managed resource
+	Default     string   // A variable's default as JSON, empty when it has none
+	Required    string   // yes for a variable without a default, else no
+	Sensitive   string   // true for a variable marked sensitive, else false
+	Provider    string   // A resource's provider, with its alias if it has one
+	Lookup      string   // How a data source finds what it reads
+	Aliased     string   // yes for a resource using an aliased provider, else no
+	Change      string   // What changed since -annotate-since, shown after the name
+	trace       []string // How the row was built, for -explain
+}
+
+// TableRow is a row of a table as -format json and yaml write it, with values typed rather than formatted as cells
+type TableRow struct {
+	Name        string      `json:"name" yaml:"name"`
+	Type        string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Source      string      `json:"source,omitempty" yaml:"source,omitempty"`   // A module call's source
+	Version     string      `json:"version,omitempty" yaml:"version,omitempty"` // A module call's version constraint
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Url         string      `json:"url" yaml:"url"`
+	Filename    string      `json:"filename" yaml:"filename"`
+	Line        int         `json:"line" yaml:"line"`
+	Dynamic     []string    `json:"dynamic,omitempty" yaml:"dynamic,omitempty"`     // Dynamic blocks in a managed resource
+	Default     interface{} `json:"default,omitempty" yaml:"default,omitempty"`     // A variable's default, absent when it has none
+	Required    *bool       `json:"required,omitempty" yaml:"required,omitempty"`   // Whether a variable has no default
+	Sensitive   *bool       `json:"sensitive,omitempty" yaml:"sensitive,omitempty"` // Whether a variable is marked sensitive
+	Provider    string      `json:"provider,omitempty" yaml:"provider,omitempty"`   // A resource's provider, with its alias if it has one
+	Lookup      string      `json:"lookup,omitempty" yaml:"lookup,omitempty"`       // How a data source finds what it reads
+	Aliased     *bool       `json:"aliased,omitempty" yaml:"aliased,omitempty"`     // Whether a data source uses an aliased provider
+	Change      string      `json:"change,omitempty" yaml:"change,omitempty"`       // What changed since -annotate-since
+}
+
+// Options controls how the *Table functions select and present rows
+type Options struct {
+	Sort             string // One of ValidSorts
+	Description      string // One of ValidDescriptions
+	Columns          map[string][]ColumnSpec
+	StripPrefixes    map[string][]string // Keyed by table, "" for all tables
+	ShowDynamic      bool                // Follow the managed resources table with the dynamic blocks each uses
+	ConstraintBadges bool                // Follow version constraints with a badge
+	Group            GroupOptions
+	Lang             string                       // The HeadingCatalogs language of the headings
+	Format           string                       // One of ValidFormats
+	Changes          map[string]map[string]string // The -annotate-since markers, keyed by table then name
+	LinkTitles       bool                         // Give every link a title saying where it goes
+	TextWidth        int                          // With Format text, wrap cells longer than this. 0 never wraps
+	URLRewrites      []URLRewrite                 // Applied to every link, the first that matches wins
+	Explainer        *Explainer                   // Records where each part came from, when not nil
+	MarkdownWarnings *MarkdownWarnings            // Counts the cells that would break a markdown table, when not nil
+	Included         *IncludedFiles               // Records the files templates include, when not nil
+}
+
+// StringInSlice reports whether a is one of list
+func StringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
+// PageOptions says where pages written beside the document go and how their source links are built
+type PageOptions struct {
+	TfPath     string // The module's directory
+	OutDir     string // Where pages are written, beside the module when empty
+	RepoUrl    string // The base of source links, which are relative to the pages when empty
+	ModulePath string // The module's path in the repository
+	HeadingIds string // One of ValidHeadingIds
+	Prune      bool   // Remove pages the run no longer writes
+}
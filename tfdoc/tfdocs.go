@@ -0,0 +1,228 @@
+package tfdoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// tfdocsTable is one section of a terraform-docs style document
+type tfdocsTable struct {
+	Title, None string // The section's heading, and what it says when it has no rows
+	Headings    []string
+	Centred     map[int]bool // Columns whose separator centres them
+	Rows        [][]string
+	Pos         []tfconfig.SourcePos // Where each row is declared, for the Position column
+}
+
+// tfdocsAnchor is a name as terraform-docs writes it, with an anchor of the kind, e.g. input, to link to it
+func tfdocsAnchor(kind, name string) string {
+	id := kind + "_" + name
+	return fmt.Sprintf(`<a name="%s"></a> [%s](#%s)`, id, name, strings.Replace(id, "_", `\_`, -1))
+}
+
+// tfdocsCode shows a type or default as terraform-docs does, in a code span, or pre-formatted when it has
+// several lines
+func tfdocsCode(s string) string {
+	if strings.Contains(s, "\n") {
+		return "<pre>" + s + "</pre>"
+	}
+	return codeSpan(s)
+}
+
+// tfdocsText is a description, or n/a without one
+func tfdocsText(s string) string {
+	if s == "" {
+		return "n/a"
+	}
+	return s
+}
+
+// tfdocsVersion is a list of version constraints, or n/a without one
+func tfdocsVersion(constraints []string) string {
+	if len(constraints) == 0 {
+		return "n/a"
+	}
+	return strings.Join(constraints, ", ")
+}
+
+// tfdocsResourceUrl links a resource or data source type to its page in the Terraform registry, or is empty
+// for providers that aren't from the registry
+func tfdocsResourceUrl(module *tfconfig.Module, r *tfconfig.Resource) string {
+	source := "hashicorp/" + r.Provider.Name
+	if p, ok := module.RequiredProviders[r.Provider.Name]; ok && p.Source != "" {
+		source = strings.TrimPrefix(p.Source, "registry.terraform.io/")
+	}
+	if strings.Count(source, "/") != 1 {
+		return ""
+	}
+	kind := "resources"
+	if r.Mode == tfconfig.DataResourceMode {
+		kind = "data-sources"
+	}
+	return fmt.Sprintf("https://registry.terraform.io/providers/%s/latest/docs/%s/%s", source, kind, strings.TrimPrefix(r.Type, r.Provider.Name+"_"))
+}
+
+// render writes the section with terraform-docs' table layout, and a Position column when positions
+func (t tfdocsTable) render(baseUrl, modulePath string, positions bool, rewrites []URLRewrite) string {
+	section := fmt.Sprintf("## %s\n\n", t.Title)
+	if len(t.Rows) == 0 {
+		return section + t.None
+	}
+	headings := t.Headings
+	if positions {
+		headings = append(append([]string{}, headings...), "Position")
+	}
+	lines := []string{"| " + strings.Join(headings, " | ") + " |"}
+	separator := "|"
+	for i, h := range headings {
+		dashes := strings.Repeat("-", len(h)+2)
+		if t.Centred[i] {
+			dashes = ":" + dashes[2:] + ":"
+		}
+		separator += dashes + "|"
+	}
+	lines = append(lines, separator)
+	for r, row := range t.Rows {
+		cells := []string{}
+		for _, cell := range row {
+			cells = append(cells, MarkdownTableCellEscape(cell))
+		}
+		if positions {
+			file := tfpath.Base(t.Pos[r].Filename)
+			cells = append(cells, markdownLink(fmt.Sprintf("%s: %d", file, t.Pos[r].Line), rewriteUrl(rewrites, rawSourceUrl(baseUrl, modulePath, file, t.Pos[r].Line)), ""))
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	return section + strings.Join(lines, "\n")
+}
+
+// MarkdownDocument renders the module as terraform-docs' markdown table output does: Requirements,
+// Providers, Modules, Resources, Inputs and Outputs sections, in that order, with its headings, columns and
+// anchors, so it can fill the regions terraform-docs did without churn. With positions, each table but
+// Requirements and Providers ends with a Position column linking to the declarations, which terraform-docs
+// doesn't have. The links are rewritten by rewrites.
+func MarkdownDocument(module *tfconfig.Module, baseUrl, modulePath string, positions bool, rewrites []URLRewrite) string {
+	requirements := tfdocsTable{Title: "Requirements", None: "No requirements.", Headings: []string{"Name", "Version"}}
+	if len(module.RequiredCore) > 0 {
+		requirements.Rows = append(requirements.Rows, []string{tfdocsAnchor("requirement", "terraform"), strings.Join(module.RequiredCore, ", ")})
+	}
+	providers := tfdocsTable{Title: "Providers", None: "No providers.", Headings: []string{"Name", "Version"}}
+	used := map[string]bool{}
+	for name := range module.RequiredProviders {
+		used[name] = true
+	}
+	for _, resources := range []map[string]*tfconfig.Resource{module.ManagedResources, module.DataResources} {
+		for _, r := range resources {
+			name := r.Provider.Name
+			if r.Provider.Alias != "" {
+				name += "." + r.Provider.Alias
+			}
+			used[name] = true
+		}
+	}
+	names := []string{}
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		constraints := []string{}
+		if p, ok := module.RequiredProviders[strings.SplitN(name, ".", 2)[0]]; ok {
+			constraints = p.VersionConstraints
+		}
+		if _, ok := module.RequiredProviders[name]; ok {
+			requirements.Rows = append(requirements.Rows, []string{tfdocsAnchor("requirement", name), tfdocsVersion(constraints)})
+		}
+		providers.Rows = append(providers.Rows, []string{tfdocsAnchor("provider", name), tfdocsVersion(constraints)})
+	}
+
+	modules := tfdocsTable{Title: "Modules", None: "No modules.", Headings: []string{"Name", "Source", "Version"}}
+	names = []string{}
+	for name := range module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := module.ModuleCalls[name]
+		version := c.Version
+		if version == "" {
+			version = "n/a"
+		}
+		modules.Rows = append(modules.Rows, []string{tfdocsAnchor("module", name), c.Source, version})
+		modules.Pos = append(modules.Pos, c.Pos)
+	}
+
+	resourcesTable := tfdocsTable{Title: "Resources", None: "No resources.", Headings: []string{"Name", "Type"}}
+	resources := []*tfconfig.Resource{}
+	for _, r := range module.ManagedResources {
+		resources = append(resources, r)
+	}
+	for _, r := range module.DataResources {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i].Type+"."+resources[i].Name, resources[j].Type+"."+resources[j].Name
+		if a != b {
+			return a < b
+		}
+		return resources[i].Mode < resources[j].Mode
+	})
+	for _, r := range resources {
+		name := r.Type + "." + r.Name
+		if url := tfdocsResourceUrl(module, r); url != "" {
+			name = fmt.Sprintf("[%s](%s)", name, url)
+		}
+		kind := "resource"
+		if r.Mode == tfconfig.DataResourceMode {
+			kind = "data source"
+		}
+		resourcesTable.Rows = append(resourcesTable.Rows, []string{name, kind})
+		resourcesTable.Pos = append(resourcesTable.Pos, r.Pos)
+	}
+
+	inputs := tfdocsTable{Title: "Inputs", None: "No inputs.", Headings: []string{"Name", "Description", "Type", "Default", "Required"}, Centred: map[int]bool{4: true}}
+	names = []string{}
+	for name := range module.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := module.Variables[name]
+		varType := v.Type
+		if varType == "" {
+			varType = "any"
+		}
+		def := "n/a"
+		if !v.Required {
+			def = tfdocsCode(variableDefault(v))
+		}
+		inputs.Rows = append(inputs.Rows, []string{tfdocsAnchor("input", name), tfdocsText(v.Description), tfdocsCode(varType), def, yesNo(v.Required)})
+		inputs.Pos = append(inputs.Pos, v.Pos)
+	}
+
+	outputs := tfdocsTable{Title: "Outputs", None: "No outputs.", Headings: []string{"Name", "Description"}}
+	names = []string{}
+	for name := range module.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := module.Outputs[name]
+		outputs.Rows = append(outputs.Rows, []string{tfdocsAnchor("output", name), tfdocsText(o.Description)})
+		outputs.Pos = append(outputs.Pos, o.Pos)
+	}
+
+	sections := []string{
+		requirements.render(baseUrl, modulePath, false, rewrites),
+		providers.render(baseUrl, modulePath, false, rewrites),
+	}
+	for _, t := range []tfdocsTable{modules, resourcesTable, inputs, outputs} {
+		sections = append(sections, t.render(baseUrl, modulePath, positions, rewrites))
+	}
+	return strings.Join(sections, "\n\n")
+}
@@ -0,0 +1,43 @@
+package tfdoc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func TestMarkdownDocument(t *testing.T) {
+	module := loadTestModule(t, "tfdocs")
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "tfdocs", "README.golden.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := MarkdownDocument(module, "", "", false, nil) + "\n"; got != string(golden) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, golden)
+	}
+
+	got := MarkdownDocument(module, "https://example.com/stack", "", true, nil)
+	for _, want := range []string{
+		"| Name | Description | Type | Default | Required | Position |\n|------|-------------|------|---------|:--------:|----------|",
+		"| yes | [main.tf: 12](https://example.com/stack/main.tf#L12) |",
+		"| resource | [main.tf: 29](https://example.com/stack/main.tf#L29) |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got:\n%s\nwant it to contain:\n%s", got, want)
+		}
+	}
+	if strings.Contains(strings.SplitN(got, "## Modules", 2)[0], "Position") {
+		t.Errorf("the Requirements and Providers tables have a Position column:\n%s", got)
+	}
+}
+
+func TestMarkdownDocumentEmpty(t *testing.T) {
+	want := "## Requirements\n\nNo requirements.\n\n## Providers\n\nNo providers.\n\n## Modules\n\nNo modules.\n\n" +
+		"## Resources\n\nNo resources.\n\n## Inputs\n\nNo inputs.\n\n## Outputs\n\nNo outputs."
+	if got := MarkdownDocument(&tfconfig.Module{}, "", "", true, nil); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
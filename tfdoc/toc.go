@@ -0,0 +1,113 @@
+package tfdoc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	rHashHeader        = regexp.MustCompile("^(?P<indent>#+) ?(?P<title>.+)$")
+	rUnderscoreHeader1 = regexp.MustCompile("^=+$")
+	rUnderscoreHeader2 = regexp.MustCompile("^\\-+$")
+)
+
+// Slugufy came from: https://github.com/sebdah/markdown-toc/tree/master/toc
+func slugify(s string) string {
+	// As GitHub does, keep letters, numbers, - and _, and turn spaces into -
+	var slug strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == ' ':
+			slug.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			slug.WriteRune(r)
+		}
+	}
+	return slug.String()
+}
+
+// TocEntry is a heading listed in the table of contents, with Level 1 for top-level headings
+type TocEntry struct {
+	Title, Anchor string
+	Level         int
+}
+
+// https://github.com/sebdah/markdown-toc/tree/master/toc
+func BuildMarkdownToc(d []byte, depth, skipHeaders int, opts Options) ([]string, error) {
+	entries, err := BuildTocEntries(d, depth, skipHeaders)
+	if err != nil {
+		return []string{}, err
+	}
+	return FormatToc(entries, opts), nil
+}
+
+// FormatToc renders entries as the table of contents, under TocHeading
+func FormatToc(entries []TocEntry, opts Options) []string {
+	toc := strings.Split(TocHeading, "\n")
+	for _, e := range entries {
+		toc = append(toc, fmt.Sprintf("%s1. %s", strings.Repeat("   ", e.Level-1), opts.link(e.Title, "#"+e.Anchor, "Section "+e.Title)))
+	}
+	return toc
+}
+
+// BuildTocEntries lists the headings of d down to depth levels, after the first skipHeaders, with the
+// anchors GitHub gives them
+func BuildTocEntries(d []byte, depth, skipHeaders int) ([]TocEntry, error) {
+	entries := []TocEntry{}
+
+	seenHeaders := make(map[string]int)
+	var previousLine string
+	appendToC := func(title string, indent int) {
+		link := slugify(title)
+		if skipHeaders > 0 {
+			skipHeaders--
+			return
+		}
+
+		if _, ok := seenHeaders[link]; ok {
+			seenHeaders[link]++
+			link = fmt.Sprintf("%s-%d", link, seenHeaders[link]-1)
+		} else {
+			seenHeaders[link] = 1
+		}
+		entries = append(entries, TocEntry{Title: title, Anchor: link, Level: indent + 1})
+	}
+
+	fence := codeFence{}
+	s := bufio.NewScanner(bytes.NewReader(d))
+	for s.Scan() {
+		// Nothing in a code block is a heading
+		if fence.skip(s.Text()) {
+			previousLine = ""
+			continue
+		}
+
+		switch {
+		case rHashHeader.Match(s.Bytes()):
+			m := rHashHeader.FindStringSubmatch(s.Text())
+			if depth > 0 && len(m[1]) > depth {
+				continue
+			}
+			appendToC(m[2], len(m[1])-1)
+
+		case rUnderscoreHeader1.Match(s.Bytes()) && strings.TrimSpace(previousLine) != "":
+			appendToC(previousLine, 0)
+
+		case rUnderscoreHeader2.Match(s.Bytes()) && strings.TrimSpace(previousLine) != "":
+			if depth > 0 && depth < 2 {
+				continue
+			}
+			appendToC(previousLine, 1)
+		}
+		previousLine = s.Text()
+	}
+	if err := s.Err(); err != nil {
+		return []TocEntry{}, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,156 @@
+package tfdoc
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+	"unicode"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+func FuzzSlugify(f *testing.F) {
+	for _, seed := range []string{"", "Inputs", "Input Variables", "Requirements & Providers", "`code` in a heading", "Ünïcödé Çafé", "日本語の見出し", "emoji 🚀 heading", "tabs\tand\nnewlines", "--already-a-slug__", "Inputs (`var.name`) & Outputs!", "a/b: why?"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, heading string) {
+		slug := slugify(heading)
+		for _, r := range slug {
+			if r != '-' && r != '_' && !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsMark(r) {
+				t.Fatalf("slugify(%q) = %q, which has %q", heading, slug, r)
+			}
+		}
+	})
+}
+
+// fencedTocSeeds are markdown documents with headings in and out of code fences
+var fencedTocSeeds = []string{
+	"# Title\n\n## Section\n\ntext\n",
+	"Title\n=====\n\nSection\n-------\n",
+	"# Title\n\n```\n# not a heading\n```\n\n## Section\n",
+	"~~~hcl\n# comment\n~~~\n# Heading\n",
+	"```\n# unclosed fence\n",
+	"    ```\n## Indented fence\n    ```\n",
+	"# Same\n# Same\n# Same\n",
+	"text\n---\n",
+	"text\n\n---\n",
+	"```\nText\n===\n```\n",
+}
+
+func FuzzBuildMarkdownToc(f *testing.F) {
+	for _, seed := range fencedTocSeeds {
+		f.Add(seed, 3, 0)
+	}
+	f.Add("# One\n## Two\n### Three\n#### Four\n", 0, 1)
+	f.Fuzz(func(t *testing.T, doc string, depth, skipHeaders int) {
+		if _, err := BuildMarkdownToc([]byte(doc), depth, skipHeaders, Options{}); err != nil {
+			return
+		}
+
+		// The same lines inside a code fence give no entries
+		lines := []string{}
+		for _, line := range strings.Split(doc, "\n") {
+			if !rCodeFence.MatchString(line) {
+				lines = append(lines, line)
+			}
+		}
+		fenced := "```\n" + strings.Join(lines, "\n") + "\n```\n"
+		toc, err := BuildMarkdownToc([]byte(fenced), depth, skipHeaders, Options{})
+		if err != nil {
+			return
+		}
+		if len(toc) > 2 {
+			t.Errorf("%q gave entries %q from inside its code fence", fenced, toc[2:])
+		}
+	})
+}
+
+const tocFixture = `# Module
+
+## Usage
+
+` + "```" + `
+## Not a heading
+` + "```" + `
+
+### Usage
+
+Inputs & Outputs
+----------------
+
+#### Deep
+
+## Usage
+`
+
+func TestBuildTocEntries(t *testing.T) {
+	tests := []struct {
+		depth, skip int
+		want        []TocEntry
+	}{
+		{0, 0, []TocEntry{{"Module", "module", 1}, {"Usage", "usage", 2}, {"Usage", "usage-1", 3}, {"Inputs & Outputs", "inputs--outputs", 2}, {"Deep", "deep", 4}, {"Usage", "usage-2", 2}}},
+		{2, 0, []TocEntry{{"Module", "module", 1}, {"Usage", "usage", 2}, {"Inputs & Outputs", "inputs--outputs", 2}, {"Usage", "usage-1", 2}}},
+		{1, 0, []TocEntry{{"Module", "module", 1}}},
+		{3, 1, []TocEntry{{"Usage", "usage", 2}, {"Usage", "usage-1", 3}, {"Inputs & Outputs", "inputs--outputs", 2}, {"Usage", "usage-2", 2}}},
+	}
+	for _, test := range tests {
+		got, err := BuildTocEntries([]byte(tocFixture), test.depth, test.skip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("depth %d skipping %d: got %+v, want %+v", test.depth, test.skip, got, test.want)
+		}
+	}
+}
+
+// rTocAnchor matches the anchor of a link in a table of contents
+var rTocAnchor = regexp.MustCompile(`\(#([^) ]+)`)
+
+func TestTocEntriesMatchMarkdownToc(t *testing.T) {
+	entries, err := BuildTocEntries([]byte(tocFixture), 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{}
+	for _, m := range rTocAnchor.FindAllStringSubmatch(strings.Join(FormatToc(entries, Options{}), "\n"), -1) {
+		want = append(want, m[1])
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{"the TOCEntries field", `{{ range .TOCEntries }}{{ .Level }} [{{ .Title }}](#{{ .Anchor }}){{ "\n" }}{{ end }}`, want},
+		{"tocEntries", `{{ range tocEntries }}- (#{{ .Anchor }}){{ end }}`, want},
+		{"tocEntries to a maximum depth", `{{ range tocEntries 2 }}- (#{{ .Anchor }}){{ end }}`, []string{"module", "usage", "inputs--outputs", "usage-2"}},
+		{"tocEntries between depths", `{{ range tocEntries 2 3 }}- (#{{ .Anchor }}){{ end }}`, []string{"usage", "usage-1", "inputs--outputs", "usage-2"}},
+	}
+	for _, test := range tests {
+		toc := entries
+		funcs := TemplateFuncs("README.template", DefaultMaxIncludeSize, "en", &tfconfig.Module{}, &toc, nil)
+		tmpl := template.Must(template.New("README.template").Funcs(funcs).Parse(test.template))
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, DocData{TOCEntries: entries}); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		got := []string{}
+		for _, m := range rTocAnchor.FindAllStringSubmatch(out.String(), -1) {
+			got = append(got, m[1])
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s links to %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	toc := entries
+	funcs := TemplateFuncs("README.template", DefaultMaxIncludeSize, "en", &tfconfig.Module{}, &toc, nil)
+	tmpl := template.Must(template.New("README.template").Funcs(funcs).Parse(`{{ tocEntries 1 2 3 }}`))
+	if err := tmpl.Execute(&bytes.Buffer{}, nil); err == nil || !strings.Contains(err.Error(), "tocEntries: expected at most a minimum and maximum depth, got 3 arguments") {
+		t.Errorf("tocEntries with three arguments gave error %v", err)
+	}
+}
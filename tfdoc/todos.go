@@ -0,0 +1,87 @@
+package tfdoc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/tfpath"
+)
+
+// DefaultTodoMarkers are the comment markers reported by the Todos action unless -todo-markers says otherwise
+const DefaultTodoMarkers = "TODO,FIXME,HACK"
+
+type Todo struct {
+	File   string
+	Line   int
+	Marker string
+	Text   string
+}
+
+func (t Todo) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", t.File, t.Line, t.Marker, t.Text)
+}
+
+// FindTodos returns comments in the module's .tf files containing any of markers. Files are lexed
+// as HCL so only comments are considered, never string literals.
+func FindTodos(modulePath string, markers []string) ([]Todo, error) {
+	quoted := []string{}
+	for _, m := range markers {
+		if m = strings.TrimSpace(m); m != "" {
+			quoted = append(quoted, regexp.QuoteMeta(m))
+		}
+	}
+	if len(quoted) == 0 {
+		return []Todo{}, nil
+	}
+	rMarker := regexp.MustCompile(fmt.Sprintf(`\b(%s)\b[:\s]*(.*)$`, strings.Join(quoted, "|")))
+
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	todos := []Todo{}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		tokens, _ := hclsyntax.LexConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		for _, token := range tokens {
+			if token.Type != hclsyntax.TokenComment {
+				continue
+			}
+			for i, line := range strings.Split(string(token.Bytes), "\n") {
+				m := rMarker.FindStringSubmatch(line)
+				if m == nil {
+					continue
+				}
+				text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[2]), "*/"))
+				todos = append(todos, Todo{
+					File:   tfpath.Base(file),
+					Line:   token.Range.Start.Line + i,
+					Marker: m[1],
+					Text:   text,
+				})
+			}
+		}
+	}
+	return todos, nil
+}
+
+// TodoChecklist renders todos as a markdown task list
+func TodoChecklist(todos []Todo) string {
+	lines := []string{}
+	for _, t := range todos {
+		lines = append(lines, fmt.Sprintf("- [ ] `%s:%d` %s: %s", t.File, t.Line, t.Marker, t.Text))
+	}
+	return strings.Join(lines, "\n")
+}
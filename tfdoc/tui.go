@@ -0,0 +1,235 @@
+package tfdoc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// tuiTab is one table shown by the viewer
+type tuiTab struct {
+	Title string
+	Rows  []TableObject
+}
+
+// tuiState is what the viewer is showing
+type tuiState struct {
+	Tabs     []tuiTab
+	Tab      int
+	Filter   string
+	Selected int
+	Message  string
+}
+
+// visible returns the current tab's rows whose names contain the filter
+func (s *tuiState) visible() []TableObject {
+	rows := []TableObject{}
+	for _, o := range s.Tabs[s.Tab].Rows {
+		if strings.Contains(strings.ToLower(o.Name), strings.ToLower(s.Filter)) {
+			rows = append(rows, o)
+		}
+	}
+	return rows
+}
+
+// newTuiTabs collects the rows of each table as the renderers would, in their sort order
+func newTuiTabs(module *tfconfig.Module, opts Options) []tuiTab {
+	tables := []struct {
+		title, table string
+		items        []tableItem
+	}{
+		{"Variables", "vars", variableItems(module, nil)},
+		{"Outputs", "outputs", outputItems(module)},
+		{"Resources", "resources", resourceItems(module.ManagedResources, nil)},
+		{"Data sources", "data", resourceItems(module.DataResources, nil)},
+		{"Modules", "modules", moduleCallItems(module)},
+	}
+	tabs := []tuiTab{}
+	for _, t := range tables {
+		opts.Description = "full"
+		objs, _ := stripNamePrefixes(t.table, collectObjects(t.table, t.items, "", "", opts), opts.StripPrefixes)
+		tab := tuiTab{Title: t.title}
+		for _, k := range getSortedKeys(objs, opts.Sort) {
+			tab.Rows = append(tab.Rows, objs[k])
+		}
+		tabs = append(tabs, tab)
+	}
+	return tabs
+}
+
+// stty runs stty against the terminal on stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// terminalSize returns the rows and columns of the terminal, or a small default
+func terminalSize() (int, int) {
+	size, err := stty("size")
+	if fields := strings.Fields(size); err == nil && len(fields) == 2 {
+		rows, errRows := strconv.Atoi(fields[0])
+		cols, errCols := strconv.Atoi(fields[1])
+		if errRows == nil && errCols == nil && rows > 0 && cols > 0 {
+			return rows, cols
+		}
+	}
+	return 24, 80
+}
+
+// fitWidth cuts s to width runes
+func fitWidth(s string, width int) string {
+	runes := []rune(strings.Replace(s, "\n", " ", -1))
+	if width < 1 {
+		return ""
+	}
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return string(runes)
+}
+
+// draw redraws the whole screen. Raw mode needs \r\n line endings.
+func (s *tuiState) draw() {
+	height, width := terminalSize()
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+
+	tabs := []string{}
+	for i, t := range s.Tabs {
+		title := fmt.Sprintf(" %s (%d) ", t.Title, len(t.Rows))
+		if i == s.Tab {
+			title = "\x1b[7m" + title + "\x1b[0m"
+		}
+		tabs = append(tabs, title)
+	}
+	b.WriteString(strings.Join(tabs, "|") + "\r\n")
+	b.WriteString(fitWidth("Filter: "+s.Filter, width) + "\r\n\r\n")
+
+	rows := s.visible()
+	listHeight := height - 5
+	first := 0
+	if s.Selected >= listHeight {
+		first = s.Selected - listHeight + 1
+	}
+	nameWidth := width / 3
+	for i := first; i < len(rows) && i < first+listHeight; i++ {
+		o := rows[i]
+		line := fmt.Sprintf("%-*s %-*s %s:%d", nameWidth, fitWidth(o.Name, nameWidth), nameWidth, fitWidth(o.Type, nameWidth), o.Filename, o.Line)
+		line = fitWidth(line, width)
+		if i == s.Selected {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	b.WriteString(fmt.Sprintf("\x1b[%d;1H", height))
+	status := "tab/←/→ switch table  ↑/↓ move  type to filter  enter open in $EDITOR  esc quit"
+	if s.Message != "" {
+		status = s.Message
+	}
+	b.WriteString(fitWidth(status, width))
+	os.Stdout.WriteString(b.String())
+}
+
+// openEditor opens the file and line of o in $EDITOR, with the terminal restored while it runs
+func openEditor(o TableObject, modulePath, saved string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		return errors.New("set $EDITOR to open files")
+	}
+	if _, err := stty(saved); err != nil {
+		return err
+	}
+	defer stty("raw", "-echo")
+
+	args := append(strings.Fields(editor), fmt.Sprintf("+%d", o.Line), filepath.Join(modulePath, o.Filename))
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// RunTui browses the module's tables in the terminal until the user quits. It needs a terminal on stdin.
+func RunTui(module *tfconfig.Module, modulePath string, opts Options) error {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return errors.New("-tui needs a terminal on stdin")
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return fmt.Errorf("-tui needs a terminal on stdin: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return fmt.Errorf("can't control the terminal: %v", err)
+	}
+	defer func() {
+		stty(saved)
+		os.Stdout.WriteString("\x1b[H\x1b[2J")
+	}()
+
+	s := &tuiState{Tabs: newTuiTabs(module, opts)}
+	buf := make([]byte, 16)
+	for {
+		s.draw()
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		keys := []string{string(buf[:n])}
+		if buf[0] != '\x1b' {
+			// Several keys can arrive in one read, e.g. when pasting
+			keys = strings.Split(string(buf[:n]), "")
+		}
+		for _, key := range keys {
+			if quit := s.handleKey(key, modulePath, saved); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// handleKey updates the state for a key press, returning true to quit
+func (s *tuiState) handleKey(key, modulePath, saved string) bool {
+	s.Message = ""
+	switch key {
+	case "\x03", "\x04", "\x1b":
+		return true
+	case "\t", "\x1b[C":
+		s.Tab, s.Selected = (s.Tab+1)%len(s.Tabs), 0
+	case "\x1b[Z", "\x1b[D":
+		s.Tab, s.Selected = (s.Tab+len(s.Tabs)-1)%len(s.Tabs), 0
+	case "\x1b[A":
+		if s.Selected > 0 {
+			s.Selected--
+		}
+	case "\x1b[B":
+		if s.Selected < len(s.visible())-1 {
+			s.Selected++
+		}
+	case "\r", "\n":
+		if rows := s.visible(); s.Selected < len(rows) {
+			if err := openEditor(rows[s.Selected], modulePath, saved); err != nil {
+				s.Message = err.Error()
+			}
+		}
+	case "\x7f", "\b":
+		if runes := []rune(s.Filter); len(runes) > 0 {
+			s.Filter, s.Selected = string(runes[:len(runes)-1]), 0
+		}
+	default:
+		if !strings.HasPrefix(key, "\x1b") && key >= " " {
+			s.Filter, s.Selected = s.Filter+key, 0
+		}
+	}
+	return false
+}
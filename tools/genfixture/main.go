@@ -0,0 +1,32 @@
+// genfixture writes a Terraform module with a given number of synthetic resources, for checking how
+// TF_2_DOC copes with very large modules:
+//
+//	go run ./tools/genfixture -resources 6000 -out /tmp/large
+//	time TF_2_DOC -path /tmp/large -action RenderTemplate -templatePath terraform_module_doc.template.md
+//
+// The benchmarks in large_test.go generate the same module.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/JoeButler99/TF_2_DOC/internal/fixture"
+)
+
+func main() {
+	resources := flag.Int("resources", 1000, "How many resources to generate")
+	perFile := flag.Int("per-file", 500, "How many resources to put in each .tf file")
+	outDir := flag.String("out", "", "The directory to write the module into")
+	flag.Parse()
+
+	if *outDir == "" || *resources < 0 || *perFile < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := fixture.WriteModule(*outDir, *resources, *perFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latestReleaseUrl is the GitHub API endpoint for the project's newest release
+const latestReleaseUrl = "https://api.github.com/repos/JoeButler99/TF_2_DOC/releases/latest"
+
+// checksumsAsset is the release asset listing each binary's sha256, in sha256sum format
+const checksumsAsset = "checksums.txt"
+
+type release struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	Url  string `json:"browser_download_url"`
+}
+
+// updateClient honours HTTPS_PROXY and friends, as http.DefaultTransport does
+var updateClient = &http.Client{Timeout: 5 * time.Minute}
+
+// binaryAssetName is the name of the release asset built for this OS and architecture
+func binaryAssetName() string {
+	name := fmt.Sprintf("TF_2_DOC_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// newerVersion reports whether version a, like "v1.2.3", is newer than b. Anything after a - or + is ignored.
+func newerVersion(a, b string) bool {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(v, "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		parts := []int{}
+		for _, p := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(p)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// httpGet fetches url, failing on any status but 200
+func httpGet(url string) (io.ReadCloser, error) {
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func latestRelease() (*release, error) {
+	body, err := httpGet(latestReleaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var r release
+	if err := json.NewDecoder(body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("reading the latest release: %v", err)
+	}
+	return &r, nil
+}
+
+// expectedChecksum finds name's sha256 in the release's checksums file
+func expectedChecksum(r *release, name string) (string, error) {
+	for _, a := range r.Assets {
+		if a.Name != checksumsAsset {
+			continue
+		}
+		body, err := httpGet(a.Url)
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		s := bufio.NewScanner(body)
+		for s.Scan() {
+			fields := strings.Fields(s.Text())
+			if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+				return strings.ToLower(fields[0]), nil
+			}
+		}
+		if err := s.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%s has no checksum for %s", checksumsAsset, name)
+	}
+	return "", fmt.Errorf("release %s has no %s", r.TagName, checksumsAsset)
+}
+
+// replaceExecutable downloads url next to the running binary, checks its sha256 and renames it over the
+// binary. Until the rename nothing has changed, so any failure leaves the existing binary as it was.
+func replaceExecutable(url, checksum string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	body, err := httpGet(url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	tmp, err := ioutil.TempFile(filepath.Dir(exe), filepath.Base(exe)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != checksum {
+		return fmt.Errorf("downloaded binary has sha256 %s, but %s says %s", sum, checksumsAsset, checksum)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), exe)
+}
+
+// RunUpdate replaces the running binary with the latest release's build for this OS and architecture,
+// after checking it against the release's checksums. With checkOnly it only reports whether there is one.
+func RunUpdate(checkOnly bool) error {
+	r, err := latestRelease()
+	if err != nil {
+		return err
+	}
+	if Version != "dev" && !newerVersion(r.TagName, Version) {
+		fmt.Printf("%s is the latest version\n", Version)
+		return nil
+	}
+	if checkOnly {
+		fmt.Printf("%s is available, this is %s\n", r.TagName, Version)
+		return nil
+	}
+	if Version == "dev" {
+		return fmt.Errorf("%s is available, but this is a development build so it won't be replaced", r.TagName)
+	}
+
+	name := binaryAssetName()
+	for _, a := range r.Assets {
+		if a.Name != name {
+			continue
+		}
+		checksum, err := expectedChecksum(r, name)
+		if err != nil {
+			return err
+		}
+		if err := replaceExecutable(a.Url, checksum); err != nil {
+			return err
+		}
+		fmt.Printf("updated from %s to %s\n", Version, r.TagName)
+		return nil
+	}
+	return fmt.Errorf("release %s has no %s asset", r.TagName, name)
+}